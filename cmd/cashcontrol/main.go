@@ -1,16 +1,38 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"cashcontrol/internal/config"
 	"cashcontrol/internal/database"
 	"cashcontrol/internal/handlers"
+	"cashcontrol/internal/rates"
+	"cashcontrol/internal/scheduler"
+	"cashcontrol/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// recurringExpensesLockKey ключ advisory lock для обработки регулярных расходов
+	recurringExpensesLockKey int64 = 7_301_001
+	// exchangeRatesLockKey ключ advisory lock для импорта курсов валют из файла
+	exchangeRatesLockKey int64 = 7_301_002
+	// trashPurgeLockKey ключ advisory lock для очистки корзины
+	trashPurgeLockKey int64 = 7_301_003
+)
+
+// shutdownTimeout время на завершение активных HTTP запросов при остановке
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
@@ -19,6 +41,12 @@ func main() {
 
 	logger := initLogger()
 
+	defaultCategories, err := services.LoadDefaultCategories(cfg.DefaultCategoriesFile, cfg.DefaultLocale)
+	if err != nil {
+		logger.Error("failed to load default categories", slog.String("error", err.Error()))
+		panic(err)
+	}
+
 	if err := database.Init(cfg); err != nil {
 		logger.Error("failed to init database", slog.String("error", err.Error()))
 		panic(err)
@@ -35,22 +63,91 @@ func main() {
 		}
 	}()
 
+	// Сервисы собираются один раз и общие для HTTP маршрутов и фоновых задач
+	svc := services.New(cfg, database.DB, logger, defaultCategories)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Фоновые задачи
+	var wg sync.WaitGroup
+	jobs := setupScheduler(cfg, svc, logger)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		jobs.Run(ctx)
+	}()
+
 	// Инициализация маршрутизатора
-	router := setupRouter(cfg, logger)
+	router := setupRouter(svc, logger)
 
-	router.Run(cfg.ServerAddress)
+	server := &http.Server{
+		Addr:    cfg.ServerAddress,
+		Handler: router,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("http server failed", slog.String("error", err.Error()))
+			stop()
+		}
+	}()
+
+	<-ctx.Done()
+	logger.Info("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("failed to shutdown http server", slog.String("error", err.Error()))
+	}
+
+	wg.Wait()
 }
 
-// setupRouter инициализирует все зависимости и настраивает роутер
-func setupRouter(cfg *config.Config, logger *slog.Logger) *gin.Engine {
+// setupRouter настраивает роутер поверх собранных сервисов
+func setupRouter(svc *services.Services, logger *slog.Logger) *gin.Engine {
 	// Настройка роутера
 	router := gin.Default()
 
-	handlers.RegisterRoutes(router, database.DB, logger, cfg)
+	handlers.RegisterRoutes(router, svc, logger)
 
 	return router
 }
 
+// setupScheduler регистрирует фоновые задачи
+func setupScheduler(cfg *config.Config, svc *services.Services, logger *slog.Logger) *scheduler.Scheduler {
+	s := scheduler.New(database.DB, logger)
+	s.Add(scheduler.Job{
+		Name:     "process_recurring_expenses",
+		Interval: cfg.RecurringProcessInterval,
+		LockKey:  recurringExpensesLockKey,
+		Run:      svc.RecurringExpense.ProcessRecurringExpenses,
+	})
+	if cfg.ExchangeRatesFile != "" {
+		provider := rates.NewFileProvider(cfg.ExchangeRatesFile)
+		s.Add(scheduler.Job{
+			Name:     "import_exchange_rates",
+			Interval: cfg.ExchangeRatesImportInterval,
+			LockKey:  exchangeRatesLockKey,
+			Run: func(ctx context.Context) error {
+				// Курсы из файла общие для всех пользователей
+				_, err := svc.Currency.ImportRates(ctx, nil, provider)
+				return err
+			},
+		})
+	}
+	if cfg.TrashRetention > 0 {
+		s.Add(scheduler.Job{
+			Name:     "purge_trash",
+			Interval: cfg.TrashPurgeInterval,
+			LockKey:  trashPurgeLockKey,
+			Run:      svc.Trash.PurgeExpired,
+		})
+	}
+	return s
+}
+
 // initLogger инициализирует структурированный логгер
 func initLogger() *slog.Logger {
 	opts := &slog.HandlerOptions{
@@ -0,0 +1,321 @@
+// Команда seed заполняет базу демонстрационными пользователями, категориями, расходами,
+// бюджетами и регулярными расходами для локальной разработки. Повторный запуск пропускает
+// уже существующих демо-пользователей.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"time"
+
+	"cashcontrol/internal/config"
+	"cashcontrol/internal/database"
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
+	"cashcontrol/internal/repository"
+	"cashcontrol/internal/services"
+
+	"gorm.io/gorm"
+)
+
+// demoPassword пароль всех демо-пользователей
+const demoPassword = "demo12345"
+
+// demoUser профиль демо-пользователя; суммы шаблонов задаются в рублях и умножаются на rate
+type demoUser struct {
+	Email    string
+	Username string
+	Currency string
+	Locale   string
+	Rate     float64
+}
+
+var demoUsers = []demoUser{
+	{Email: "demo@cashcontrol.local", Username: "demo", Currency: "RUB", Locale: "ru", Rate: 1},
+	{Email: "demo.en@cashcontrol.local", Username: "demo_en", Currency: "USD", Locale: "en", Rate: 0.011},
+}
+
+// expenseTemplate описывает, как часто и на какие суммы создаются расходы категории.
+// Категория находится по иконке, поэтому шаблоны подходят к наборам на любом языке.
+type expenseTemplate struct {
+	Icon         string
+	PerWeek      float64
+	Min, Max     float64
+	Descriptions map[string][]string
+}
+
+var expenseTemplates = []expenseTemplate{
+	{Icon: "shopping-cart", PerWeek: 3, Min: 400, Max: 3500, Descriptions: map[string][]string{
+		"ru": {"Пятерочка", "Перекресток", "Лента", "Рынок"},
+		"en": {"Walmart", "Trader Joe's", "Whole Foods", "Farmers market"},
+	}},
+	{Icon: "utensils", PerWeek: 1.5, Min: 350, Max: 4000, Descriptions: map[string][]string{
+		"ru": {"Кофейня", "Обед в столовой", "Ужин в ресторане", "Доставка пиццы"},
+		"en": {"Coffee shop", "Lunch", "Dinner out", "Pizza delivery"},
+	}},
+	{Icon: "train", PerWeek: 4, Min: 60, Max: 250, Descriptions: map[string][]string{
+		"ru": {"Метро", "Автобус", "Электричка"},
+		"en": {"Subway", "Bus", "Commuter train"},
+	}},
+	{Icon: "car", PerWeek: 0.7, Min: 300, Max: 1500, Descriptions: map[string][]string{
+		"ru": {"Яндекс Такси"},
+		"en": {"Uber", "Lyft"},
+	}},
+	{Icon: "heart", PerWeek: 0.3, Min: 500, Max: 5000, Descriptions: map[string][]string{
+		"ru": {"Аптека", "Анализы"},
+		"en": {"Pharmacy", "Doctor visit"},
+	}},
+	{Icon: "shirt", PerWeek: 0.2, Min: 1500, Max: 9000, Descriptions: map[string][]string{
+		"ru": {"Кроссовки", "Куртка", "Футболки"},
+		"en": {"Sneakers", "Jacket", "T-shirts"},
+	}},
+	{Icon: "film", PerWeek: 0.6, Min: 400, Max: 3000, Descriptions: map[string][]string{
+		"ru": {"Кино", "Концерт", "Подписка на стриминг"},
+		"en": {"Movies", "Concert", "Streaming subscription"},
+	}},
+}
+
+// recurringTemplate регулярный ежемесячный расход демо-пользователя
+type recurringTemplate struct {
+	Icon         string
+	Amount       float64
+	DayOfMonth   int
+	Descriptions map[string]string
+}
+
+var recurringTemplates = []recurringTemplate{
+	{Icon: "home", Amount: 35000, DayOfMonth: 5, Descriptions: map[string]string{"ru": "Аренда квартиры", "en": "Rent"}},
+	{Icon: "wifi", Amount: 700, DayOfMonth: 20, Descriptions: map[string]string{"ru": "Домашний интернет", "en": "Home internet"}},
+}
+
+// budgetTemplate месячный бюджет категории в рублях; общий бюджет создается всегда
+type budgetTemplate struct {
+	Icon  string
+	Limit float64
+}
+
+var budgetTemplates = []budgetTemplate{
+	{Icon: "shopping-cart", Limit: 30000},
+	{Icon: "utensils", Limit: 12000},
+}
+
+// overallBudget общий месячный бюджет в рублях
+const overallBudget = 90000
+
+func main() {
+	days := flag.Int("days", 90, "за сколько последних дней создать расходы")
+	seed := flag.Int64("seed", 1, "начальное значение генератора случайных сумм")
+	flag.Parse()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	if err := run(logger, *days, *seed); err != nil {
+		logger.Error("seed failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
+
+func run(logger *slog.Logger, days int, seed int64) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if err := database.Init(cfg); err != nil {
+		return err
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			logger.Error("failed to close database", slog.String("error", err.Error()))
+		}
+	}()
+
+	if err := database.Migrate(); err != nil {
+		return err
+	}
+
+	defaultCategories, err := services.LoadDefaultCategories(cfg.DefaultCategoriesFile, cfg.DefaultLocale)
+	if err != nil {
+		return err
+	}
+
+	s := newSeeder(cfg, logger, defaultCategories, rand.New(rand.NewSource(seed)))
+	for _, user := range demoUsers {
+		if err := s.seedUser(user, days); err != nil {
+			return fmt.Errorf("%s: %w", user.Email, err)
+		}
+	}
+	return nil
+}
+
+type seeder struct {
+	users      repository.UserRepository
+	categories repository.CategoryRepository
+	expenses   repository.ExpenseRepository
+	auth       services.AuthService
+	budgets    services.BudgetService
+	recurring  services.RecurringExpenseService
+	random     *rand.Rand
+	logger     *slog.Logger
+}
+
+func newSeeder(cfg *config.Config, logger *slog.Logger, defaultCategories *services.DefaultCategories, random *rand.Rand) *seeder {
+	db := database.DB
+	svc := services.New(cfg, db, logger, defaultCategories)
+
+	return &seeder{
+		users:      repository.NewUserRepository(db, logger),
+		categories: repository.NewCategoryRepository(db, logger),
+		expenses:   repository.NewExpenseRepository(db, logger),
+		auth:       svc.Auth,
+		budgets:    svc.Budget,
+		recurring:  svc.RecurringExpense,
+		random:     random,
+		logger:     logger,
+	}
+}
+
+// seedUser регистрирует демо-пользователя с категориями по умолчанию и заполняет его данные
+func (s *seeder) seedUser(demo demoUser, days int) error {
+	if _, err := s.users.GetByEmail(demo.Email); err == nil {
+		s.logger.Info("demo user already exists, skipping", slog.String("email", demo.Email))
+		return nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	session, err := s.auth.Register(models.RegisterRequest{
+		Email:        demo.Email,
+		Username:     demo.Username,
+		Password:     demoPassword,
+		BaseCurrency: demo.Currency,
+		Locale:       demo.Locale,
+	})
+	if err != nil {
+		return err
+	}
+	userID := session.User.ID
+
+	categories, err := s.categories.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+	byIcon := make(map[string]uint)
+	for _, category := range categories {
+		if category.Type == models.CategoryTypeExpense {
+			byIcon[category.Icon] = category.ID
+		}
+	}
+
+	expenses := s.generateExpenses(demo, userID, byIcon, days)
+	if err := s.expenses.CreateBatch(expenses); err != nil {
+		return err
+	}
+
+	budgets, err := s.createBudgets(demo, userID, byIcon)
+	if err != nil {
+		return err
+	}
+
+	recurring, err := s.createRecurringExpenses(demo, userID, byIcon)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("demo user seeded",
+		slog.String("email", demo.Email),
+		slog.String("password", demoPassword),
+		slog.Int("categories", len(categories)),
+		slog.Int("expenses", len(expenses)),
+		slog.Int("budgets", budgets),
+		slog.Int("recurring_expenses", recurring),
+	)
+	return nil
+}
+
+// generateExpenses создает расходы за последние days дней по шаблонам; категории без шаблона пропускаются
+func (s *seeder) generateExpenses(demo demoUser, userID uint, byIcon map[string]uint, days int) []models.Expense {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	expenses := make([]models.Expense, 0)
+	for day := today.AddDate(0, 0, -days); !day.After(today); day = day.AddDate(0, 0, 1) {
+		for _, template := range expenseTemplates {
+			categoryID, ok := byIcon[template.Icon]
+			if !ok || s.random.Float64() >= template.PerWeek/7 {
+				continue
+			}
+			amount := template.Min + s.random.Float64()*(template.Max-template.Min)
+			descriptions := template.Descriptions[demo.Locale]
+			expenses = append(expenses, models.Expense{
+				UserID:      userID,
+				CategoryID:  categoryID,
+				Amount:      money.FromFloat(amount * demo.Rate).Round(demo.Currency),
+				Currency:    demo.Currency,
+				Description: descriptions[s.random.Intn(len(descriptions))],
+				Date:        day.Add(time.Duration(8+s.random.Intn(14)) * time.Hour),
+			})
+		}
+	}
+	return expenses
+}
+
+// createBudgets создает общий бюджет и бюджеты категорий на текущий и прошлый месяц
+func (s *seeder) createBudgets(demo demoUser, userID uint, byIcon map[string]uint) (int, error) {
+	now := time.Now().UTC()
+	count := 0
+	for _, month := range []time.Time{now.AddDate(0, -1, 0), now} {
+		requests := []models.CreateBudgetRequest{{
+			Amount:       money.FromFloat(overallBudget * demo.Rate).Round(demo.Currency),
+			Month:        int(month.Month()),
+			Year:         month.Year(),
+			RolloverMode: models.RolloverNone,
+		}}
+		for _, template := range budgetTemplates {
+			categoryID, ok := byIcon[template.Icon]
+			if !ok {
+				continue
+			}
+			requests = append(requests, models.CreateBudgetRequest{
+				CategoryID:   &categoryID,
+				Amount:       money.FromFloat(template.Limit * demo.Rate).Round(demo.Currency),
+				Month:        int(month.Month()),
+				Year:         month.Year(),
+				RolloverMode: models.RolloverSurplus,
+			})
+		}
+		for _, req := range requests {
+			if _, err := s.budgets.CreateBudget(userID, req); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// createRecurringExpenses создает ежемесячные регулярные расходы по шаблонам
+func (s *seeder) createRecurringExpenses(demo demoUser, userID uint, byIcon map[string]uint) (int, error) {
+	count := 0
+	for _, template := range recurringTemplates {
+		categoryID, ok := byIcon[template.Icon]
+		if !ok {
+			continue
+		}
+		day := template.DayOfMonth
+		_, err := s.recurring.CreateRecurringExpense(userID, models.CreateRecurringExpenseRequest{
+			CategoryID:  categoryID,
+			Amount:      money.FromFloat(template.Amount * demo.Rate).Round(demo.Currency),
+			Description: template.Descriptions[demo.Locale],
+			Type:        models.RecurringTypeMonthly,
+			DayOfMonth:  &day,
+		})
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
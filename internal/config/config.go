@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -19,6 +20,26 @@ type Config struct {
 	DBPort     string
 	DBSSLMode  string
 	JWTSecret  string
+
+	AccessTokenTTL  time.Duration // Время жизни JWT токена доступа
+	RefreshTokenTTL time.Duration // Время жизни refresh токена
+
+	RecurringProcessInterval time.Duration // Интервал обработки регулярных расходов, 0 отключает планировщик
+
+	ExchangeRatesFile           string        // CSV файл с общими курсами валют, пусто отключает импорт
+	ExchangeRatesImportInterval time.Duration // Интервал повторного импорта курсов из файла
+
+	TrashRetention     time.Duration // Сколько удаленные записи хранятся в корзине, 0 отключает очистку
+	TrashPurgeInterval time.Duration // Интервал очистки корзины от записей старше срока хранения
+
+	SMTPHost     string // Почтовый сервер для уведомлений, пусто отключает отправку писем
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string // Адрес отправителя уведомлений
+
+	DefaultCategoriesFile string // JSON файл с наборами категорий новых пользователей по языкам, пусто — встроенные наборы
+	DefaultLocale         string // Язык набора категорий для пользователей без указанного языка
 }
 
 func Load() (*Config, error) {
@@ -37,6 +58,38 @@ func Load() (*Config, error) {
 		DBPort:     getEnv("DB_PORT", "5432"),
 		DBSSLMode:  getEnv("DB_SSLMODE", "disable"),
 		JWTSecret:  getEnv("JWT_SECRET", "secret"),
+
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", ""),
+
+		ExchangeRatesFile: getEnv("EXCHANGE_RATES_FILE", ""),
+
+		DefaultCategoriesFile: getEnv("DEFAULT_CATEGORIES_FILE", ""),
+		DefaultLocale:         getEnv("DEFAULT_LOCALE", "ru"),
+	}
+
+	var err error
+	if cfg.AccessTokenTTL, err = getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.RefreshTokenTTL, err = getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.RecurringProcessInterval, err = getEnvDuration("RECURRING_PROCESS_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.ExchangeRatesImportInterval, err = getEnvDuration("EXCHANGE_RATES_IMPORT_INTERVAL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+
+	if cfg.TrashRetention, err = getEnvDuration("TRASH_RETENTION", 30*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.TrashPurgeInterval, err = getEnvDuration("TRASH_PURGE_INTERVAL", 24*time.Hour); err != nil {
+		return nil, err
 	}
 
 	if err := cfg.validate(); err != nil {
@@ -50,6 +103,27 @@ func (c *Config) validate() error {
 	if c.ServerAddress == "" {
 		return fmt.Errorf("SERVER_ADDRESS не может быть пустым")
 	}
+	if c.AccessTokenTTL <= 0 || c.RefreshTokenTTL <= 0 {
+		return fmt.Errorf("время жизни токенов должно быть положительным")
+	}
+	if c.AccessTokenTTL >= c.RefreshTokenTTL {
+		return fmt.Errorf("ACCESS_TOKEN_TTL должен быть меньше REFRESH_TOKEN_TTL")
+	}
+	if c.RecurringProcessInterval < 0 {
+		return fmt.Errorf("RECURRING_PROCESS_INTERVAL не может быть отрицательным")
+	}
+	if c.ExchangeRatesImportInterval < 0 {
+		return fmt.Errorf("EXCHANGE_RATES_IMPORT_INTERVAL не может быть отрицательным")
+	}
+	if c.TrashRetention < 0 {
+		return fmt.Errorf("TRASH_RETENTION не может быть отрицательным")
+	}
+	if c.TrashPurgeInterval < 0 {
+		return fmt.Errorf("TRASH_PURGE_INTERVAL не может быть отрицательным")
+	}
+	if c.SMTPHost != "" && c.SMTPFrom == "" {
+		return fmt.Errorf("SMTP_FROM обязателен, если задан SMTP_HOST")
+	}
 	return nil
 }
 
@@ -59,3 +133,15 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("некорректное значение %s: %w", key, err)
+	}
+	return d, nil
+}
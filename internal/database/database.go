@@ -107,15 +107,86 @@ func Migrate() error {
 	err := DB.AutoMigrate(
 		&models.User{},
 		&models.Category{},
+		&models.Tag{},
 		&models.Expense{},
+		&models.Income{},
+		&models.Account{},
+		&models.Transfer{},
 		&models.Budget{},
 		&models.RecurringExpense{},
 		&models.ActivityHistory{},
+		&models.RefreshToken{},
+		&models.AlertSettings{},
+		&models.BudgetAlert{},
+		&models.ExchangeRate{},
+		&models.CategorizationRule{},
 	)
 	if err != nil {
 		return fmt.Errorf("ошибка миграции: %w", err)
 	}
 
+	if err := createPartialIndexes(); err != nil {
+		return fmt.Errorf("ошибка создания индексов: %w", err)
+	}
+
+	if err := createSearchIndexes(); err != nil {
+		return fmt.Errorf("ошибка создания индексов полнотекстового поиска: %w", err)
+	}
+
+	return nil
+}
+
+// createPartialIndexes создает частичные уникальные индексы, которые нельзя описать тегами моделей
+func createPartialIndexes() error {
+	statements := []string{
+		// Один общий бюджет на пользователя и месяц
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_user_month_overall
+			ON budgets (user_id, year, month)
+			WHERE category_id IS NULL AND deleted_at IS NULL`,
+		// Один бюджет на категорию в месяц
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_user_month_category
+			ON budgets (user_id, year, month, category_id)
+			WHERE category_id IS NOT NULL AND deleted_at IS NULL`,
+		// Один курс пары на дату для каждого владельца, общие курсы имеют владельца 0
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_exchange_rates_owner_pair_date
+			ON exchange_rates (COALESCE(user_id, 0), from_currency, to_currency, date)
+			WHERE deleted_at IS NULL`,
+		// Названия меток пользователя не повторяются без учета регистра
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_user_name
+			ON tags (user_id, lower(name))
+			WHERE deleted_at IS NULL`,
+	}
+
+	for _, stmt := range statements {
+		if err := DB.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createSearchIndexes создает колонку и GIN индексы полнотекстового поиска расходов. Колонка search_vector
+// хранит описание, разобранное русской и английской конфигурациями, и пересчитывается БД при каждой записи;
+// индексы по названию категории совпадают с выражениями условия поиска в репозитории расходов
+func createSearchIndexes() error {
+	statements := []string{
+		`ALTER TABLE expenses ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (
+				to_tsvector('russian', coalesce(description, '')) || to_tsvector('english', coalesce(description, ''))
+			) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_expenses_search_vector
+			ON expenses USING GIN (search_vector)`,
+		`CREATE INDEX IF NOT EXISTS idx_categories_name_fts_ru
+			ON categories USING GIN (to_tsvector('russian', name))`,
+		`CREATE INDEX IF NOT EXISTS idx_categories_name_fts_en
+			ON categories USING GIN (to_tsvector('english', name))`,
+	}
+
+	for _, stmt := range statements {
+		if err := DB.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,74 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"cashcontrol/internal/models"
+)
+
+// utf8BOM позволяет Excel распознать кодировку CSV с кириллицей
+const utf8BOM = "\xef\xbb\xbf"
+
+// formulaPrefixes первые символы, с которых Excel и LibreOffice начинают формулу в ячейке
+const formulaPrefixes = "=+-@\t\r"
+
+type csvWriter struct {
+	out     io.Writer
+	w       *csv.Writer
+	started bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{out: w, w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	if _, err := io.WriteString(c.out, utf8BOM); err != nil {
+		return err
+	}
+	return c.w.Write(columns)
+}
+
+func (c *csvWriter) Write(row models.ExpenseExportRow) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	accountID := ""
+	if row.AccountID != nil {
+		accountID = strconv.FormatUint(uint64(*row.AccountID), 10)
+	}
+	return c.w.Write([]string{
+		strconv.FormatUint(uint64(row.ID), 10),
+		row.Date.UTC().Format(dateLayout),
+		strconv.FormatUint(uint64(row.CategoryID), 10),
+		csvText(row.CategoryName),
+		csvText(row.CategoryColor),
+		accountID,
+		row.Amount.String(),
+		row.Currency,
+		csvText(row.Description),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// csvText экранирует пользовательский текст апострофом, чтобы табличный редактор не выполнил его как формулу
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes, rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package export
+
+import (
+	"errors"
+	"io"
+
+	"cashcontrol/internal/models"
+)
+
+// Format формат выгрузки расходов
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+	FormatJSON Format = "json"
+)
+
+var ErrUnsupportedFormat = errors.New("поддерживаются форматы csv, xlsx и json")
+
+// columns заголовки колонок выгрузки в порядке значений строки
+var columns = []string{
+	"id", "date", "category_id", "category_name", "category_color",
+	"account_id", "amount", "currency", "description",
+}
+
+// dateLayout формат даты расхода в текстовых выгрузках
+const dateLayout = "2006-01-02"
+
+// Writer пишет расходы построчно. Пока не записана первая строка или не вызван Close, в поток ничего не уходит,
+// поэтому ошибку до начала выгрузки еще можно вернуть клиенту обычным ответом.
+type Writer interface {
+	Write(row models.ExpenseExportRow) error
+	Close() error
+}
+
+// NewWriter создает Writer для формата поверх w
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w), nil
+	case FormatJSON:
+		return newJSONWriter(w), nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// ContentType возвращает MIME тип файла выгрузки
+func ContentType(format Format) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatJSON:
+		return "application/json; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
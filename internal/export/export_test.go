@@ -0,0 +1,267 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
+)
+
+func testRow(id uint, description string) models.ExpenseExportRow {
+	accountID := uint(3)
+	return models.ExpenseExportRow{
+		ID:            id,
+		Date:          time.Date(2024, 3, 5, 18, 30, 0, 0, time.UTC),
+		CategoryID:    7,
+		CategoryName:  "Продукты",
+		CategoryColor: "#00ff00",
+		AccountID:     &accountID,
+		Amount:        money.FromFloat(1234.5),
+		Currency:      "RUB",
+		Description:   description,
+	}
+}
+
+// writeAll выгружает строки в формате format и возвращает файл целиком
+func writeAll(t *testing.T, format Format, rows ...models.ExpenseExportRow) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVWriterEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		description string
+		want        string
+	}{
+		{"=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"+7 900 000-00-00", "'+7 900 000-00-00"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"\tтабуляция", "'\tтабуляция"},
+		{"Кофе = 150", "Кофе = 150"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			row := testRow(1, tt.description)
+			row.CategoryName = tt.description
+			data := writeAll(t, FormatCSV, row)
+
+			if !bytes.HasPrefix(data, []byte(utf8BOM)) {
+				t.Fatal("CSV не начинается с UTF-8 BOM")
+			}
+			records, err := csv.NewReader(bytes.NewReader(data[len(utf8BOM):])).ReadAll()
+			if err != nil {
+				t.Fatalf("чтение CSV: %v", err)
+			}
+			if len(records) != 2 || strings.Join(records[0], ",") != strings.Join(columns, ",") {
+				t.Fatalf("records = %q", records)
+			}
+			record := records[1]
+			if record[3] != tt.want || record[8] != tt.want {
+				t.Errorf("category_name = %q, description = %q, want %q", record[3], record[8], tt.want)
+			}
+			// Числа и даты не экранируются
+			if record[1] != "2024-03-05" || record[6] != "1234.50" {
+				t.Errorf("date = %q, amount = %q", record[1], record[6])
+			}
+		})
+	}
+}
+
+// xlsxSheet лист книги в объеме, нужном для проверки значений ячеек
+type xlsxSheet struct {
+	Rows []struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Type   string `xml:"t,attr"`
+			Style  string `xml:"s,attr"`
+			Value  string `xml:"v"`
+			Inline string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestXLSXWriterParsesBack(t *testing.T) {
+	withoutAccount := testRow(2, `<b>"Обед" & ужин</b>`)
+	withoutAccount.AccountID = nil
+	data := writeAll(t, FormatXLSX, testRow(1, "=1+1"), withoutAccount)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("XLSX не читается как ZIP: %v", err)
+	}
+	parts := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		parts[f.Name] = f
+	}
+	for _, name := range []string{
+		"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml",
+	} {
+		f, ok := parts[name]
+		if !ok {
+			t.Fatalf("в книге нет части %s", name)
+		}
+		// Каждая часть книги должна быть корректным XML
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("открытие %s: %v", name, err)
+		}
+		decoder := xml.NewDecoder(r)
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: некорректный XML: %v", name, err)
+			}
+		}
+		r.Close()
+	}
+
+	r, err := parts["xl/worksheets/sheet1.xml"].Open()
+	if err != nil {
+		t.Fatalf("открытие листа: %v", err)
+	}
+	defer r.Close()
+	var sheet xlsxSheet
+	if err := xml.NewDecoder(r).Decode(&sheet); err != nil {
+		t.Fatalf("разбор листа: %v", err)
+	}
+
+	if len(sheet.Rows) != 3 {
+		t.Fatalf("строк на листе: %d, want 3", len(sheet.Rows))
+	}
+	for i, row := range sheet.Rows {
+		if row.Index != i+1 || len(row.Cells) != len(columns) {
+			t.Fatalf("строка %d: r=%d, ячеек %d", i+1, row.Index, len(row.Cells))
+		}
+	}
+	for i, cell := range sheet.Rows[0].Cells {
+		if cell.Type != "inlineStr" || cell.Inline != columns[i] {
+			t.Errorf("заголовок %d = %+v, want %q", i, cell, columns[i])
+		}
+	}
+
+	first := sheet.Rows[1].Cells
+	if first[0].Value != "1" || first[0].Type != "" {
+		t.Errorf("id = %+v, want число 1", first[0])
+	}
+	// Дата записывается серийным числом Excel со стилем даты
+	if first[1].Value != "45356" || first[1].Style != "1" {
+		t.Errorf("date = %+v, want 45356 со стилем 1", first[1])
+	}
+	if first[5].Value != "3" || first[6].Value != "1234.50" || first[6].Type != "" {
+		t.Errorf("account_id = %+v, amount = %+v", first[5], first[6])
+	}
+	// В XLSX текст хранится строкой и не вычисляется, поэтому не экранируется
+	if first[3].Inline != "Продукты" || first[8].Inline != "=1+1" || first[8].Type != "inlineStr" {
+		t.Errorf("category_name = %+v, description = %+v", first[3], first[8])
+	}
+
+	second := sheet.Rows[2].Cells
+	if second[5].Type != "inlineStr" || second[5].Inline != "" {
+		t.Errorf("пустой account_id = %+v", second[5])
+	}
+	if second[8].Inline != `<b>"Обед" & ужин</b>` {
+		t.Errorf("description = %q", second[8].Inline)
+	}
+}
+
+func TestJSONWriterParsesBack(t *testing.T) {
+	rows := []models.ExpenseExportRow{testRow(1, "Кофе"), testRow(2, "Обед")}
+	var got []models.ExpenseExportRow
+	if err := json.Unmarshal(writeAll(t, FormatJSON, rows...), &got); err != nil {
+		t.Fatalf("разбор JSON: %v", err)
+	}
+	if len(got) != 2 || got[1].ID != 2 || got[1].Description != "Обед" || got[0].Amount != rows[0].Amount {
+		t.Errorf("got = %+v", got)
+	}
+}
+
+func TestEmptyExport(t *testing.T) {
+	if got := string(writeAll(t, FormatJSON)); got != "[]" {
+		t.Errorf("JSON = %q, want []", got)
+	}
+	if got := string(writeAll(t, FormatCSV)); got != utf8BOM+strings.Join(columns, ",")+"\n" {
+		t.Errorf("CSV = %q, want только заголовок", got)
+	}
+	data := writeAll(t, FormatXLSX)
+	if _, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Errorf("пустая книга не читается: %v", err)
+	}
+}
+
+// countingWriter считает байты, дошедшие до клиента
+type countingWriter struct {
+	n int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return len(p), nil
+}
+
+func TestWritersStream(t *testing.T) {
+	const rows = 5000
+
+	for _, format := range []Format{FormatCSV, FormatXLSX, FormatJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			out := &countingWriter{}
+			w, err := NewWriter(format, out)
+			if err != nil {
+				t.Fatalf("NewWriter: %v", err)
+			}
+			// До первой строки в ответ ничего не пишется, чтобы ошибку еще можно было вернуть обычным ответом
+			if out.n != 0 {
+				t.Fatalf("до первой строки записано %d байт", out.n)
+			}
+
+			for i := 1; i <= rows; i++ {
+				if err := w.Write(testRow(uint(i), "Покупка в магазине у дома")); err != nil {
+					t.Fatalf("Write: %v", err)
+				}
+			}
+			// Выгрузка уходит клиенту по мере записи, а не копится в памяти до Close
+			written := out.n
+			if written == 0 {
+				t.Fatal("до Close в ответ ничего не записано")
+			}
+
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			if tail := out.n - written; tail > 64<<10 {
+				t.Errorf("при Close дописано %d байт из %d, данные буферизуются", tail, out.n)
+			}
+		})
+	}
+}
+
+func TestNewWriterUnsupportedFormat(t *testing.T) {
+	if _, err := NewWriter("pdf", io.Discard); err != ErrUnsupportedFormat {
+		t.Errorf("NewWriter: %v, want ErrUnsupportedFormat", err)
+	}
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"cashcontrol/internal/models"
+)
+
+// jsonWriter пишет JSON массив по одному элементу, не собирая его в памяти
+type jsonWriter struct {
+	out     io.Writer
+	w       *bufio.Writer
+	count   int
+	started bool
+}
+
+func newJSONWriter(w io.Writer) *jsonWriter {
+	return &jsonWriter{out: w}
+}
+
+func (j *jsonWriter) start() error {
+	if j.started {
+		return nil
+	}
+	j.started = true
+	j.w = bufio.NewWriter(j.out)
+	return j.w.WriteByte('[')
+}
+
+func (j *jsonWriter) Write(row models.ExpenseExportRow) error {
+	if err := j.start(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if j.count > 0 {
+		if err := j.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+	j.count++
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if err := j.start(); err != nil {
+		return err
+	}
+	if err := j.w.WriteByte(']'); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	service services.AccountService
+	logger  *slog.Logger
+}
+
+func NewAccountHandler(service services.AccountService, logger *slog.Logger) *AccountHandler {
+	return &AccountHandler{service: service, logger: logger}
+}
+
+func (h *AccountHandler) RegisterRoutes(r gin.IRouter) {
+	accounts := r.Group("/accounts")
+	{
+		accounts.GET("", h.List)
+		accounts.POST("", h.Create)
+		accounts.GET("/:id", h.Get)
+		accounts.PATCH("/:id", h.Update)
+		accounts.DELETE("/:id", h.Delete)
+	}
+
+	transfers := r.Group("/transfers")
+	{
+		transfers.GET("", h.ListTransfers)
+		transfers.POST("", h.CreateTransfer)
+		transfers.DELETE("/:id", h.DeleteTransfer)
+	}
+}
+
+func (h *AccountHandler) List(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	accounts, err := h.service.GetAccountList(userID)
+	if err != nil {
+		h.logger.Error("failed to get account list",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("account list retrieved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(accounts)),
+	)
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+func (h *AccountHandler) Create(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var req models.CreateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account, err := h.service.CreateAccount(userID, req)
+	if err != nil {
+		h.logger.Warn("failed to create account",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("account created",
+		slog.Uint64("account_id", uint64(account.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	c.JSON(http.StatusCreated, account)
+}
+
+func (h *AccountHandler) Get(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.service.GetAccountByID(userID, uint(id))
+	if err != nil {
+		h.respondAccountError(c, id, userID, "failed to get account", http.StatusInternalServerError, err)
+		return
+	}
+
+	h.logger.Info("account retrieved",
+		slog.Uint64("account_id", id),
+	)
+
+	c.JSON(http.StatusOK, account)
+}
+
+func (h *AccountHandler) Update(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	account, err := h.service.UpdateAccount(userID, uint(id), req)
+	if err != nil {
+		h.respondAccountError(c, id, userID, "failed to update account", http.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Info("account updated",
+		slog.Uint64("account_id", id),
+	)
+
+	c.JSON(http.StatusOK, account)
+}
+
+func (h *AccountHandler) Delete(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAccount(userID, uint(id)); err != nil {
+		if errors.Is(err, services.ErrAccountInUse) {
+			h.logger.Warn("account in use",
+				slog.Uint64("account_id", id),
+			)
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		h.respondAccountError(c, id, userID, "failed to delete account", http.StatusInternalServerError, err)
+		return
+	}
+
+	h.logger.Info("account deleted",
+		slog.Uint64("account_id", id),
+	)
+
+	c.Status(http.StatusOK)
+}
+
+func (h *AccountHandler) ListTransfers(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	filter, err := parseTransferFilter(c)
+	if err != nil {
+		h.logger.Warn("invalid transfer filter", slog.String("reason", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.UserID = userID
+
+	transfers, err := h.service.GetTransferList(filter)
+	if err != nil {
+		h.logger.Error("failed to get transfer list",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("transfer list retrieved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(transfers)),
+	)
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+func (h *AccountHandler) CreateTransfer(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var req models.CreateTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := h.service.CreateTransfer(userID, req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrMissingExchangeRate) {
+			status = http.StatusUnprocessableEntity
+		}
+		h.logger.Warn("failed to create transfer",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("transfer created",
+		slog.Uint64("transfer_id", uint64(transfer.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+func (h *AccountHandler) DeleteTransfer(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTransfer(userID, uint(id)); err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to transfer denied",
+				slog.Uint64("transfer_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrTransferNotFound) {
+			h.logger.Warn("transfer not found",
+				slog.Uint64("transfer_id", id),
+			)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to delete transfer",
+			slog.Uint64("transfer_id", id),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("transfer deleted",
+		slog.Uint64("transfer_id", id),
+	)
+
+	c.Status(http.StatusOK)
+}
+
+func (h *AccountHandler) parseID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid id",
+			slog.String("raw_id", c.Param("id")),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondAccountError отвечает 403 или 404 для чужого или несуществующего счета, иначе fallbackStatus
+func (h *AccountHandler) respondAccountError(c *gin.Context, id uint64, userID uint, msg string, fallbackStatus int, err error) {
+	if errors.Is(err, services.ErrForbidden) {
+		h.logger.Warn("access to account denied",
+			slog.Uint64("account_id", id),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrAccountNotFound) {
+		h.logger.Warn("account not found",
+			slog.Uint64("account_id", id),
+		)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if fallbackStatus == http.StatusInternalServerError {
+		h.logger.Error(msg,
+			slog.Uint64("account_id", id),
+			slog.String("error", err.Error()),
+		)
+	} else {
+		h.logger.Warn(msg,
+			slog.Uint64("account_id", id),
+			slog.String("error", err.Error()),
+		)
+	}
+	c.JSON(fallbackStatus, gin.H{"error": err.Error()})
+}
+
+func parseTransferFilter(c *gin.Context) (models.TransferFilter, error) {
+	var filter models.TransferFilter
+
+	if v := c.Query("account_id"); v != "" {
+		if id, err := strconv.ParseUint(v, 10, 64); err == nil {
+			accountID := uint(id)
+			filter.AccountID = &accountID
+		}
+	}
+
+	var err error
+	if filter.StartDate, filter.EndDate, err = parseDateRangeQuery(c); err != nil {
+		return filter, err
+	}
+	if v := c.Query("limit"); v != "" {
+		if l, err := strconv.Atoi(v); err == nil {
+			filter.Limit = &l
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if o, err := strconv.Atoi(v); err == nil {
+			filter.Offset = &o
+		}
+	}
+	return filter, nil
+}
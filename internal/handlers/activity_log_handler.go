@@ -6,7 +6,6 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -21,7 +20,7 @@ func NewActivityLogHandler(service services.ActivityLogService, logger *slog.Log
 	return &ActivityLogHandler{service: service, logger: logger}
 }
 
-func (h *ActivityLogHandler) RegisterRoutes(r *gin.Engine) {
+func (h *ActivityLogHandler) RegisterRoutes(r gin.IRouter) {
 	logs := r.Group("/logs")
 	{
 		logs.GET("", h.Get)
@@ -36,6 +35,11 @@ func (h *ActivityLogHandler) Get(c *gin.Context) {
 		slog.String("raw_query", c.Request.URL.RawQuery),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	filter, err := h.parseActivityFilter(c)
 	if err != nil {
 		h.logger.Warn("failed to parse filter",
@@ -46,6 +50,12 @@ func (h *ActivityLogHandler) Get(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	filter.UserID = userID
+
+	page, ok := requirePageRequest(c, h.logger)
+	if !ok {
+		return
+	}
 
 	h.logger.Debug("parsed filter",
 		slog.Uint64("user_id", uint64(filter.UserID)),
@@ -53,12 +63,15 @@ func (h *ActivityLogHandler) Get(c *gin.Context) {
 		slog.Any("entity_type", filter.EntityType),
 		slog.Any("start_date", filter.StartDate),
 		slog.Any("end_date", filter.EndDate),
-		slog.Any("limit", filter.Limit),
-		slog.Any("offset", filter.Offset),
+		slog.Int("limit", page.Limit),
+		slog.String("sort", page.SortBy),
 	)
 
-	logs, err := h.service.GetActivityLogs(filter)
+	logs, err := h.service.GetActivityLogs(filter, page)
 	if err != nil {
+		if respondPageRequestError(c, h.logger, err) {
+			return
+		}
 		h.logger.Error("service.GetActivityLogs failed",
 			slog.String("error", err.Error()),
 			slog.Uint64("user_id", uint64(filter.UserID)),
@@ -68,7 +81,8 @@ func (h *ActivityLogHandler) Get(c *gin.Context) {
 	}
 
 	h.logger.Info("activity logs returned",
-		slog.Int("count", len(logs)),
+		slog.Int("count", len(logs.Items)),
+		slog.Int64("total", logs.Total),
 		slog.Uint64("user_id", uint64(filter.UserID)),
 	)
 
@@ -81,6 +95,11 @@ func (h *ActivityLogHandler) Create(c *gin.Context) {
 		slog.String("path", c.FullPath()),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	var req models.CreateActivityLogRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Warn("invalid JSON body",
@@ -89,6 +108,8 @@ func (h *ActivityLogHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON body"})
 		return
 	}
+	// Запись всегда создается от имени аутентифицированного пользователя
+	req.UserID = userID
 
 	h.logger.Debug("request body parsed",
 		slog.Uint64("user_id", uint64(req.UserID)),
@@ -120,18 +141,6 @@ func (h *ActivityLogHandler) Create(c *gin.Context) {
 func (h *ActivityLogHandler) parseActivityFilter(c *gin.Context) (models.ActivityFilter, error) {
 	var filter models.ActivityFilter
 
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		return filter, errors.New("user_id is required")
-	}
-
-	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
-	if err != nil || userIDUint == 0 {
-		return filter, errors.New("invalid user_id")
-	}
-
-	filter.UserID = uint(userIDUint)
-
 	if v := c.Query("activity_type"); v != "" {
 		at := models.ActivityType(v)
 		filter.ActivityType = &at
@@ -158,21 +167,5 @@ func (h *ActivityLogHandler) parseActivityFilter(c *gin.Context) (models.Activit
 		filter.EndDate = &end
 	}
 
-	if v := c.Query("limit"); v != "" {
-		n, err := strconv.Atoi(v)
-		if err != nil || n <= 0 {
-			return filter, errors.New("invalid limit")
-		}
-		filter.Limit = &n
-	}
-
-	if v := c.Query("offset"); v != "" {
-		n, err := strconv.Atoi(v)
-		if err != nil || n < 0 {
-			return filter, errors.New("invalid offset")
-		}
-		filter.Offset = &n
-	}
-
 	return filter, nil
 }
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contextUserIDKey ключ, под которым middleware сохраняет идентификатор аутентифицированного пользователя
+const contextUserIDKey = "user_id"
+
+// AuthMiddleware проверяет JWT из заголовка Authorization и кладет идентификатор пользователя в контекст запроса
+func AuthMiddleware(authService services.AuthService, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			logger.Warn("missing or malformed authorization header",
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.FullPath()),
+			)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "необходима авторизация"})
+			return
+		}
+
+		userID, err := authService.ValidateToken(strings.TrimSpace(token))
+		if err != nil && !errors.Is(err, services.ErrInvalidToken) {
+			logger.Error("failed to validate access token",
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.FullPath()),
+				slog.String("error", err.Error()),
+			)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "ошибка проверки токена"})
+			return
+		}
+		if err != nil {
+			logger.Warn("invalid access token",
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.FullPath()),
+				slog.String("reason", err.Error()),
+			)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(contextUserIDKey, userID)
+		c.Next()
+	}
+}
+
+// currentUserID возвращает идентификатор пользователя, установленный AuthMiddleware
+func currentUserID(c *gin.Context) (uint, bool) {
+	v, ok := c.Get(contextUserIDKey)
+	if !ok {
+		return 0, false
+	}
+	userID, ok := v.(uint)
+	return userID, ok && userID > 0
+}
+
+// requireUserID достает идентификатор пользователя из контекста и отвечает 401, если его нет
+func requireUserID(c *gin.Context, logger *slog.Logger) (uint, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		logger.Warn("authenticated user is missing in context",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.FullPath()),
+		)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "необходима авторизация"})
+		return 0, false
+	}
+	return userID, true
+}
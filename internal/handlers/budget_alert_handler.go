@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BudgetAlertHandler struct {
+	service services.BudgetAlertService
+	logger  *slog.Logger
+}
+
+func NewBudgetAlertHandler(service services.BudgetAlertService, logger *slog.Logger) *BudgetAlertHandler {
+	return &BudgetAlertHandler{service: service, logger: logger}
+}
+
+func (h *BudgetAlertHandler) RegisterRoutes(r gin.IRouter) {
+	alerts := r.Group("/alerts")
+	{
+		alerts.GET("", h.List)
+		alerts.GET("/settings", h.GetSettings)
+		alerts.PUT("/settings", h.UpdateSettings)
+	}
+}
+
+func (h *BudgetAlertHandler) List(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	alerts, err := h.service.ListAlerts(userID)
+	if err != nil {
+		h.logger.Error("failed to get budget alerts",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("budget alerts retrieved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(alerts)),
+	)
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+func (h *BudgetAlertHandler) GetSettings(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	settings, err := h.service.GetSettings(userID)
+	if err != nil {
+		h.logger.Error("failed to get alert settings",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func (h *BudgetAlertHandler) UpdateSettings(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateAlertSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(userID, req)
+	if err != nil {
+		h.logger.Warn("failed to update alert settings",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("alert settings updated",
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	c.JSON(http.StatusOK, settings)
+}
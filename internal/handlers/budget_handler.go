@@ -3,6 +3,7 @@ package handlers
 import (
 	"cashcontrol/internal/models"
 	"cashcontrol/internal/services"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -19,7 +20,7 @@ func NewBudgetHandler(service services.BudgetService, logger *slog.Logger) *Budg
 	return &BudgetHandler{service: service, logger: logger}
 }
 
-func (h *BudgetHandler) RegisterRoutes(r *gin.Engine) {
+func (h *BudgetHandler) RegisterRoutes(r gin.IRouter) {
 	budgets := r.Group("/budgets")
 	{
 		budgets.GET("", h.List)
@@ -38,26 +39,21 @@ func (h *BudgetHandler) List(c *gin.Context) {
 		slog.String("path", c.FullPath()),
 	)
 
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		h.logger.Warn("missing user_id parameter")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "необходим параметр user_id"})
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
 		return
 	}
 
-	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid user_id parameter",
-			slog.String("raw_user_id", userIDStr),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный user_id"})
+	page, ok := requirePageRequest(c, h.logger)
+	if !ok {
 		return
 	}
-	userID := uint(userIDUint)
 
-	budgets, err := h.service.GetBudgetList(userID)
+	budgets, err := h.service.GetBudgetList(userID, page)
 	if err != nil {
+		if respondPageRequestError(c, h.logger, err) {
+			return
+		}
 		h.logger.Error("failed to get budget list",
 			slog.Uint64("user_id", uint64(userID)),
 			slog.String("error", err.Error()),
@@ -68,7 +64,8 @@ func (h *BudgetHandler) List(c *gin.Context) {
 
 	h.logger.Info("budget list retrieved",
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Int("count", len(budgets)),
+		slog.Int("count", len(budgets.Items)),
+		slog.Int64("total", budgets.Total),
 	)
 
 	c.JSON(http.StatusOK, budgets)
@@ -80,24 +77,11 @@ func (h *BudgetHandler) Create(c *gin.Context) {
 		slog.String("path", c.FullPath()),
 	)
 
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		h.logger.Warn("missing user_id parameter")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "необходим параметр user_id"})
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
 		return
 	}
 
-	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid user_id parameter",
-			slog.String("raw_user_id", userIDStr),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный user_id"})
-		return
-	}
-	userID := uint(userIDUint)
-
 	var req models.CreateBudgetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Warn("invalid request body",
@@ -120,7 +104,7 @@ func (h *BudgetHandler) Create(c *gin.Context) {
 	h.logger.Info("budget created",
 		slog.Uint64("budget_id", uint64(budget.ID)),
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Float64("amount", budget.Amount),
+		slog.String("amount", budget.Amount.String()),
 		slog.Int("month", budget.Month),
 		slog.Int("year", budget.Year),
 	)
@@ -135,6 +119,11 @@ func (h *BudgetHandler) Get(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid budget id",
@@ -145,9 +134,17 @@ func (h *BudgetHandler) Get(c *gin.Context) {
 		return
 	}
 
-	budget, err := h.service.GetBudgetByID(uint(id))
+	budget, err := h.service.GetBudgetByID(userID, uint(id))
 	if err != nil {
-		if err == services.ErrBudgetNotFound {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to budget denied",
+				slog.Uint64("budget_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrBudgetNotFound) {
 			h.logger.Warn("budget not found",
 				slog.Uint64("budget_id", id),
 			)
@@ -176,6 +173,11 @@ func (h *BudgetHandler) Update(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid budget id",
@@ -195,9 +197,17 @@ func (h *BudgetHandler) Update(c *gin.Context) {
 		return
 	}
 
-	budget, err := h.service.UpdateBudget(uint(id), req)
+	budget, err := h.service.UpdateBudget(userID, uint(id), req)
 	if err != nil {
-		if err == services.ErrBudgetNotFound {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to budget denied",
+				slog.Uint64("budget_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrBudgetNotFound) {
 			h.logger.Warn("budget not found for update",
 				slog.Uint64("budget_id", id),
 			)
@@ -226,6 +236,11 @@ func (h *BudgetHandler) Delete(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid budget id",
@@ -236,8 +251,16 @@ func (h *BudgetHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteBudget(uint(id)); err != nil {
-		if err == services.ErrBudgetNotFound {
+	if err := h.service.DeleteBudget(userID, uint(id)); err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to budget denied",
+				slog.Uint64("budget_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrBudgetNotFound) {
 			h.logger.Warn("budget not found for delete",
 				slog.Uint64("budget_id", id),
 			)
@@ -265,24 +288,11 @@ func (h *BudgetHandler) GetStatus(c *gin.Context) {
 		slog.String("path", c.FullPath()),
 	)
 
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		h.logger.Warn("missing user_id parameter")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "необходим параметр user_id"})
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
 		return
 	}
 
-	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid user_id parameter",
-			slog.String("raw_user_id", userIDStr),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный user_id"})
-		return
-	}
-	userID := uint(userIDUint)
-
 	monthStr := c.Query("month")
 	if monthStr == "" {
 		h.logger.Warn("missing month parameter")
@@ -319,7 +329,7 @@ func (h *BudgetHandler) GetStatus(c *gin.Context) {
 
 	status, err := h.service.GetBudgetStatus(userID, month, year)
 	if err != nil {
-		if err == services.ErrBudgetNotFound {
+		if errors.Is(err, services.ErrBudgetNotFound) {
 			h.logger.Warn("budget not found for status",
 				slog.Uint64("user_id", uint64(userID)),
 				slog.Int("month", month),
@@ -328,6 +338,16 @@ func (h *BudgetHandler) GetStatus(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if errors.Is(err, services.ErrMissingExchangeRate) {
+			h.logger.Warn("exchange rate missing for budget status",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.Int("month", month),
+				slog.Int("year", year),
+				slog.String("reason", err.Error()),
+			)
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("failed to get budget status",
 			slog.Uint64("user_id", uint64(userID)),
 			slog.Int("month", month),
@@ -342,9 +362,8 @@ func (h *BudgetHandler) GetStatus(c *gin.Context) {
 		slog.Uint64("user_id", uint64(userID)),
 		slog.Int("month", month),
 		slog.Int("year", year),
-		slog.Float64("spent", status.Spent),
-		slog.Float64("remaining", status.Remaining),
-		slog.Float64("percentage", status.Percentage),
+		slog.Bool("has_overall", status.Overall != nil),
+		slog.Int("categories", len(status.Categories)),
 	)
 
 	c.JSON(http.StatusOK, status)
@@ -356,23 +375,10 @@ func (h *BudgetHandler) GetByMonth(c *gin.Context) {
 		slog.String("path", c.FullPath()),
 	)
 
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		h.logger.Warn("missing user_id parameter")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "необходим параметр user_id"})
-		return
-	}
-
-	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid user_id parameter",
-			slog.String("raw_user_id", userIDStr),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный user_id"})
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
 		return
 	}
-	userID := uint(userIDUint)
 
 	monthStr := c.Query("month")
 	if monthStr == "" {
@@ -408,9 +414,24 @@ func (h *BudgetHandler) GetByMonth(c *gin.Context) {
 		return
 	}
 
-	budget, err := h.service.GetBudgetByUserIDAndMonth(userID, month, year)
+	var categoryID *uint
+	if v := c.Query("category_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			h.logger.Warn("invalid category_id parameter",
+				slog.String("raw_category_id", v),
+				slog.String("reason", err.Error()),
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный category_id"})
+			return
+		}
+		cid := uint(id)
+		categoryID = &cid
+	}
+
+	budget, err := h.service.GetBudgetByUserIDAndMonth(userID, month, year, categoryID)
 	if err != nil {
-		if err == services.ErrBudgetNotFound {
+		if errors.Is(err, services.ErrBudgetNotFound) {
 			h.logger.Warn("budget not found",
 				slog.Uint64("user_id", uint64(userID)),
 				slog.Int("month", month),
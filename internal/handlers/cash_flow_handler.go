@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CashFlowHandler struct {
+	service services.CashFlowService
+	logger  *slog.Logger
+}
+
+func NewCashFlowHandler(service services.CashFlowService, logger *slog.Logger) *CashFlowHandler {
+	return &CashFlowHandler{service: service, logger: logger}
+}
+
+func (h *CashFlowHandler) RegisterRoutes(r gin.IRouter) {
+	r.GET("/cash-flow", h.Get)
+}
+
+func (h *CashFlowHandler) Get(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	startDate, endDate, err := parseDateRangeQuery(c)
+	if err != nil {
+		h.logger.Warn("invalid date range", slog.String("reason", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	flow, err := h.service.GetCashFlow(userID, statisticsPeriodQuery(c), startDate, endDate)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidStatisticsPeriod) || errors.Is(err, services.ErrInvalidDateRange) {
+			h.logger.Warn("invalid cash flow request",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("reason", err.Error()),
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrMissingExchangeRate) {
+			h.logger.Warn("failed to get cash flow",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("reason", err.Error()),
+			)
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to get cash flow",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("cash flow retrieved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("periods", len(flow)),
+	)
+
+	c.JSON(http.StatusOK, flow)
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategorizationRuleHandler struct {
+	service services.CategorizationService
+	logger  *slog.Logger
+}
+
+func NewCategorizationRuleHandler(service services.CategorizationService, logger *slog.Logger) *CategorizationRuleHandler {
+	return &CategorizationRuleHandler{service: service, logger: logger}
+}
+
+func (h *CategorizationRuleHandler) RegisterRoutes(r gin.IRouter) {
+	rules := r.Group("/categorization-rules")
+	{
+		rules.GET("", h.List)
+		rules.POST("", h.Create)
+		rules.POST("/apply", h.Apply)
+		rules.GET("/:id", h.Get)
+		rules.PATCH("/:id", h.Update)
+		rules.DELETE("/:id", h.Delete)
+	}
+}
+
+func (h *CategorizationRuleHandler) List(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	rules, err := h.service.GetRuleList(userID)
+	if err != nil {
+		h.logger.Error("failed to get categorization rule list",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("categorization rule list retrieved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(rules)),
+	)
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func (h *CategorizationRuleHandler) Create(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var req models.CreateCategorizationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.service.CreateRule(userID, req)
+	if err != nil {
+		h.logger.Warn("failed to create categorization rule",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("categorization rule created",
+		slog.Uint64("rule_id", uint64(rule.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *CategorizationRuleHandler) Get(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	rule, err := h.service.GetRuleByID(userID, uint(id))
+	if err != nil {
+		h.respondRuleError(c, id, userID, "failed to get categorization rule", http.StatusInternalServerError, err)
+		return
+	}
+
+	h.logger.Info("categorization rule retrieved",
+		slog.Uint64("rule_id", id),
+	)
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *CategorizationRuleHandler) Update(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCategorizationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.service.UpdateRule(userID, uint(id), req)
+	if err != nil {
+		h.respondRuleError(c, id, userID, "failed to update categorization rule", http.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Info("categorization rule updated",
+		slog.Uint64("rule_id", id),
+	)
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *CategorizationRuleHandler) Delete(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRule(userID, uint(id)); err != nil {
+		h.respondRuleError(c, id, userID, "failed to delete categorization rule", http.StatusInternalServerError, err)
+		return
+	}
+
+	h.logger.Info("categorization rule deleted",
+		slog.Uint64("rule_id", id),
+	)
+
+	c.Status(http.StatusOK)
+}
+
+// Apply заново применяет правила к уже созданным расходам; с dry_run только показывает, что изменится
+func (h *CategorizationRuleHandler) Apply(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	// Пустое тело означает все расходы пользователя
+	var req models.ApplyCategorizationRulesRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.ApplyRules(userID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidDateRange) {
+			h.logger.Warn("invalid date range",
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to apply categorization rules",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("categorization rules applied",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("changed", result.Changed),
+		slog.Bool("dry_run", result.DryRun),
+	)
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *CategorizationRuleHandler) parseID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid id",
+			slog.String("raw_id", c.Param("id")),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondRuleError отвечает 403 или 404 для чужого или несуществующего правила, иначе fallbackStatus
+func (h *CategorizationRuleHandler) respondRuleError(c *gin.Context, id uint64, userID uint, msg string, fallbackStatus int, err error) {
+	if errors.Is(err, services.ErrForbidden) {
+		h.logger.Warn("access to categorization rule denied",
+			slog.Uint64("rule_id", id),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrCategorizationRuleNotFound) {
+		h.logger.Warn("categorization rule not found",
+			slog.Uint64("rule_id", id),
+		)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if fallbackStatus == http.StatusInternalServerError {
+		h.logger.Error(msg,
+			slog.Uint64("rule_id", id),
+			slog.String("error", err.Error()),
+		)
+	} else {
+		h.logger.Warn(msg,
+			slog.Uint64("rule_id", id),
+			slog.String("error", err.Error()),
+		)
+	}
+	c.JSON(fallbackStatus, gin.H{"error": err.Error()})
+}
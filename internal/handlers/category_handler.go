@@ -3,6 +3,7 @@ package handlers
 import (
 	"cashcontrol/internal/models"
 	"cashcontrol/internal/services"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -19,36 +20,51 @@ func NewCategoryHandler(service services.CategoryService, logger *slog.Logger) *
 	return &CategoryHandler{service: service, logger: logger}
 }
 
-func (h *CategoryHandler) RegisterRoutes(r *gin.Engine) {
+func (h *CategoryHandler) RegisterRoutes(r gin.IRouter) {
 	categories := r.Group("/categories")
 	{
-		categories.GET("/:userId", h.ListByUser)
-		categories.POST("/:userId", h.Create)
-		categories.GET("/detail/:id", h.Get)
+		categories.GET("", h.List)
+		categories.POST("", h.Create)
+		categories.GET("/tree", h.Tree)
+		categories.POST("/merge", h.Merge)
+		categories.GET("/:id", h.Get)
+		categories.GET("/:id/usage", h.Usage)
 		categories.PATCH("/:id", h.Update)
 		categories.DELETE("/:id", h.Delete)
 	}
 }
 
-func (h *CategoryHandler) ListByUser(c *gin.Context) {
+func (h *CategoryHandler) List(c *gin.Context) {
 	h.logger.Info("incoming request",
 		slog.String("method", c.Request.Method),
 		slog.String("path", c.FullPath()),
 	)
 
-	userIDUint, err := strconv.ParseUint(c.Param("userId"), 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid user id",
-			slog.String("raw_id", c.Param("userId")),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор пользователя"})
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var categoryType *models.CategoryType
+	if v := c.Query("type"); v != "" {
+		t := models.CategoryType(v)
+		categoryType = &t
+	}
+
+	page, ok := requirePageRequest(c, h.logger)
+	if !ok {
 		return
 	}
-	userID := uint(userIDUint)
 
-	categories, err := h.service.GetCategoryList(userID)
+	categories, err := h.service.GetCategoryList(userID, categoryType, page)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidCategoryType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if respondPageRequestError(c, h.logger, err) {
+			return
+		}
 		h.logger.Error("failed to get category list",
 			slog.Uint64("user_id", uint64(userID)),
 			slog.String("error", err.Error()),
@@ -59,28 +75,63 @@ func (h *CategoryHandler) ListByUser(c *gin.Context) {
 
 	h.logger.Info("category list retrieved",
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Int("count", len(categories)),
+		slog.Int("count", len(categories.Items)),
+		slog.Int64("total", categories.Total),
 	)
 
 	c.JSON(http.StatusOK, categories)
 }
 
-func (h *CategoryHandler) Create(c *gin.Context) {
+// Tree возвращает категории деревом с вложенными подкатегориями
+func (h *CategoryHandler) Tree(c *gin.Context) {
 	h.logger.Info("incoming request",
 		slog.String("method", c.Request.Method),
 		slog.String("path", c.FullPath()),
 	)
 
-	userIDUint, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var categoryType *models.CategoryType
+	if v := c.Query("type"); v != "" {
+		t := models.CategoryType(v)
+		categoryType = &t
+	}
+
+	tree, err := h.service.GetCategoryTree(userID, categoryType)
 	if err != nil {
-		h.logger.Warn("invalid user id",
-			slog.String("raw_id", c.Param("userId")),
-			slog.String("reason", err.Error()),
+		if errors.Is(err, services.ErrInvalidCategoryType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to get category tree",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
 		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор пользователя"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("category tree retrieved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("roots", len(tree)),
+	)
+
+	c.JSON(http.StatusOK, tree)
+}
+
+func (h *CategoryHandler) Create(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
 		return
 	}
-	userID := uint(userIDUint)
 
 	var req models.CreateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -116,6 +167,11 @@ func (h *CategoryHandler) Get(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid category id",
@@ -126,8 +182,23 @@ func (h *CategoryHandler) Get(c *gin.Context) {
 		return
 	}
 
-	category, err := h.service.GetCategoryByID(uint(id))
+	category, err := h.service.GetCategoryByID(userID, uint(id))
 	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to category denied",
+				slog.Uint64("category_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			h.logger.Warn("category not found",
+				slog.Uint64("category_id", id),
+			)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("failed to get category",
 			slog.Uint64("category_id", id),
 			slog.String("error", err.Error()),
@@ -150,6 +221,11 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid category id",
@@ -169,8 +245,23 @@ func (h *CategoryHandler) Update(c *gin.Context) {
 		return
 	}
 
-	category, err := h.service.UpdateCategory(uint(id), req)
+	category, err := h.service.UpdateCategory(userID, uint(id), req)
 	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to category denied",
+				slog.Uint64("category_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			h.logger.Warn("category not found",
+				slog.Uint64("category_id", id),
+			)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Warn("failed to update category",
 			slog.Uint64("category_id", id),
 			slog.String("error", err.Error()),
@@ -193,6 +284,11 @@ func (h *CategoryHandler) Delete(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid category id",
@@ -203,8 +299,39 @@ func (h *CategoryHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err = h.service.DeleteCategory(uint(id))
+	var req models.DeleteCategoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.logger.Warn("invalid delete parameters",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.DeleteCategory(userID, uint(id), req)
 	if err != nil {
+		if errors.Is(err, services.ErrCategoryInUse) {
+			h.logger.Warn("category in use",
+				slog.Uint64("category_id", id),
+			)
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to category denied",
+				slog.Uint64("category_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			h.logger.Warn("category not found",
+				slog.Uint64("category_id", id),
+			)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Warn("failed to delete category",
 			slog.Uint64("category_id", id),
 			slog.String("error", err.Error()),
@@ -217,5 +344,113 @@ func (h *CategoryHandler) Delete(c *gin.Context) {
 		slog.Uint64("category_id", id),
 	)
 
-	c.JSON(http.StatusOK, gin.H{"message": "категория удалена"})
+	c.JSON(http.StatusOK, result)
+}
+
+// Merge переносит все записи нескольких категорий в одну и удаляет исходные категории
+func (h *CategoryHandler) Merge(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var req models.MergeCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.MergeCategories(userID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to category denied",
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			h.logger.Warn("category not found",
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Warn("failed to merge categories",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("categories merged",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("target_id", uint64(req.TargetID)),
+	)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Usage возвращает количество записей, которые ссылаются на категорию
+func (h *CategoryHandler) Usage(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid category id",
+			slog.String("raw_id", c.Param("id")),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+		return
+	}
+
+	usage, err := h.service.GetCategoryUsage(userID, uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to category denied",
+				slog.Uint64("category_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrCategoryNotFound) {
+			h.logger.Warn("category not found",
+				slog.Uint64("category_id", id),
+			)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to get category usage",
+			slog.Uint64("category_id", id),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("category usage retrieved",
+		slog.Uint64("category_id", id),
+	)
+
+	c.JSON(http.StatusOK, usage)
 }
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/rates"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxRatesFileSize ограничивает размер загружаемого файла курсов
+const maxRatesFileSize = 5 << 20
+
+type ExchangeRateHandler struct {
+	service services.CurrencyService
+	logger  *slog.Logger
+}
+
+func NewExchangeRateHandler(service services.CurrencyService, logger *slog.Logger) *ExchangeRateHandler {
+	return &ExchangeRateHandler{service: service, logger: logger}
+}
+
+func (h *ExchangeRateHandler) RegisterRoutes(r gin.IRouter) {
+	exchangeRates := r.Group("/exchange-rates")
+	{
+		exchangeRates.GET("", h.List)
+		exchangeRates.POST("", h.Create)
+		exchangeRates.POST("/import", h.Import)
+		exchangeRates.DELETE("/:id", h.Delete)
+	}
+}
+
+func (h *ExchangeRateHandler) List(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	list, err := h.service.ListRates(models.ExchangeRateFilter{
+		UserID:       userID,
+		FromCurrency: c.Query("from"),
+		ToCurrency:   c.Query("to"),
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCurrency) {
+			h.logger.Warn("invalid currency filter",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("reason", err.Error()),
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to get exchange rates",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("exchange rates retrieved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(list)),
+	)
+
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *ExchangeRateHandler) Create(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var req models.CreateExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rate, err := h.service.CreateRate(userID, req)
+	if err != nil {
+		h.logger.Warn("failed to create exchange rate",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("exchange rate created",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("from", rate.FromCurrency),
+		slog.String("to", rate.ToCurrency),
+	)
+
+	c.JSON(http.StatusCreated, rate)
+}
+
+// Import загружает курсы пользователя из CSV файла в поле file формы multipart
+func (h *ExchangeRateHandler) Import(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRatesFileSize)
+	header, err := c.FormFile("file")
+	if err != nil {
+		h.logger.Warn("invalid rates file",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "необходим CSV файл в поле file"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		h.logger.Error("failed to open rates file",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	imported, err := h.service.ImportRates(c.Request.Context(), &userID, rates.NewCSVProvider(header.Filename, file))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRatesSource) {
+			h.logger.Warn("rates file rejected",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("reason", err.Error()),
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to import exchange rates",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("exchange rates imported",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", imported),
+	)
+
+	c.JSON(http.StatusOK, models.ExchangeRateImportResult{Imported: imported})
+}
+
+func (h *ExchangeRateHandler) Delete(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid exchange rate id",
+			slog.String("raw_id", c.Param("id")),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+		return
+	}
+
+	if err := h.service.DeleteRate(userID, uint(id)); err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to exchange rate denied",
+				slog.Uint64("rate_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrExchangeRateNotFound) {
+			h.logger.Warn("exchange rate not found for delete",
+				slog.Uint64("rate_id", id),
+			)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to delete exchange rate",
+			slog.Uint64("rate_id", id),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("exchange rate deleted",
+		slog.Uint64("rate_id", id),
+	)
+
+	c.Status(http.StatusOK)
+}
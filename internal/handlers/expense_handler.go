@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"cashcontrol/internal/export"
 	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
 	"cashcontrol/internal/services"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,11 +26,13 @@ func NewExpenseHandler(service services.ExpenseService, logger *slog.Logger) *Ex
 	return &ExpenseHandler{service: service, logger: logger}
 }
 
-func (h *ExpenseHandler) RegisterRoutes(r *gin.Engine) {
+func (h *ExpenseHandler) RegisterRoutes(r gin.IRouter) {
 	expenses := r.Group("/expenses")
 	{
 		expenses.GET("", h.List)
 		expenses.POST("", h.Create)
+		expenses.GET("/grouped", h.Grouped)
+		expenses.GET("/export", h.Export)
 		expenses.GET("/:id", h.Get)
 		expenses.PATCH("/:id", h.Update)
 		expenses.DELETE("/:id", h.Delete)
@@ -37,10 +45,24 @@ func (h *ExpenseHandler) List(c *gin.Context) {
 		slog.String("path", c.FullPath()),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	filter, _ := h.parseExpenseFilter(c) // Игнорируем ошибку парсинга фильтра, так как все поля опциональны
+	filter.UserID = userID
+
+	page, ok := requirePageRequest(c, h.logger)
+	if !ok {
+		return
+	}
 
-	expenses, err := h.service.GetExpenseList(filter)
+	expenses, err := h.service.GetExpenseList(filter, page)
 	if err != nil {
+		if respondPageRequestError(c, h.logger, err) {
+			return
+		}
 		h.logger.Error("failed to get expense list",
 			slog.String("error", err.Error()),
 		)
@@ -49,35 +71,81 @@ func (h *ExpenseHandler) List(c *gin.Context) {
 	}
 
 	h.logger.Info("expense list retrieved",
-		slog.Int("count", len(expenses)),
+		slog.Int("count", len(expenses.Items)),
+		slog.Int64("total", expenses.Total),
 	)
 
 	c.JSON(http.StatusOK, expenses)
 }
 
-func (h *ExpenseHandler) Create(c *gin.Context) {
+func (h *ExpenseHandler) Grouped(c *gin.Context) {
 	h.logger.Info("incoming request",
 		slog.String("method", c.Request.Method),
 		slog.String("path", c.FullPath()),
 	)
 
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		h.logger.Warn("missing user_id parameter")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "необходим параметр user_id"})
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
 		return
 	}
 
-	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
+	filter, err := parseExpenseGroupFilter(c)
 	if err != nil {
-		h.logger.Warn("invalid user_id parameter",
-			slog.String("raw_user_id", userIDStr),
+		h.logger.Warn("invalid group parameters",
 			slog.String("reason", err.Error()),
 		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный user_id"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.UserID = userID
+
+	groups, err := h.service.GetGroupedExpenses(filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidGroupPeriod) ||
+			errors.Is(err, services.ErrInvalidDateRange) ||
+			errors.Is(err, services.ErrTooManyExpenseGroups) ||
+			errors.Is(err, services.ErrTooManyGroupExpenses) {
+			h.logger.Warn("invalid group parameters",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("reason", err.Error()),
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrMissingExchangeRate) {
+			h.logger.Warn("exchange rate missing for grouping",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("reason", err.Error()),
+			)
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to group expenses",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("expense groups retrieved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(groups)),
+	)
+
+	c.JSON(http.StatusOK, groups)
+}
+
+func (h *ExpenseHandler) Create(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
 		return
 	}
-	userID := uint(userIDUint)
 
 	var req models.CreateExpenseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -106,6 +174,62 @@ func (h *ExpenseHandler) Create(c *gin.Context) {
 	c.JSON(http.StatusCreated, expense)
 }
 
+// Export отдает расходы по тем же фильтрам, что и список, файлом csv, xlsx или json; строки пишутся в ответ по мере чтения из БД
+func (h *ExpenseHandler) Export(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	format := export.Format(c.DefaultQuery("format", string(export.FormatCSV)))
+	writer, err := export.NewWriter(format, c.Writer)
+	if err != nil {
+		h.logger.Warn("invalid export format",
+			slog.String("format", string(format)),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, _ := h.parseExpenseFilter(c) // Все поля фильтра опциональны, как и в списке
+	filter.UserID = userID
+
+	filename := fmt.Sprintf("expenses-%s.%s", time.Now().UTC().Format("2006-01-02"), format)
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	err = h.service.ExportExpenses(filter, writer.Write)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		// Пока в ответ ничего не записано, можно вернуть обычную ошибку; иначе выгрузка просто обрывается
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			c.Writer.Header().Del("Content-Type")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("expense export interrupted",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.Abort()
+		return
+	}
+
+	h.logger.Info("expenses exported",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("format", string(format)),
+	)
+}
+
 func (h *ExpenseHandler) Get(c *gin.Context) {
 	h.logger.Info("incoming request",
 		slog.String("method", c.Request.Method),
@@ -113,6 +237,11 @@ func (h *ExpenseHandler) Get(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid expense id",
@@ -123,9 +252,17 @@ func (h *ExpenseHandler) Get(c *gin.Context) {
 		return
 	}
 
-	expense, err := h.service.GetExpenseByID(uint(id))
+	expense, err := h.service.GetExpenseByID(userID, uint(id))
 	if err != nil {
-		if err == services.ErrExpenseNotFound {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to expense denied",
+				slog.Uint64("expense_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrExpenseNotFound) {
 			h.logger.Warn("expense not found",
 				slog.Uint64("expense_id", id),
 			)
@@ -154,6 +291,11 @@ func (h *ExpenseHandler) Update(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid expense id",
@@ -173,8 +315,23 @@ func (h *ExpenseHandler) Update(c *gin.Context) {
 		return
 	}
 
-	expense, err := h.service.UpdateExpense(uint(id), req)
+	expense, err := h.service.UpdateExpense(userID, uint(id), req)
 	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to expense denied",
+				slog.Uint64("expense_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrExpenseNotFound) {
+			h.logger.Warn("expense not found",
+				slog.Uint64("expense_id", id),
+			)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Warn("failed to update expense",
 			slog.Uint64("expense_id", id),
 			slog.String("error", err.Error()),
@@ -197,6 +354,11 @@ func (h *ExpenseHandler) Delete(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid expense id",
@@ -207,7 +369,22 @@ func (h *ExpenseHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteExpense(uint(id)); err != nil {
+	if err := h.service.DeleteExpense(userID, uint(id)); err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to expense denied",
+				slog.Uint64("expense_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrExpenseNotFound) {
+			h.logger.Warn("expense not found",
+				slog.Uint64("expense_id", id),
+			)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		h.logger.Error("failed to delete expense",
 			slog.Uint64("expense_id", id),
 			slog.String("error", err.Error()),
@@ -226,19 +403,24 @@ func (h *ExpenseHandler) Delete(c *gin.Context) {
 func (h *ExpenseHandler) parseExpenseFilter(c *gin.Context) (models.ExpenseFilter, error) {
 	var filter models.ExpenseFilter
 
-	// user_id обязателен
-	if v := c.Query("user_id"); v != "" {
-		if id, err := strconv.ParseUint(v, 10, 64); err == nil {
-			filter.UserID = uint(id)
-		}
-	}
-
+	// Владелец расходов берется из токена, а не из параметров запроса
 	if v := c.Query("category_id"); v != "" {
 		if id, err := strconv.ParseUint(v, 10, 64); err == nil {
 			categoryID := uint(id)
 			filter.CategoryID = &categoryID
 		}
 	}
+	if v := c.Query("include_subcategories"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			filter.IncludeSubcategories = b
+		}
+	}
+	if v := c.Query("account_id"); v != "" {
+		if id, err := strconv.ParseUint(v, 10, 64); err == nil {
+			accountID := uint(id)
+			filter.AccountID = &accountID
+		}
+	}
 	if v := c.Query("start_date"); v != "" {
 		if t, err := time.Parse("2006-01-02", v); err == nil {
 			filter.StartDate = &t
@@ -249,16 +431,66 @@ func (h *ExpenseHandler) parseExpenseFilter(c *gin.Context) (models.ExpenseFilte
 			filter.EndDate = &t
 		}
 	}
-	if v := c.Query("limit"); v != "" {
-		if l, err := strconv.Atoi(v); err == nil {
-			filter.Limit = &l
+	if v := c.Query("min_amount"); v != "" {
+		if a, err := money.Parse(v); err == nil {
+			filter.MinAmount = &a
+		}
+	}
+	if v := c.Query("max_amount"); v != "" {
+		if a, err := money.Parse(v); err == nil {
+			filter.MaxAmount = &a
 		}
 	}
-	if v := c.Query("offset"); v != "" {
-		if o, err := strconv.Atoi(v); err == nil {
-			filter.Offset = &o
+	// Метки передаются списком через запятую, некорректные идентификаторы пропускаются
+	if v := c.Query("tag_ids"); v != "" {
+		for _, raw := range strings.Split(v, ",") {
+			if id, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64); err == nil {
+				filter.TagIDs = append(filter.TagIDs, uint(id))
+			}
 		}
 	}
+	if models.TagMatch(c.Query("tag_match")) == models.TagMatchAll {
+		filter.TagMatch = models.TagMatchAll
+	} else {
+		filter.TagMatch = models.TagMatchAny
+	}
+	// Запрос без букв и цифр искать нечего, он не учитывается
+	if v := strings.TrimSpace(c.Query("q")); strings.ContainsFunc(v, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) {
+		filter.Search = v
+	}
+
+	return filter, nil
+}
+
+func parseExpenseGroupFilter(c *gin.Context) (models.ExpenseGroupFilter, error) {
+	filter := models.ExpenseGroupFilter{
+		By: models.StatisticsPeriod(c.DefaultQuery("by", string(models.PeriodDay))),
+	}
 
+	var err error
+	if filter.StartDate, filter.EndDate, err = parseDateRangeQuery(c); err != nil {
+		return filter, err
+	}
+	if filter.IncludeExpenses, err = parseBoolQuery(c, "include_expenses"); err != nil {
+		return filter, err
+	}
+	if filter.FillEmpty, err = parseBoolQuery(c, "fill_empty"); err != nil {
+		return filter, err
+	}
 	return filter, nil
 }
+
+// parseBoolQuery разбирает необязательный логический параметр, по умолчанию false
+func parseBoolQuery(c *gin.Context, key string) (bool, error) {
+	v := c.Query(key)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("некорректный параметр %s, ожидается true или false", key)
+	}
+	return b, nil
+}
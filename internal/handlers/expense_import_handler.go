@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"cashcontrol/internal/importer"
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxStatementFileSize ограничивает размер загружаемой выписки
+const maxStatementFileSize = 5 << 20
+
+type ExpenseImportHandler struct {
+	service services.ExpenseImportService
+	logger  *slog.Logger
+}
+
+func NewExpenseImportHandler(service services.ExpenseImportService, logger *slog.Logger) *ExpenseImportHandler {
+	return &ExpenseImportHandler{service: service, logger: logger}
+}
+
+func (h *ExpenseImportHandler) RegisterRoutes(r gin.IRouter) {
+	imports := r.Group("/expenses/import")
+	{
+		imports.POST("", h.Import)
+		imports.POST("/preview", h.Preview)
+	}
+}
+
+func (h *ExpenseImportHandler) Preview(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	req, file, ok := h.bindStatement(c, userID)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	preview, err := h.service.PreviewImport(userID, req, file)
+	if err != nil {
+		h.respondImportError(c, userID, "failed to preview expense import", err)
+		return
+	}
+
+	h.logger.Info("expense import previewed",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("rows", len(preview.Rows)),
+	)
+
+	c.JSON(http.StatusOK, preview)
+}
+
+func (h *ExpenseImportHandler) Import(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	req, file, ok := h.bindStatement(c, userID)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	result, err := h.service.ImportExpenses(userID, req, file)
+	if err != nil {
+		h.respondImportError(c, userID, "failed to import expenses", err)
+		return
+	}
+
+	h.logger.Info("expenses imported",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("imported", result.Imported),
+	)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// bindStatement читает параметры импорта из формы и открывает файл выписки; формат по умолчанию берется из расширения
+func (h *ExpenseImportHandler) bindStatement(c *gin.Context, userID uint) (models.ExpenseImportRequest, multipart.File, bool) {
+	var req models.ExpenseImportRequest
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxStatementFileSize)
+	header, err := c.FormFile("file")
+	if err != nil {
+		h.logger.Warn("invalid statement file",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "необходим файл выписки в поле file"})
+		return req, nil, false
+	}
+
+	if err := c.ShouldBind(&req); err != nil {
+		h.logger.Warn("invalid import parameters",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, nil, false
+	}
+	if req.Format == "" {
+		format, err := importer.DetectFormat(header.Filename)
+		if err != nil {
+			h.logger.Warn("unknown statement format",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("filename", header.Filename),
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "не удалось определить формат по имени файла, укажите format: " + err.Error()})
+			return req, nil, false
+		}
+		req.Format = string(format)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		h.logger.Error("failed to open statement file",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return req, nil, false
+	}
+
+	return req, file, true
+}
+
+// respondImportError отвечает 400 на ошибки в файле и параметрах, 422 на выписку с некорректными строками, иначе 500
+func (h *ExpenseImportHandler) respondImportError(c *gin.Context, userID uint, msg string, err error) {
+	switch {
+	case errors.Is(err, services.ErrImportHasInvalidRows):
+		h.logger.Warn(msg,
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidImportFile),
+		errors.Is(err, services.ErrInvalidDecimalSeparator),
+		errors.Is(err, services.ErrTooManyImportRows),
+		errors.Is(err, importer.ErrUnsupportedFormat),
+		errors.Is(err, services.ErrCategoryNotFound),
+		errors.Is(err, services.ErrCategoryTypeMismatch),
+		errors.Is(err, services.ErrAccountNotFound),
+		errors.Is(err, services.ErrAccountCurrencyMismatch),
+		errors.Is(err, services.ErrInvalidCurrency):
+		h.logger.Warn(msg,
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(msg,
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
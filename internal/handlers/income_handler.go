@@ -0,0 +1,311 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IncomeHandler struct {
+	service services.IncomeService
+	logger  *slog.Logger
+}
+
+func NewIncomeHandler(service services.IncomeService, logger *slog.Logger) *IncomeHandler {
+	return &IncomeHandler{service: service, logger: logger}
+}
+
+func (h *IncomeHandler) RegisterRoutes(r gin.IRouter) {
+	incomes := r.Group("/incomes")
+	{
+		incomes.GET("", h.List)
+		incomes.POST("", h.Create)
+		incomes.GET("/:id", h.Get)
+		incomes.PATCH("/:id", h.Update)
+		incomes.DELETE("/:id", h.Delete)
+	}
+}
+
+func (h *IncomeHandler) List(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	filter, _ := h.parseIncomeFilter(c) // Игнорируем ошибку парсинга фильтра, так как все поля опциональны
+	filter.UserID = userID
+
+	incomes, err := h.service.GetIncomeList(filter)
+	if err != nil {
+		h.logger.Error("failed to get income list",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("income list retrieved",
+		slog.Int("count", len(incomes)),
+	)
+
+	c.JSON(http.StatusOK, incomes)
+}
+
+func (h *IncomeHandler) Create(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var req models.CreateIncomeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	income, err := h.service.CreateIncome(userID, req)
+	if err != nil {
+		h.logger.Warn("failed to create income",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("income created",
+		slog.Uint64("income_id", uint64(income.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	c.JSON(http.StatusCreated, income)
+}
+
+func (h *IncomeHandler) Get(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid income id",
+			slog.String("raw_id", c.Param("id")),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+		return
+	}
+
+	income, err := h.service.GetIncomeByID(userID, uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to income denied",
+				slog.Uint64("income_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrIncomeNotFound) {
+			h.logger.Warn("income not found",
+				slog.Uint64("income_id", id),
+			)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to get income",
+			slog.Uint64("income_id", id),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("income retrieved",
+		slog.Uint64("income_id", id),
+	)
+
+	c.JSON(http.StatusOK, income)
+}
+
+func (h *IncomeHandler) Update(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid income id",
+			slog.String("raw_id", c.Param("id")),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+		return
+	}
+
+	var req models.UpdateIncomeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	income, err := h.service.UpdateIncome(userID, uint(id), req)
+	if err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to income denied",
+				slog.Uint64("income_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrIncomeNotFound) {
+			h.logger.Warn("income not found",
+				slog.Uint64("income_id", id),
+			)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Warn("failed to update income",
+			slog.Uint64("income_id", id),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("income updated",
+		slog.Uint64("income_id", id),
+	)
+
+	c.JSON(http.StatusOK, income)
+}
+
+func (h *IncomeHandler) Delete(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid income id",
+			slog.String("raw_id", c.Param("id")),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+		return
+	}
+
+	if err := h.service.DeleteIncome(userID, uint(id)); err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to income denied",
+				slog.Uint64("income_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrIncomeNotFound) {
+			h.logger.Warn("income not found",
+				slog.Uint64("income_id", id),
+			)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to delete income",
+			slog.Uint64("income_id", id),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("income deleted",
+		slog.Uint64("income_id", id),
+	)
+
+	c.Status(http.StatusOK)
+}
+
+func (h *IncomeHandler) parseIncomeFilter(c *gin.Context) (models.IncomeFilter, error) {
+	var filter models.IncomeFilter
+
+	// Владелец доходов берется из токена, а не из параметров запроса
+	if v := c.Query("category_id"); v != "" {
+		if id, err := strconv.ParseUint(v, 10, 64); err == nil {
+			categoryID := uint(id)
+			filter.CategoryID = &categoryID
+		}
+	}
+	if v := c.Query("account_id"); v != "" {
+		if id, err := strconv.ParseUint(v, 10, 64); err == nil {
+			accountID := uint(id)
+			filter.AccountID = &accountID
+		}
+	}
+	if v := c.Query("start_date"); v != "" {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			filter.StartDate = &t
+		}
+	}
+	if v := c.Query("end_date"); v != "" {
+		if t, err := time.Parse("2006-01-02", v); err == nil {
+			filter.EndDate = &t
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		if l, err := strconv.Atoi(v); err == nil {
+			filter.Limit = &l
+		}
+	}
+	if v := c.Query("offset"); v != "" {
+		if o, err := strconv.Atoi(v); err == nil {
+			filter.Offset = &o
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requirePageRequest читает параметры страницы limit, cursor, sort и order и отвечает 400, если limit не число.
+// Допустимость значений проверяет сервис списка.
+func requirePageRequest(c *gin.Context, logger *slog.Logger) (models.PageRequest, bool) {
+	page := models.PageRequest{
+		Cursor: c.Query("cursor"),
+		SortBy: c.Query("sort"),
+		Order:  models.SortOrder(c.Query("order")),
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			logger.Warn("invalid limit",
+				slog.String("raw_limit", v),
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit должен быть целым числом"})
+			return page, false
+		}
+		page.Limit = n
+	}
+	return page, true
+}
+
+// respondPageRequestError отвечает 400 на некорректные параметры страницы; для других ошибок возвращает false
+func respondPageRequestError(c *gin.Context, logger *slog.Logger, err error) bool {
+	if !errors.Is(err, services.ErrInvalidPageRequest) {
+		return false
+	}
+	logger.Warn("invalid page request",
+		slog.String("path", c.FullPath()),
+		slog.String("reason", err.Error()),
+	)
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	return true
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"cashcontrol/internal/models"
 	"cashcontrol/internal/services"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -19,7 +20,7 @@ func NewRecurringExpenseHandler(service services.RecurringExpenseService, logger
 	return &RecurringExpenseHandler{service: service, logger: logger}
 }
 
-func (h *RecurringExpenseHandler) RegisterRoutes(r *gin.Engine) {
+func (h *RecurringExpenseHandler) RegisterRoutes(r gin.IRouter) {
 	recurringExpenses := r.Group("/recurring-expenses")
 	{
 		recurringExpenses.GET("", h.List)
@@ -39,34 +40,21 @@ func (h *RecurringExpenseHandler) List(c *gin.Context) {
 		slog.String("path", c.FullPath()),
 	)
 
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		h.logger.Warn("missing user_id parameter")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "необходим параметр user_id"})
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
 		return
 	}
 
-	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid user_id parameter",
-			slog.String("raw_user_id", userIDStr),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный user_id"})
-		return
-	}
-	userID := uint(userIDUint)
-	if err != nil {
-		h.logger.Warn("invalid user_id parameter",
-			slog.String("raw_user_id", userIDStr),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный user_id"})
+	page, ok := requirePageRequest(c, h.logger)
+	if !ok {
 		return
 	}
 
-	recurringExpenses, err := h.service.GetRecurringExpenseList(userID)
+	recurringExpenses, err := h.service.GetRecurringExpenseList(userID, page)
 	if err != nil {
+		if respondPageRequestError(c, h.logger, err) {
+			return
+		}
 		h.logger.Error("failed to get recurring expense list",
 			slog.Uint64("user_id", uint64(userID)),
 			slog.String("error", err.Error()),
@@ -77,7 +65,8 @@ func (h *RecurringExpenseHandler) List(c *gin.Context) {
 
 	h.logger.Info("recurring expense list retrieved",
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Int("count", len(recurringExpenses)),
+		slog.Int("count", len(recurringExpenses.Items)),
+		slog.Int64("total", recurringExpenses.Total),
 	)
 
 	c.JSON(http.StatusOK, recurringExpenses)
@@ -89,29 +78,8 @@ func (h *RecurringExpenseHandler) Create(c *gin.Context) {
 		slog.String("path", c.FullPath()),
 	)
 
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		h.logger.Warn("missing user_id parameter")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "необходим параметр user_id"})
-		return
-	}
-
-	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid user_id parameter",
-			slog.String("raw_user_id", userIDStr),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный user_id"})
-		return
-	}
-	userID := uint(userIDUint)
-	if err != nil {
-		h.logger.Warn("invalid user_id parameter",
-			slog.String("raw_user_id", userIDStr),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный user_id"})
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
 		return
 	}
 
@@ -150,6 +118,11 @@ func (h *RecurringExpenseHandler) Get(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid recurring expense id",
@@ -160,9 +133,17 @@ func (h *RecurringExpenseHandler) Get(c *gin.Context) {
 		return
 	}
 
-	recurringExpense, err := h.service.GetRecurringExpenseByID(uint(id))
+	recurringExpense, err := h.service.GetRecurringExpenseByID(userID, uint(id))
 	if err != nil {
-		if err == services.ErrRecurringExpenseNotFound {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to recurring expense denied",
+				slog.Uint64("recurring_expense_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrRecurringExpenseNotFound) {
 			h.logger.Warn("recurring expense not found",
 				slog.Uint64("recurring_expense_id", id),
 			)
@@ -191,6 +172,11 @@ func (h *RecurringExpenseHandler) Update(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid recurring expense id",
@@ -210,9 +196,17 @@ func (h *RecurringExpenseHandler) Update(c *gin.Context) {
 		return
 	}
 
-	recurringExpense, err := h.service.UpdateRecurringExpense(uint(id), req)
+	recurringExpense, err := h.service.UpdateRecurringExpense(userID, uint(id), req)
 	if err != nil {
-		if err == services.ErrRecurringExpenseNotFound {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to recurring expense denied",
+				slog.Uint64("recurring_expense_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrRecurringExpenseNotFound) {
 			h.logger.Warn("recurring expense not found for update",
 				slog.Uint64("recurring_expense_id", id),
 			)
@@ -241,6 +235,11 @@ func (h *RecurringExpenseHandler) Delete(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid recurring expense id",
@@ -251,8 +250,16 @@ func (h *RecurringExpenseHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteRecurringExpense(uint(id)); err != nil {
-		if err == services.ErrRecurringExpenseNotFound {
+	if err := h.service.DeleteRecurringExpense(userID, uint(id)); err != nil {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to recurring expense denied",
+				slog.Uint64("recurring_expense_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrRecurringExpenseNotFound) {
 			h.logger.Warn("recurring expense not found for delete",
 				slog.Uint64("recurring_expense_id", id),
 			)
@@ -280,29 +287,8 @@ func (h *RecurringExpenseHandler) GetActive(c *gin.Context) {
 		slog.String("path", c.FullPath()),
 	)
 
-	userIDStr := c.Query("user_id")
-	if userIDStr == "" {
-		h.logger.Warn("missing user_id parameter")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "необходим параметр user_id"})
-		return
-	}
-
-	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid user_id parameter",
-			slog.String("raw_user_id", userIDStr),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный user_id"})
-		return
-	}
-	userID := uint(userIDUint)
-	if err != nil {
-		h.logger.Warn("invalid user_id parameter",
-			slog.String("raw_user_id", userIDStr),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный user_id"})
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
 		return
 	}
 
@@ -331,6 +317,11 @@ func (h *RecurringExpenseHandler) Activate(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid recurring expense id",
@@ -341,9 +332,17 @@ func (h *RecurringExpenseHandler) Activate(c *gin.Context) {
 		return
 	}
 
-	recurringExpense, err := h.service.ActivateRecurringExpense(uint(id))
+	recurringExpense, err := h.service.ActivateRecurringExpense(userID, uint(id))
 	if err != nil {
-		if err == services.ErrRecurringExpenseNotFound {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to recurring expense denied",
+				slog.Uint64("recurring_expense_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrRecurringExpenseNotFound) {
 			h.logger.Warn("recurring expense not found for activation",
 				slog.Uint64("recurring_expense_id", id),
 			)
@@ -372,6 +371,11 @@ func (h *RecurringExpenseHandler) Deactivate(c *gin.Context) {
 		slog.String("raw_id", c.Param("id")),
 	)
 
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		h.logger.Warn("invalid recurring expense id",
@@ -382,9 +386,17 @@ func (h *RecurringExpenseHandler) Deactivate(c *gin.Context) {
 		return
 	}
 
-	recurringExpense, err := h.service.DeactivateRecurringExpense(uint(id))
+	recurringExpense, err := h.service.DeactivateRecurringExpense(userID, uint(id))
 	if err != nil {
-		if err == services.ErrRecurringExpenseNotFound {
+		if errors.Is(err, services.ErrForbidden) {
+			h.logger.Warn("access to recurring expense denied",
+				slog.Uint64("recurring_expense_id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrRecurringExpenseNotFound) {
 			h.logger.Warn("recurring expense not found for deactivation",
 				slog.Uint64("recurring_expense_id", id),
 			)
@@ -1,52 +1,68 @@
 package handlers
 
 import (
-	"cashcontrol/internal/config"
-	"cashcontrol/internal/repository"
 	"cashcontrol/internal/services"
 	"log/slog"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-func RegisterRoutes(r *gin.Engine, db *gorm.DB, logger *slog.Logger, cfg *config.Config) {
-	// Инициализация репозиториев
-	userRepo := repository.NewUserRepository(db, logger)
-	categoryRepo := repository.NewCategoryRepository(db, logger)
-	expenseRepo := repository.NewExpenseRepository(db, logger)
-	budgetRepo := repository.NewBudgetRepository(db, logger)
-	recurringExpenseRepo := repository.NewRecurringExpenseRepository(db, logger)
-	_ = repository.NewActivityLogRepository(db, logger)
-	_ = repository.NewRecurringExpenseRepository(db, logger)
-
-	// Инициализация сервисов
-	userService := services.NewUserService(userRepo, logger)
-	categoryService := services.NewCategoryService(categoryRepo, logger)
-	expenseService := services.NewExpenseService(expenseRepo, categoryRepo, logger)
-	recurringExpenseService := services.NewRecurringExpenseService(recurringExpenseRepo, expenseRepo, logger)
+// RegisterRoutes регистрирует маршруты API поверх уже собранных сервисов
+func RegisterRoutes(r *gin.Engine, svc *services.Services, logger *slog.Logger) {
+	// Auth
+	authMiddleware := AuthMiddleware(svc.Auth, logger)
+	authHandler := NewAuthHandler(svc.Auth, logger)
+	authHandler.RegisterRoutes(r, authMiddleware)
+
+	// Все остальные маршруты доступны только с валидным JWT
+	protected := r.Group("", authMiddleware)
 
 	// Инициализация handlers и регистрация маршрутов
-	userHandler := NewUserHandler(userService, logger)
-	userHandler.RegisterRoutes(r)
+	userHandler := NewUserHandler(svc.User, logger)
+	userHandler.RegisterRoutes(protected)
 
-	categoryHandler := NewCategoryHandler(categoryService, logger)
-	categoryHandler.RegisterRoutes(r)
+	categoryHandler := NewCategoryHandler(svc.Category, logger)
+	categoryHandler.RegisterRoutes(protected)
 
-	expenseHandler := NewExpenseHandler(expenseService, logger)
-	expenseHandler.RegisterRoutes(r)
+	expenseHandler := NewExpenseHandler(svc.Expense, logger)
+	expenseHandler.RegisterRoutes(protected)
 
-	budgetService := services.NewBudgetService(budgetRepo, expenseRepo, logger)
-	budgetHandler := NewBudgetHandler(budgetService, logger)
-	budgetHandler.RegisterRoutes(r)
+	expenseImportHandler := NewExpenseImportHandler(svc.ExpenseImport, logger)
+	expenseImportHandler.RegisterRoutes(protected)
 
-	recurringExpenseHandler := NewRecurringExpenseHandler(recurringExpenseService, logger)
-	recurringExpenseHandler.RegisterRoutes(r)
+	categorizationRuleHandler := NewCategorizationRuleHandler(svc.Categorization, logger)
+	categorizationRuleHandler.RegisterRoutes(protected)
 
-	// Auth
-	authService := services.NewAuthService(userRepo, logger, cfg.JWTSecret)
-	authHandler := NewAuthHandler(authService, logger)
-	authHandler.RegisterRoutes(r)
+	tagHandler := NewTagHandler(svc.Tag, logger)
+	tagHandler.RegisterRoutes(protected)
+
+	incomeHandler := NewIncomeHandler(svc.Income, logger)
+	incomeHandler.RegisterRoutes(protected)
+
+	accountHandler := NewAccountHandler(svc.Account, logger)
+	accountHandler.RegisterRoutes(protected)
+
+	budgetHandler := NewBudgetHandler(svc.Budget, logger)
+	budgetHandler.RegisterRoutes(protected)
+
+	budgetAlertHandler := NewBudgetAlertHandler(svc.BudgetAlert, logger)
+	budgetAlertHandler.RegisterRoutes(protected)
+
+	recurringExpenseHandler := NewRecurringExpenseHandler(svc.RecurringExpense, logger)
+	recurringExpenseHandler.RegisterRoutes(protected)
+
+	exchangeRateHandler := NewExchangeRateHandler(svc.Currency, logger)
+	exchangeRateHandler.RegisterRoutes(protected)
+
+	statisticsHandler := NewStatisticsHandler(svc.Statistics, logger)
+	statisticsHandler.RegisterRoutes(protected)
+
+	cashFlowHandler := NewCashFlowHandler(svc.CashFlow, logger)
+	cashFlowHandler.RegisterRoutes(protected)
+
+	trashHandler := NewTrashHandler(svc.Trash, logger)
+	trashHandler.RegisterRoutes(protected)
 
-	// TODO: Добавить handlers для ActivityLog если необходимо
+	activityLogHandler := NewActivityLogHandler(svc.ActivityLog, logger)
+	activityLogHandler.RegisterRoutes(protected)
 }
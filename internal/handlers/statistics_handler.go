@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatisticsHandler struct {
+	service services.StatisticsService
+	logger  *slog.Logger
+}
+
+func NewStatisticsHandler(service services.StatisticsService, logger *slog.Logger) *StatisticsHandler {
+	return &StatisticsHandler{service: service, logger: logger}
+}
+
+func (h *StatisticsHandler) RegisterRoutes(r gin.IRouter) {
+	statistics := r.Group("/statistics")
+	{
+		statistics.GET("/summary", h.GetSummary)
+		statistics.GET("/periods", h.GetByPeriods)
+		statistics.GET("/distribution", h.GetDistribution)
+	}
+}
+
+func (h *StatisticsHandler) GetSummary(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	date := time.Now()
+	parsed, err := parseDateQuery(c, "date")
+	if err != nil {
+		h.logger.Warn("invalid date", slog.String("reason", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if parsed != nil {
+		date = *parsed
+	}
+
+	stats, err := h.service.GetPeriodStatistics(userID, statisticsPeriodQuery(c), date)
+	if err != nil {
+		h.respondError(c, userID, "failed to get period statistics", err)
+		return
+	}
+
+	h.logger.Info("period statistics retrieved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("period", string(stats.Period)),
+	)
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *StatisticsHandler) GetByPeriods(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	startDate, endDate, err := parseDateRangeQuery(c)
+	if err != nil {
+		h.logger.Warn("invalid date range", slog.String("reason", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := h.service.GetStatisticsByPeriods(userID, statisticsPeriodQuery(c), startDate, endDate)
+	if err != nil {
+		h.respondError(c, userID, "failed to get statistics by periods", err)
+		return
+	}
+
+	h.logger.Info("statistics by periods retrieved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(stats)),
+	)
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *StatisticsHandler) GetDistribution(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	startDate, endDate, err := parseDateRangeQuery(c)
+	if err != nil {
+		h.logger.Warn("invalid date range", slog.String("reason", err.Error()))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	distribution, err := h.service.GetDistribution(userID, startDate, endDate)
+	if err != nil {
+		h.respondError(c, userID, "failed to get expense distribution", err)
+		return
+	}
+
+	h.logger.Info("expense distribution retrieved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(distribution)),
+	)
+
+	c.JSON(http.StatusOK, distribution)
+}
+
+func (h *StatisticsHandler) respondError(c *gin.Context, userID uint, msg string, err error) {
+	if errors.Is(err, services.ErrInvalidStatisticsPeriod) || errors.Is(err, services.ErrInvalidDateRange) {
+		h.logger.Warn(msg,
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrMissingExchangeRate) {
+		h.logger.Warn(msg,
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Error(msg,
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("error", err.Error()),
+	)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// statisticsPeriodQuery читает параметр period, по умолчанию месяц
+func statisticsPeriodQuery(c *gin.Context) models.StatisticsPeriod {
+	return models.StatisticsPeriod(c.DefaultQuery("period", string(models.PeriodMonth)))
+}
+
+// parseDateQuery разбирает необязательный параметр даты в формате YYYY-MM-DD
+func parseDateQuery(c *gin.Context, key string) (*time.Time, error) {
+	v := c.Query(key)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный параметр %s, ожидается формат YYYY-MM-DD", key)
+	}
+	return &t, nil
+}
+
+// parseDateRangeQuery разбирает необязательные параметры start_date и end_date
+func parseDateRangeQuery(c *gin.Context) (*time.Time, *time.Time, error) {
+	startDate, err := parseDateQuery(c, "start_date")
+	if err != nil {
+		return nil, nil, err
+	}
+	endDate, err := parseDateQuery(c, "end_date")
+	if err != nil {
+		return nil, nil, err
+	}
+	return startDate, endDate, nil
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	service services.TagService
+	logger  *slog.Logger
+}
+
+func NewTagHandler(service services.TagService, logger *slog.Logger) *TagHandler {
+	return &TagHandler{service: service, logger: logger}
+}
+
+func (h *TagHandler) RegisterRoutes(r gin.IRouter) {
+	tags := r.Group("/tags")
+	{
+		tags.GET("", h.List)
+		tags.POST("", h.Create)
+		tags.GET("/:id", h.Get)
+		tags.PATCH("/:id", h.Update)
+		tags.DELETE("/:id", h.Delete)
+	}
+}
+
+func (h *TagHandler) List(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	tags, err := h.service.GetTagList(userID)
+	if err != nil {
+		h.logger.Error("failed to get tag list",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("tag list retrieved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(tags)),
+	)
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *TagHandler) Create(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var req models.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.service.CreateTag(userID, req)
+	if err != nil {
+		h.respondTagError(c, 0, userID, "failed to create tag", http.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Info("tag created",
+		slog.Uint64("tag_id", uint64(tag.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	c.JSON(http.StatusCreated, tag)
+}
+
+func (h *TagHandler) Get(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	tag, err := h.service.GetTagByID(userID, uint(id))
+	if err != nil {
+		h.respondTagError(c, id, userID, "failed to get tag", http.StatusInternalServerError, err)
+		return
+	}
+
+	h.logger.Info("tag retrieved",
+		slog.Uint64("tag_id", id),
+	)
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *TagHandler) Update(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid request body",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tag, err := h.service.UpdateTag(userID, uint(id), req)
+	if err != nil {
+		h.respondTagError(c, id, userID, "failed to update tag", http.StatusBadRequest, err)
+		return
+	}
+
+	h.logger.Info("tag updated",
+		slog.Uint64("tag_id", id),
+	)
+
+	c.JSON(http.StatusOK, tag)
+}
+
+func (h *TagHandler) Delete(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTag(userID, uint(id)); err != nil {
+		h.respondTagError(c, id, userID, "failed to delete tag", http.StatusInternalServerError, err)
+		return
+	}
+
+	h.logger.Info("tag deleted",
+		slog.Uint64("tag_id", id),
+	)
+
+	c.Status(http.StatusOK)
+}
+
+func (h *TagHandler) parseID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid id",
+			slog.String("raw_id", c.Param("id")),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+		return 0, false
+	}
+	return id, true
+}
+
+// respondTagError отвечает 403 или 404 для чужой или несуществующей метки, 409 для занятого названия,
+// иначе fallbackStatus
+func (h *TagHandler) respondTagError(c *gin.Context, id uint64, userID uint, msg string, fallbackStatus int, err error) {
+	switch {
+	case errors.Is(err, services.ErrForbidden):
+		h.logger.Warn("access to tag denied",
+			slog.Uint64("tag_id", id),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTagNotFound):
+		h.logger.Warn("tag not found",
+			slog.Uint64("tag_id", id),
+		)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTagNameTaken):
+		h.logger.Warn("tag name taken",
+			slog.Uint64("tag_id", id),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case fallbackStatus == http.StatusInternalServerError:
+		h.logger.Error(msg,
+			slog.Uint64("tag_id", id),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(fallbackStatus, gin.H{"error": err.Error()})
+	default:
+		h.logger.Warn(msg,
+			slog.Uint64("tag_id", id),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(fallbackStatus, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/services"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler struct {
+	service services.TrashService
+	logger  *slog.Logger
+}
+
+func NewTrashHandler(service services.TrashService, logger *slog.Logger) *TrashHandler {
+	return &TrashHandler{service: service, logger: logger}
+}
+
+func (h *TrashHandler) RegisterRoutes(r gin.IRouter) {
+	trash := r.Group("/trash")
+	{
+		trash.GET("", h.List)
+		trash.POST("/:type/:id/restore", h.Restore)
+	}
+}
+
+func (h *TrashHandler) List(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("raw_query", c.Request.URL.RawQuery),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	filter, err := h.parseTrashFilter(c)
+	if err != nil {
+		h.logger.Warn("failed to parse filter",
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.UserID = userID
+
+	items, err := h.service.GetTrash(filter)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTrashEntityType) {
+			h.logger.Warn("invalid trash entity type",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.Any("type", filter.EntityType),
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("failed to get trash",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.logger.Info("trash retrieved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(items)),
+	)
+
+	c.JSON(http.StatusOK, items)
+}
+
+// Restore возвращает запись из корзины вместе с удаленными категориями и счетами, на которые она ссылается
+func (h *TrashHandler) Restore(c *gin.Context) {
+	h.logger.Info("incoming request",
+		slog.String("method", c.Request.Method),
+		slog.String("path", c.FullPath()),
+		slog.String("type", c.Param("type")),
+		slog.String("raw_id", c.Param("id")),
+	)
+
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		h.logger.Warn("invalid id",
+			slog.String("raw_id", c.Param("id")),
+			slog.String("reason", err.Error()),
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+		return
+	}
+	entityType := c.Param("type")
+
+	result, err := h.service.Restore(userID, entityType, uint(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTrashEntityType):
+			h.logger.Warn("invalid trash entity type",
+				slog.String("type", entityType),
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTrashItemNotFound):
+			h.logger.Warn("trash item not found",
+				slog.String("type", entityType),
+				slog.Uint64("id", id),
+				slog.Uint64("user_id", uint64(userID)),
+			)
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTrashRestoreConflict):
+			h.logger.Warn("trash item restore conflict",
+				slog.String("type", entityType),
+				slog.Uint64("id", id),
+			)
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			h.logger.Error("failed to restore trash item",
+				slog.String("type", entityType),
+				slog.Uint64("id", id),
+				slog.String("error", err.Error()),
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	h.logger.Info("trash item restored",
+		slog.String("type", entityType),
+		slog.Uint64("id", id),
+		slog.Int("dependencies", len(result.Dependencies)),
+	)
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *TrashHandler) parseTrashFilter(c *gin.Context) (models.TrashFilter, error) {
+	var filter models.TrashFilter
+
+	if v := c.Query("type"); v != "" {
+		filter.EntityType = &v
+	}
+
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return filter, errors.New("invalid limit")
+		}
+		filter.Limit = &n
+	}
+
+	if v := c.Query("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return filter, errors.New("invalid offset")
+		}
+		filter.Offset = &n
+	}
+
+	return filter, nil
+}
@@ -1,11 +1,10 @@
 package handlers
 
 import (
-	"cashcontrol/internal/models"
 	"cashcontrol/internal/services"
+	"errors"
 	"log/slog"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,100 +18,36 @@ func NewUserHandler(service services.UserService, logger *slog.Logger) *UserHand
 	return &UserHandler{service: service, logger: logger}
 }
 
-func (h *UserHandler) RegisterRoutes(r *gin.Engine) {
-	users := r.Group("/users")
+// RegisterRoutes регистрирует маршруты профиля: пользователь видит и меняет только свою учетную запись из токена.
+// Новые пользователи создаются только регистрацией через /auth/register.
+func (h *UserHandler) RegisterRoutes(r gin.IRouter) {
+	me := r.Group("/users/me")
 	{
-		users.GET("", h.List)
-		users.POST("", h.Create)
-		users.GET("/:id", h.Get)
-		users.PATCH("/:id", h.Update)
-		users.DELETE("/:id", h.Delete)
+		me.GET("", h.Get)
+		me.PATCH("", h.Update)
+		me.DELETE("", h.Delete)
 	}
 }
 
-func (h *UserHandler) List(c *gin.Context) {
-	h.logger.Info("incoming request",
-		slog.String("method", c.Request.Method),
-		slog.String("path", c.FullPath()),
-	)
-
-	users, err := h.service.GetUserList()
-	if err != nil {
-		h.logger.Error("failed to get user list",
-			slog.String("error", err.Error()),
-		)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	h.logger.Info("user list retrieved",
-		slog.Int("count", len(users)),
-	)
-
-	c.JSON(http.StatusOK, users)
-}
-
-func (h *UserHandler) Create(c *gin.Context) {
-	h.logger.Info("incoming request",
-		slog.String("method", c.Request.Method),
-		slog.String("path", c.FullPath()),
-	)
-
-	var req models.RegisterRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn("invalid request body",
-			slog.String("error", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	user, err := h.service.CreateUser(req)
-	if err != nil {
-		h.logger.Warn("failed to create user",
-			slog.String("error", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	h.logger.Info("user created",
-		slog.Uint64("user_id", uint64(user.ID)),
-		slog.String("email", user.Email),
-	)
-
-	c.JSON(http.StatusCreated, user)
-}
-
 func (h *UserHandler) Get(c *gin.Context) {
 	h.logger.Info("incoming request",
 		slog.String("method", c.Request.Method),
 		slog.String("path", c.FullPath()),
-		slog.String("raw_id", c.Param("id")),
 	)
 
-	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid user id",
-			slog.String("raw_id", c.Param("id")),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
 		return
 	}
 
-	user, err := h.service.GetUserByID(uint(id))
+	user, err := h.service.GetUserByID(userID)
 	if err != nil {
-		h.logger.Error("failed to get user",
-			slog.Uint64("user_id", id),
-			slog.String("error", err.Error()),
-		)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.respondUserError(c, userID, "failed to get user", http.StatusInternalServerError, err)
 		return
 	}
 
 	h.logger.Info("user retrieved",
-		slog.Uint64("user_id", id),
+		slog.Uint64("user_id", uint64(userID)),
 	)
 
 	c.JSON(http.StatusOK, user)
@@ -122,22 +57,17 @@ func (h *UserHandler) Update(c *gin.Context) {
 	h.logger.Info("incoming request",
 		slog.String("method", c.Request.Method),
 		slog.String("path", c.FullPath()),
-		slog.String("raw_id", c.Param("id")),
 	)
 
-	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid user id",
-			slog.String("raw_id", c.Param("id")),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
 		return
 	}
 
 	var req struct {
-		Email    string `json:"email,omitempty"`
-		Username string `json:"username,omitempty"`
+		Email        string `json:"email,omitempty"`
+		Username     string `json:"username,omitempty"`
+		BaseCurrency string `json:"base_currency,omitempty"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.Warn("invalid request body",
@@ -147,18 +77,14 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.UpdateUser(uint(id), req.Email, req.Username)
+	user, err := h.service.UpdateUser(userID, req.Email, req.Username, req.BaseCurrency)
 	if err != nil {
-		h.logger.Warn("failed to update user",
-			slog.Uint64("user_id", id),
-			slog.String("error", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.respondUserError(c, userID, "failed to update user", http.StatusBadRequest, err)
 		return
 	}
 
 	h.logger.Info("user updated",
-		slog.Uint64("user_id", id),
+		slog.Uint64("user_id", uint64(userID)),
 	)
 
 	c.JSON(http.StatusOK, user)
@@ -168,32 +94,44 @@ func (h *UserHandler) Delete(c *gin.Context) {
 	h.logger.Info("incoming request",
 		slog.String("method", c.Request.Method),
 		slog.String("path", c.FullPath()),
-		slog.String("raw_id", c.Param("id")),
 	)
 
-	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
-	if err != nil {
-		h.logger.Warn("invalid user id",
-			slog.String("raw_id", c.Param("id")),
-			slog.String("reason", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор"})
+	userID, ok := requireUserID(c, h.logger)
+	if !ok {
 		return
 	}
 
-	err = h.service.DeleteUser(uint(id))
-	if err != nil {
-		h.logger.Warn("failed to delete user",
-			slog.Uint64("user_id", id),
-			slog.String("error", err.Error()),
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := h.service.DeleteUser(userID); err != nil {
+		h.respondUserError(c, userID, "failed to delete user", http.StatusInternalServerError, err)
 		return
 	}
 
 	h.logger.Info("user deleted",
-		slog.Uint64("user_id", id),
+		slog.Uint64("user_id", uint64(userID)),
 	)
 
 	c.JSON(http.StatusOK, gin.H{"message": "пользователь удален"})
 }
+
+// respondUserError отвечает 404, если пользователь из токена уже удален, иначе fallbackStatus
+func (h *UserHandler) respondUserError(c *gin.Context, userID uint, msg string, fallbackStatus int, err error) {
+	if errors.Is(err, services.ErrUserNotFound) {
+		h.logger.Warn("user not found",
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if fallbackStatus == http.StatusInternalServerError {
+		h.logger.Error(msg,
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+	} else {
+		h.logger.Warn(msg,
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+	}
+	c.JSON(fallbackStatus, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,208 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CSVOptions сопоставление колонок CSV выписки. Колонка задается названием из заголовка или номером с единицы.
+type CSVOptions struct {
+	Delimiter         string // Разделитель полей, по умолчанию определяется по первой строке
+	HasHeader         bool   // Первая строка содержит названия колонок
+	DateColumn        string // Колонка даты
+	AmountColumn      string // Колонка суммы
+	DescriptionColumn string // Колонка описания, необязательна
+	CurrencyColumn    string // Колонка валюты, необязательна
+}
+
+// Необязательные колонки с этими названиями используются, если они не заданы явно, но есть в заголовке
+const (
+	defaultDescriptionColumn = "description"
+	defaultCurrencyColumn    = "currency"
+)
+
+// csvColumns индексы колонок, -1 для отсутствующей необязательной колонки
+type csvColumns struct {
+	date, amount, description, currency int
+}
+
+func parseCSV(data string, opts Options, layouts []string) ([]Row, error) {
+	delimiter, err := csvDelimiter(opts.CSV.Delimiter, data)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var (
+		columns csvColumns
+		rows    []Row
+	)
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("выписка: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first {
+			var header []string
+			if opts.CSV.HasHeader {
+				header = record
+			}
+			if columns, err = resolveCSVColumns(opts.CSV, header); err != nil {
+				return nil, err
+			}
+			if opts.CSV.HasHeader {
+				continue
+			}
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		rows = append(rows, parseCSVRecord(line, record, columns, opts.DecimalSeparator, layouts))
+	}
+
+	return rows, nil
+}
+
+func parseCSVRecord(line int, record []string, columns csvColumns, decimalSeparator string, layouts []string) Row {
+	row := Row{Line: line}
+
+	field := func(index int) string {
+		if index < 0 || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+	if columns.date >= len(record) || columns.amount >= len(record) {
+		row.Err = fmt.Errorf("в строке только %d полей, колонки даты и суммы не найдены", len(record))
+		return row
+	}
+
+	row.Description = field(columns.description)
+	row.Currency = strings.ToUpper(field(columns.currency))
+
+	date, err := parseDate(field(columns.date), layouts)
+	if err != nil {
+		row.Err = err
+		return row
+	}
+	row.Date = date
+
+	amount, err := parseAmount(field(columns.amount), decimalSeparator)
+	if err != nil {
+		row.Err = err
+		return row
+	}
+	row.Amount = amount
+
+	return row
+}
+
+// resolveCSVColumns переводит сопоставление колонок в индексы; header равен nil для файла без заголовка
+func resolveCSVColumns(opts CSVOptions, header []string) (csvColumns, error) {
+	var (
+		columns csvColumns
+		err     error
+	)
+	if columns.date, err = csvColumnIndex("дата", opts.DateColumn, header); err != nil {
+		return columns, err
+	}
+	if columns.amount, err = csvColumnIndex("сумма", opts.AmountColumn, header); err != nil {
+		return columns, err
+	}
+	if columns.description, err = optionalCSVColumnIndex("описание", opts.DescriptionColumn, defaultDescriptionColumn, header); err != nil {
+		return columns, err
+	}
+	if columns.currency, err = optionalCSVColumnIndex("валюта", opts.CurrencyColumn, defaultCurrencyColumn, header); err != nil {
+		return columns, err
+	}
+	return columns, nil
+}
+
+// optionalCSVColumnIndex ищет необязательную колонку: явно заданная должна быть в файле,
+// а колонка по умолчанию используется, только если нашлась в заголовке
+func optionalCSVColumnIndex(title, column, defaultColumn string, header []string) (int, error) {
+	if strings.TrimSpace(column) == "" {
+		return headerIndex(header, defaultColumn), nil
+	}
+	return csvColumnIndex(title, column, header)
+}
+
+// csvColumnIndex ищет колонку по названию из заголовка или по номеру; колонка должна быть в файле
+func csvColumnIndex(title, column string, header []string) (int, error) {
+	column = strings.TrimSpace(column)
+	if column == "" {
+		return -1, fmt.Errorf("не задана колонка: %s", title)
+	}
+
+	if n, err := strconv.Atoi(column); err == nil {
+		if n < 1 {
+			return -1, fmt.Errorf("номер колонки %s должен начинаться с 1", title)
+		}
+		return n - 1, nil
+	}
+
+	if i := headerIndex(header, column); i >= 0 {
+		return i, nil
+	}
+	if header == nil {
+		return -1, fmt.Errorf("колонка %s %q задана названием, но в файле нет заголовка", title, column)
+	}
+	return -1, fmt.Errorf("колонка %s %q не найдена в заголовке", title, column)
+}
+
+// headerIndex возвращает индекс колонки с названием name без учета регистра или -1
+func headerIndex(header []string, name string) int {
+	for i, title := range header {
+		if strings.EqualFold(strings.TrimSpace(title), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// csvDelimiter возвращает заданный разделитель или выбирает по первой строке: точка с запятой, табуляция, запятая
+func csvDelimiter(delimiter, data string) (rune, error) {
+	switch delimiter {
+	case "":
+		first, _, _ := strings.Cut(data, "\n")
+		switch {
+		case strings.Contains(first, ";"):
+			return ';', nil
+		case strings.Contains(first, "\t"):
+			return '\t', nil
+		}
+		return ',', nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) || r == '"' || r == '\r' || r == '\n' {
+		return 0, fmt.Errorf("некорректный разделитель %q", delimiter)
+	}
+	return r, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,191 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"cashcontrol/internal/money"
+)
+
+func TestParseCSVColumnMapping(t *testing.T) {
+	day := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		data    string
+		opts    CSVOptions
+		want    Row
+		wantErr string
+	}{
+		{
+			name: "колонки по названию без учета регистра",
+			data: "Date;Amount;Description;Currency\n2024-03-05;150,50;Кофе;eur\n",
+			opts: CSVOptions{HasHeader: true, DateColumn: "date", AmountColumn: "AMOUNT", DescriptionColumn: "description", CurrencyColumn: "currency"},
+			want: Row{Line: 2, Date: day, Amount: money.FromFloat(150.5), Currency: "EUR", Description: "Кофе"},
+		},
+		{
+			name: "колонки по номеру без заголовка",
+			data: "Кофе,05.03.2024,99.90\n",
+			opts: CSVOptions{DateColumn: "2", AmountColumn: "3", DescriptionColumn: "1"},
+			want: Row{Line: 1, Date: day, Amount: money.FromFloat(99.9), Description: "Кофе"},
+		},
+		{
+			name: "необязательные колонки по умолчанию из заголовка",
+			data: "date,amount,description,currency\n2024-03-05,10,Такси,usd\n",
+			opts: CSVOptions{HasHeader: true, DateColumn: "date", AmountColumn: "amount"},
+			want: Row{Line: 2, Date: day, Amount: money.FromFloat(10), Currency: "USD", Description: "Такси"},
+		},
+		{
+			name: "необязательных колонок по умолчанию нет в заголовке",
+			data: "date,amount\n2024-03-05,10\n",
+			opts: CSVOptions{HasHeader: true, DateColumn: "date", AmountColumn: "amount"},
+			want: Row{Line: 2, Date: day, Amount: money.FromFloat(10)},
+		},
+		{
+			name: "табуляция и разряды через пробел",
+			data: "date\tamount\n2024-03-05\t1 234,56\n",
+			opts: CSVOptions{HasHeader: true, DateColumn: "date", AmountColumn: "amount"},
+			want: Row{Line: 2, Date: day, Amount: money.FromFloat(1234.56)},
+		},
+		{
+			name:    "явно заданной описательной колонки нет в заголовке",
+			data:    "date,amount\n2024-03-05,10\n",
+			opts:    CSVOptions{HasHeader: true, DateColumn: "date", AmountColumn: "amount", DescriptionColumn: "memo"},
+			wantErr: `колонка описание "memo" не найдена в заголовке`,
+		},
+		{
+			name:    "явно заданной колонки валюты нет в заголовке",
+			data:    "date,amount\n2024-03-05,10\n",
+			opts:    CSVOptions{HasHeader: true, DateColumn: "date", AmountColumn: "amount", CurrencyColumn: "ccy"},
+			wantErr: `колонка валюта "ccy" не найдена в заголовке`,
+		},
+		{
+			name:    "нет обязательной колонки",
+			data:    "date,sum\n2024-03-05,10\n",
+			opts:    CSVOptions{HasHeader: true, DateColumn: "date", AmountColumn: "amount"},
+			wantErr: `колонка сумма "amount" не найдена в заголовке`,
+		},
+		{
+			name:    "название колонки в файле без заголовка",
+			data:    "2024-03-05,10\n",
+			opts:    CSVOptions{DateColumn: "date", AmountColumn: "2"},
+			wantErr: "задана названием, но в файле нет заголовка",
+		},
+		{
+			name:    "не задана колонка суммы",
+			data:    "2024-03-05,10\n",
+			opts:    CSVOptions{DateColumn: "1"},
+			wantErr: "не задана колонка: сумма",
+		},
+		{
+			name:    "нулевой номер колонки",
+			data:    "2024-03-05,10\n",
+			opts:    CSVOptions{DateColumn: "0", AmountColumn: "2"},
+			wantErr: "должен начинаться с 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Parse(FormatCSV, strings.NewReader(tt.data), Options{CSV: tt.opts})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse: %v, want ошибку %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if len(rows) != 1 {
+				t.Fatalf("строк: %d, want 1", len(rows))
+			}
+			if rows[0] != tt.want {
+				t.Errorf("row = %+v, want %+v", rows[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCSVBadRows(t *testing.T) {
+	data := "date,amount,description\n" +
+		"2024-03-05,10,Кофе\n" +
+		"05/03/2024,20,Дата в неизвестном формате\n" +
+		"2024-03-06,двадцать,Сумма словами\n" +
+		"2024-03-07\n" +
+		"\n" +
+		"2024-03-08,10 руб,Сумма с текстом\n" +
+		"2024-03-09,30,Обед\n"
+
+	rows, err := Parse(FormatCSV, strings.NewReader(data), Options{
+		CSV: CSVOptions{HasHeader: true, DateColumn: "date", AmountColumn: "amount"},
+	})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	// Некорректная строка не прерывает разбор: остальные строки разбираются, пустые пропускаются
+	wantErr := map[int]string{
+		3: "некорректная дата",
+		4: "некорректная сумма",
+		5: "в строке только 1 полей",
+		7: "некорректная сумма",
+	}
+	wantLines := []int{2, 3, 4, 5, 7, 8}
+	if len(rows) != len(wantLines) {
+		t.Fatalf("строк: %d, want %d", len(rows), len(wantLines))
+	}
+	for i, row := range rows {
+		if row.Line != wantLines[i] {
+			t.Errorf("rows[%d].Line = %d, want %d", i, row.Line, wantLines[i])
+		}
+		want, bad := wantErr[row.Line]
+		switch {
+		case bad && (row.Err == nil || !strings.Contains(row.Err.Error(), want)):
+			t.Errorf("строка %d: ошибка %v, want %q", row.Line, row.Err, want)
+		case !bad && row.Err != nil:
+			t.Errorf("строка %d: неожиданная ошибка %v", row.Line, row.Err)
+		}
+	}
+}
+
+func TestParseCSVDecimalSeparator(t *testing.T) {
+	tests := []struct {
+		amount    string
+		separator string
+		want      money.Amount
+	}{
+		{"1,234.56", "", money.FromFloat(1234.56)},
+		{"1.234,56", "", money.FromFloat(1234.56)},
+		{"12,5", "", money.FromFloat(12.5)},
+		{"1.234", ",", money.FromFloat(1234)},
+		{"1,234", ".", money.FromFloat(1234)},
+		{"-15.00", "", money.FromFloat(-15)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount+"/"+tt.separator, func(t *testing.T) {
+			data := "date;amount\n2024-03-05;" + tt.amount + "\n"
+			rows, err := Parse(FormatCSV, strings.NewReader(data), Options{
+				DecimalSeparator: tt.separator,
+				CSV:              CSVOptions{HasHeader: true, DateColumn: "date", AmountColumn: "amount"},
+			})
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if rows[0].Err != nil || rows[0].Amount != tt.want {
+				t.Errorf("amount = %s (%v), want %s", rows[0].Amount, rows[0].Err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCSVEmptyStatement(t *testing.T) {
+	_, err := Parse(FormatCSV, strings.NewReader("date,amount\n"), Options{
+		CSV: CSVOptions{HasHeader: true, DateColumn: "date", AmountColumn: "amount"},
+	})
+	if err != ErrEmptyStatement {
+		t.Fatalf("Parse: %v, want ErrEmptyStatement", err)
+	}
+}
@@ -0,0 +1,167 @@
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cashcontrol/internal/money"
+)
+
+// Format формат файла банковской выписки
+type Format string
+
+const (
+	FormatCSV Format = "csv"
+	FormatOFX Format = "ofx"
+	FormatQIF Format = "qif"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("поддерживаются форматы csv, ofx и qif")
+	ErrEmptyStatement    = errors.New("в выписке нет операций")
+)
+
+// Row операция из выписки. Сумма хранится со знаком, как в файле; ошибка разбора строки не прерывает разбор файла.
+type Row struct {
+	Line        int          // Номер строки CSV или порядковый номер операции в OFX и QIF
+	Date        time.Time    // Дата операции в UTC без времени
+	Amount      money.Amount // Сумма со знаком из выписки
+	Currency    string       // Валюта операции, если указана в файле
+	Description string       // Описание или получатель платежа
+	Err         error        // Ошибка разбора строки
+}
+
+// Options настройки разбора выписки
+type Options struct {
+	DateFormat       string     // Формат даты вида DD.MM.YYYY, по умолчанию перебираются распространенные форматы
+	DecimalSeparator string     // Десятичный разделитель "." или ",", по умолчанию определяется по сумме
+	CSV              CSVOptions // Настройки CSV
+}
+
+// DetectFormat определяет формат выписки по расширению файла
+func DetectFormat(filename string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return FormatCSV, nil
+	case ".ofx", ".qfx":
+		return FormatOFX, nil
+	case ".qif":
+		return FormatQIF, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// Parse разбирает выписку в указанном формате. Ошибка возвращается только если файл нельзя разобрать целиком.
+func Parse(format Format, r io.Reader, opts Options) ([]Row, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("чтение выписки: %w", err)
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	layouts, err := dateLayouts(opts.DateFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []Row
+	switch format {
+	case FormatCSV:
+		rows, err = parseCSV(string(data), opts, layouts)
+	case FormatOFX:
+		rows = parseOFX(string(data), opts)
+	case FormatQIF:
+		rows = parseQIF(string(data), opts, layouts)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrEmptyStatement
+	}
+	return rows, nil
+}
+
+// defaultDateLayouts перебираются, если формат даты не задан
+var defaultDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2.1.2006",
+	"2.1.2006 15:04:05",
+	"2.1.2006 15:04",
+}
+
+// dateTokens переводит обозначения формата даты в раскладку time.Parse; длинные обозначения идут первыми
+var dateTokens = strings.NewReplacer(
+	"YYYY", "2006",
+	"YY", "06",
+	"MM", "01",
+	"DD", "02",
+	"HH", "15",
+	"mm", "04",
+	"ss", "05",
+)
+
+func dateLayouts(format string) ([]string, error) {
+	format = strings.TrimSpace(format)
+	if format == "" {
+		return defaultDateLayouts, nil
+	}
+	layout := dateTokens.Replace(format)
+	if !strings.Contains(layout, "2006") && !strings.Contains(layout, "06") {
+		return nil, fmt.Errorf("некорректный формат даты %q, ожидается например DD.MM.YYYY", format)
+	}
+	return []string{layout}, nil
+}
+
+func parseDate(value string, layouts []string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("некорректная дата %q", value)
+}
+
+// parseAmount разбирает сумму с учетом десятичного разделителя и разделителей разрядов (пробелы, апострофы)
+func parseAmount(value, decimalSeparator string) (money.Amount, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '\'':
+			return -1
+		}
+		return r
+	}, value)
+
+	switch decimalSeparator {
+	case ",":
+		cleaned = strings.ReplaceAll(cleaned, ".", "")
+		cleaned = strings.Replace(cleaned, ",", ".", 1)
+	case ".":
+		cleaned = strings.ReplaceAll(cleaned, ",", "")
+	default:
+		// При обоих разделителях десятичным считается последний
+		if strings.Contains(cleaned, ",") && strings.Contains(cleaned, ".") {
+			if strings.LastIndex(cleaned, ",") > strings.LastIndex(cleaned, ".") {
+				cleaned = strings.ReplaceAll(cleaned, ".", "")
+			} else {
+				cleaned = strings.ReplaceAll(cleaned, ",", "")
+			}
+		}
+	}
+
+	amount, err := money.Parse(cleaned)
+	if err != nil {
+		return 0, fmt.Errorf("некорректная сумма %q: %w", value, err)
+	}
+	return amount, nil
+}
@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// ofxTransaction поля операции STMTTRN, которые нужны для импорта
+type ofxTransaction struct {
+	posted, amount, name, memo, currency string
+}
+
+// parseOFX разбирает OFX 1.x (SGML, листовые теги без закрывающих) и OFX 2.x (XML).
+// Учитываются операции STMTTRN и валюта выписки CURDEF.
+func parseOFX(data string, opts Options) []Row {
+	var (
+		rows            []Row
+		statementCurDef string
+		current         *ofxTransaction
+	)
+
+	// Каждый фрагмент после '<' имеет вид "TAG>значение" или "/TAG>"
+	for _, chunk := range strings.Split(data, "<")[1:] {
+		tag, value, ok := strings.Cut(chunk, ">")
+		if !ok {
+			continue
+		}
+		tag = strings.ToUpper(strings.TrimSpace(tag))
+		value = strings.TrimSpace(html.UnescapeString(value))
+
+		switch tag {
+		case "STMTTRN":
+			current = &ofxTransaction{}
+		case "/STMTTRN":
+			if current != nil {
+				rows = append(rows, current.row(len(rows)+1, statementCurDef, opts.DecimalSeparator))
+				current = nil
+			}
+		case "CURDEF":
+			statementCurDef = strings.ToUpper(value)
+		}
+		if current == nil {
+			continue
+		}
+
+		switch tag {
+		case "DTPOSTED":
+			current.posted = value
+		case "TRNAMT":
+			current.amount = value
+		case "NAME", "PAYEE":
+			if current.name == "" {
+				current.name = value
+			}
+		case "MEMO":
+			current.memo = value
+		case "CURSYM":
+			current.currency = strings.ToUpper(value)
+		}
+	}
+
+	return rows
+}
+
+func (t *ofxTransaction) row(index int, statementCurrency, decimalSeparator string) Row {
+	row := Row{
+		Line:        index,
+		Description: t.name,
+		Currency:    t.currency,
+	}
+	if row.Description == "" {
+		row.Description = t.memo
+	}
+	if row.Currency == "" {
+		row.Currency = statementCurrency
+	}
+
+	// DTPOSTED имеет вид YYYYMMDD[HHMMSS[.XXX]][[смещение:зона]], для даты достаточно первых восьми символов
+	if len(t.posted) < 8 {
+		row.Err = fmt.Errorf("некорректная дата %q", t.posted)
+		return row
+	}
+	date, err := time.Parse("20060102", t.posted[:8])
+	if err != nil {
+		row.Err = fmt.Errorf("некорректная дата %q", t.posted)
+		return row
+	}
+	row.Date = date
+
+	amount, err := parseAmount(t.amount, decimalSeparator)
+	if err != nil {
+		row.Err = err
+		return row
+	}
+	row.Amount = amount
+
+	return row
+}
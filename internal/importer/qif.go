@@ -0,0 +1,86 @@
+package importer
+
+import "strings"
+
+// qifDateLayouts перебираются для QIF, если формат даты не задан: программы пишут и M/D/YYYY, и M/D'YY
+var qifDateLayouts = []string{
+	"1/2/2006",
+	"1/2/06",
+	"2.1.2006",
+	"2.1.06",
+	"2006-01-02",
+}
+
+// parseQIF разбирает QIF: записи из строк с однобуквенным кодом поля, завершаемые "^".
+// Используются поля D (дата), T или U (сумма), P (получатель) и M (комментарий); заголовки "!Type:" пропускаются.
+func parseQIF(data string, opts Options, layouts []string) []Row {
+	if opts.DateFormat == "" {
+		layouts = qifDateLayouts
+	}
+
+	var (
+		rows                      []Row
+		date, amount, payee, memo string
+		hasFields                 bool
+	)
+	flush := func() {
+		if hasFields {
+			rows = append(rows, qifRow(len(rows)+1, date, amount, payee, memo, opts.DecimalSeparator, layouts))
+		}
+		date, amount, payee, memo, hasFields = "", "", "", "", false
+	}
+
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "!") {
+			continue
+		}
+
+		value := strings.TrimSpace(line[1:])
+		switch line[0] {
+		case '^':
+			flush()
+			continue
+		case 'D':
+			date = value
+		case 'T':
+			amount = value
+		case 'U':
+			if amount == "" {
+				amount = value
+			}
+		case 'P':
+			payee = value
+		case 'M':
+			memo = value
+		}
+		hasFields = true
+	}
+	flush()
+
+	return rows
+}
+
+func qifRow(index int, date, amount, payee, memo, decimalSeparator string, layouts []string) Row {
+	row := Row{Line: index, Description: payee}
+	if row.Description == "" {
+		row.Description = memo
+	}
+
+	// Год после апострофа (1/31'24) и пробелы внутри даты встречаются в выгрузках Quicken
+	parsed, err := parseDate(strings.ReplaceAll(strings.ReplaceAll(date, "'", "/"), " ", ""), layouts)
+	if err != nil {
+		row.Err = err
+		return row
+	}
+	row.Date = parsed
+
+	value, err := parseAmount(amount, decimalSeparator)
+	if err != nil {
+		row.Err = err
+		return row
+	}
+	row.Amount = value
+
+	return row
+}
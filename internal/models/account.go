@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"cashcontrol/internal/money"
+
+	"gorm.io/gorm"
+)
+
+type AccountType string
+
+const (
+	AccountTypeCash    AccountType = "cash"    // Наличные
+	AccountTypeCard    AccountType = "card"    // Банковская карта
+	AccountTypeBank    AccountType = "bank"    // Банковский счет
+	AccountTypeSavings AccountType = "savings" // Накопительный счет
+)
+
+type Account struct {
+	gorm.Model
+	UserID         uint         `gorm:"not null;index" json:"user_id"`                       // Идентификатор пользователя владельца счета
+	Name           string       `gorm:"not null" json:"name"`                                // Название счета
+	Type           AccountType  `gorm:"not null" json:"type"`                                // Тип счета наличные карта банк накопления
+	Currency       string       `gorm:"type:char(3);not null;default:'RUB'" json:"currency"` // Валюта счета, в ней ведутся все операции
+	InitialBalance money.Amount `gorm:"not null;type:decimal(19,4)" json:"initial_balance"`  // Остаток на момент заведения счета
+	Balance        money.Amount `gorm:"not null;type:decimal(19,4)" json:"balance"`          // Текущий остаток с учетом всех операций
+
+	// Связи
+	User User `gorm:"foreignKey:UserID" json:"-"` // Пользователь владелец счета
+}
+
+type CreateAccountRequest struct {
+	Name           string       `json:"name" binding:"required"`                              // Название счета
+	Type           AccountType  `json:"type" binding:"required,oneof=cash card bank savings"` // Тип счета
+	Currency       string       `json:"currency" binding:"omitempty,len=3"`                   // Валюта счета, по умолчанию базовая валюта пользователя
+	InitialBalance money.Amount `json:"initial_balance"`                                      // Начальный остаток, может быть отрицательным для кредитной карты
+}
+
+type UpdateAccountRequest struct {
+	Name *string      `json:"name,omitempty"`                                                  // Новое название счета
+	Type *AccountType `json:"type,omitempty" binding:"omitempty,oneof=cash card bank savings"` // Новый тип счета
+}
+
+type Transfer struct {
+	gorm.Model
+	UserID        uint         `gorm:"not null;index" json:"user_id"`                // Идентификатор пользователя
+	FromAccountID uint         `gorm:"not null;index" json:"from_account_id"`        // Счет списания
+	ToAccountID   uint         `gorm:"not null;index" json:"to_account_id"`          // Счет зачисления
+	Amount        money.Amount `gorm:"not null;type:decimal(19,4)" json:"amount"`    // Сумма списания в валюте счета списания
+	ToAmount      money.Amount `gorm:"not null;type:decimal(19,4)" json:"to_amount"` // Сумма зачисления в валюте счета зачисления
+	Description   string       `json:"description"`                                  // Описание перевода
+	Date          time.Time    `gorm:"not null;index" json:"date"`                   // Дата перевода
+
+	// Связи
+	User        User    `gorm:"foreignKey:UserID" json:"-"`        // Пользователь владелец перевода
+	FromAccount Account `gorm:"foreignKey:FromAccountID" json:"-"` // Счет списания
+	ToAccount   Account `gorm:"foreignKey:ToAccountID" json:"-"`   // Счет зачисления
+}
+
+type CreateTransferRequest struct {
+	FromAccountID uint          `json:"from_account_id" binding:"required"` // Счет списания
+	ToAccountID   uint          `json:"to_account_id" binding:"required"`   // Счет зачисления
+	Amount        money.Amount  `json:"amount" binding:"required,gt=0"`     // Сумма списания должна быть больше нуля
+	ToAmount      *money.Amount `json:"to_amount,omitempty"`                // Сумма зачисления для счетов в разных валютах, по умолчанию пересчет по курсу
+	Description   string        `json:"description"`                        // Описание перевода
+	Date          time.Time     `json:"date" binding:"required"`            // Дата перевода
+}
+
+type TransferFilter struct {
+	UserID    uint       // Идентификатор пользователя для фильтрации
+	AccountID *uint      // Счет списания или зачисления для фильтрации
+	StartDate *time.Time // Начальная дата периода для фильтрации
+	EndDate   *time.Time // Конечная дата периода для фильтрации
+	Limit     *int       // количество записей
+	Offset    *int       // смещение
+}
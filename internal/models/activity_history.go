@@ -9,17 +9,48 @@ import (
 type ActivityType string
 
 const (
-	ActivityTypeExpenseCreated   ActivityType = "expense_created"
-	ActivityTypeExpenseUpdated   ActivityType = "expense_updated"
-	ActivityTypeExpenseDeleted   ActivityType = "expense_deleted"
-	ActivityTypeCategoryCreated  ActivityType = "category_created"
-	ActivityTypeCategoryUpdated  ActivityType = "category_updated"
-	ActivityTypeCategoryDeleted  ActivityType = "category_deleted"
-	ActivityTypeBudgetCreated    ActivityType = "budget_created"
-	ActivityTypeBudgetUpdated    ActivityType = "budget_updated"
-	ActivityTypeRecurringCreated ActivityType = "recurring_created"
-	ActivityTypeRecurringUpdated ActivityType = "recurring_updated"
-	ActivityTypeRecurringDeleted ActivityType = "recurring_deleted"
+	ActivityTypeExpenseCreated            ActivityType = "expense_created"
+	ActivityTypeExpenseUpdated            ActivityType = "expense_updated"
+	ActivityTypeExpenseDeleted            ActivityType = "expense_deleted"
+	ActivityTypeExpensesImported          ActivityType = "expenses_imported"
+	ActivityTypeCategoryCreated           ActivityType = "category_created"
+	ActivityTypeCategoryUpdated           ActivityType = "category_updated"
+	ActivityTypeCategoryDeleted           ActivityType = "category_deleted"
+	ActivityTypeCategoriesMerged          ActivityType = "categories_merged"
+	ActivityTypeBudgetCreated             ActivityType = "budget_created"
+	ActivityTypeBudgetUpdated             ActivityType = "budget_updated"
+	ActivityTypeBudgetDeleted             ActivityType = "budget_deleted"
+	ActivityTypeRecurringCreated          ActivityType = "recurring_created"
+	ActivityTypeRecurringUpdated          ActivityType = "recurring_updated"
+	ActivityTypeRecurringDeleted          ActivityType = "recurring_deleted"
+	ActivityTypeIncomeCreated             ActivityType = "income_created"
+	ActivityTypeIncomeUpdated             ActivityType = "income_updated"
+	ActivityTypeIncomeDeleted             ActivityType = "income_deleted"
+	ActivityTypeAccountCreated            ActivityType = "account_created"
+	ActivityTypeAccountUpdated            ActivityType = "account_updated"
+	ActivityTypeAccountDeleted            ActivityType = "account_deleted"
+	ActivityTypeTransferCreated           ActivityType = "transfer_created"
+	ActivityTypeTransferDeleted           ActivityType = "transfer_deleted"
+	ActivityTypeCategorizationRuleCreated ActivityType = "categorization_rule_created"
+	ActivityTypeCategorizationRuleUpdated ActivityType = "categorization_rule_updated"
+	ActivityTypeCategorizationRuleDeleted ActivityType = "categorization_rule_deleted"
+	ActivityTypeExpensesRecategorized     ActivityType = "expenses_recategorized"
+	ActivityTypeEntityRestored            ActivityType = "entity_restored"
+	ActivityTypeTagCreated                ActivityType = "tag_created"
+	ActivityTypeTagUpdated                ActivityType = "tag_updated"
+	ActivityTypeTagDeleted                ActivityType = "tag_deleted"
+)
+
+const (
+	EntityTypeExpense            = "expense"
+	EntityTypeCategory           = "category"
+	EntityTypeBudget             = "budget"
+	EntityTypeRecurringExpense   = "recurring_expense"
+	EntityTypeIncome             = "income"
+	EntityTypeAccount            = "account"
+	EntityTypeTransfer           = "transfer"
+	EntityTypeCategorizationRule = "categorization_rule"
+	EntityTypeTag                = "tag"
 )
 
 type ActivityHistory struct {
@@ -50,6 +81,4 @@ type ActivityFilter struct {
 	EntityType   *string       // Тип сущности для фильтрации
 	StartDate    *time.Time    // Начальная дата периода для фильтрации
 	EndDate      *time.Time    // Конечная дата периода для фильтрации
-	Limit        *int          // количество записей
-	Offset       *int          // смещение
 }
@@ -1,37 +1,71 @@
 package models
 
 import (
+	"cashcontrol/internal/money"
+
 	"gorm.io/gorm"
 )
 
+type RolloverMode string
+
+const (
+	RolloverNone    RolloverMode = "none"    // Остаток не переносится
+	RolloverSurplus RolloverMode = "surplus" // Переносится только неизрасходованный остаток
+	RolloverDeficit RolloverMode = "deficit" // Переносится только перерасход
+	RolloverBoth    RolloverMode = "both"    // Переносится и остаток, и перерасход
+)
+
 type Budget struct {
 	gorm.Model
-	UserID uint    `gorm:"not null;index" json:"user_id"`                          // Идентификатор пользователя
-	Amount float64 `gorm:"not null;type:decimal(10,2)" json:"amount"`              // Сумма месячного бюджета
-	Month  int     `gorm:"not null;check:month >= 1 AND month <= 12" json:"month"` // Номер месяца от 1 до 12
-	Year   int     `gorm:"not null" json:"year"`                                   // Год бюджета
+	UserID     uint         `gorm:"not null;index" json:"user_id"`                          // Идентификатор пользователя
+	CategoryID *uint        `gorm:"index" json:"category_id"`                               // Категория бюджета, пусто для общего бюджета на месяц
+	Amount     money.Amount `gorm:"not null;type:decimal(19,4)" json:"amount"`              // Сумма месячного бюджета
+	Currency   string       `gorm:"type:char(3);not null;default:'RUB'" json:"currency"`    // Валюта бюджета, в нее пересчитываются расходы
+	Month      int          `gorm:"not null;check:month >= 1 AND month <= 12" json:"month"` // Номер месяца от 1 до 12
+	Year       int          `gorm:"not null" json:"year"`                                   // Год бюджета
+
+	RolloverMode RolloverMode `gorm:"not null;default:'none'" json:"rollover_mode"` // Что переносится из этого месяца в следующий
 
 	// Связи
 	User User `gorm:"foreignKey:UserID" json:"-"` // Пользователь владелец бюджета
 }
 
 type CreateBudgetRequest struct {
-	Amount float64 `json:"amount" binding:"required,gt=0"`        // Сумма бюджета должна быть больше нуля
-	Month  int     `json:"month" binding:"required,min=1,max=12"` // Номер месяца от 1 до 12
-	Year   int     `json:"year" binding:"required"`               // Год бюджета
+	CategoryID *uint        `json:"category_id"`                           // Категория бюджета, не указывается для общего бюджета
+	Amount     money.Amount `json:"amount" binding:"required,gt=0"`        // Сумма бюджета должна быть больше нуля
+	Currency   string       `json:"currency" binding:"omitempty,len=3"`    // Валюта бюджета, по умолчанию базовая валюта пользователя
+	Month      int          `json:"month" binding:"required,min=1,max=12"` // Номер месяца от 1 до 12
+	Year       int          `json:"year" binding:"required"`               // Год бюджета
+
+	RolloverMode RolloverMode `json:"rollover_mode" binding:"omitempty,oneof=none surplus deficit both"` // Режим переноса остатка, по умолчанию none
 }
 
 type UpdateBudgetRequest struct {
-	Amount *float64 `json:"amount,omitempty"` // Новая сумма бюджета
-	Month  *int     `json:"month,omitempty"`  // Новый номер месяца
-	Year   *int     `json:"year,omitempty"`   // Новый год бюджета
+	Amount       *money.Amount `json:"amount,omitempty"`        // Новая сумма бюджета
+	Month        *int          `json:"month,omitempty"`         // Новый номер месяца
+	Year         *int          `json:"year,omitempty"`          // Новый год бюджета
+	RolloverMode *RolloverMode `json:"rollover_mode,omitempty"` // Новый режим переноса остатка
 }
 
 type BudgetStatus struct {
-	Budget      *Budget `json:"budget"`        // Информация о бюджете
-	Spent       float64 `json:"spent"`         // Потраченная сумма за период
-	Remaining   float64 `json:"remaining"`     // Оставшаяся сумма бюджета
-	Percentage  float64 `json:"percentage"`    // Процент использования бюджета
-	IsExceeded  bool    `json:"is_exceeded"`   // Флаг превышения бюджета
-	IsNearLimit bool    `json:"is_near_limit"` // Флаг приближения к лимиту бюджета
+	Budget         *Budget      `json:"budget"`          // Информация о бюджете
+	CarriedOver    money.Amount `json:"carried_over"`    // Перенос из предыдущего месяца, отрицательный при перерасходе
+	EffectiveLimit money.Amount `json:"effective_limit"` // Лимит с учетом переноса
+	Spent          money.Amount `json:"spent"`           // Потраченная сумма за период
+	Remaining      money.Amount `json:"remaining"`       // Оставшаяся сумма, отрицательная при превышении лимита
+	Percentage     float64      `json:"percentage"`      // Процент использования лимита
+	IsExceeded     bool         `json:"is_exceeded"`     // Флаг превышения бюджета
+	IsNearLimit    bool         `json:"is_near_limit"`   // Флаг приближения к лимиту бюджета
+}
+
+type MonthlyBudgetStatus struct {
+	Month      int            `json:"month"`      // Номер месяца
+	Year       int            `json:"year"`       // Год
+	Overall    *BudgetStatus  `json:"overall"`    // Статус общего бюджета, пусто если он не задан
+	Categories []BudgetStatus `json:"categories"` // Статусы бюджетов по категориям
+
+	Currency       string       `json:"currency"`        // Базовая валюта доходов и доступных средств
+	Income         money.Amount `json:"income"`          // Доходы за месяц в базовой валюте
+	Expenses       money.Amount `json:"expenses"`        // Все расходы за месяц в базовой валюте
+	AvailableFunds money.Amount `json:"available_funds"` // Доходы минус расходы за месяц, отрицательные при дефиците
 }
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"cashcontrol/internal/money"
+
+	"gorm.io/gorm"
+)
+
+// DefaultAlertThresholds пороги уведомлений в процентах, если пользователь не настроил свои
+var DefaultAlertThresholds = []int{80, 100}
+
+type AlertSettings struct {
+	gorm.Model
+	UserID     uint   `gorm:"not null;uniqueIndex" json:"user_id"`                   // Идентификатор пользователя
+	Thresholds []int  `gorm:"serializer:json;type:jsonb;not null" json:"thresholds"` // Пороги использования бюджета в процентах
+	WebhookURL string `json:"webhook_url"`                                           // Адрес webhook для уведомлений
+	Email      string `json:"email"`                                                 // Адрес для уведомлений по почте
+	IsEnabled  bool   `gorm:"not null" json:"is_enabled"`                            // Флаг включения уведомлений
+
+	// Связи
+	User User `gorm:"foreignKey:UserID" json:"-"` // Пользователь владелец настроек
+}
+
+type BudgetAlert struct {
+	gorm.Model
+	UserID      uint         `gorm:"not null;index" json:"user_id"`                                    // Идентификатор пользователя
+	BudgetID    uint         `gorm:"not null;uniqueIndex:idx_budget_alert_threshold" json:"budget_id"` // Бюджет, по которому сработало уведомление
+	Threshold   int          `gorm:"not null;uniqueIndex:idx_budget_alert_threshold" json:"threshold"` // Достигнутый порог в процентах
+	Percentage  float64      `gorm:"not null" json:"percentage"`                                       // Фактический процент использования лимита
+	Spent       money.Amount `gorm:"not null;type:decimal(19,4)" json:"spent"`                         // Потраченная сумма на момент срабатывания
+	LimitAmount money.Amount `gorm:"not null;type:decimal(19,4)" json:"limit_amount"`                  // Лимит с учетом переноса на момент срабатывания
+	Month       int          `gorm:"not null" json:"month"`                                            // Месяц бюджета
+	Year        int          `gorm:"not null" json:"year"`                                             // Год бюджета
+	TriggeredAt time.Time    `gorm:"not null" json:"triggered_at"`                                     // Время срабатывания
+
+	// Связи
+	User   User   `gorm:"foreignKey:UserID" json:"-"`   // Пользователь владелец бюджета
+	Budget Budget `gorm:"foreignKey:BudgetID" json:"-"` // Бюджет уведомления
+}
+
+type UpdateAlertSettingsRequest struct {
+	Thresholds []int  `json:"thresholds" binding:"required,min=1,max=10,dive,gt=0,lte=1000"` // Пороги в процентах
+	WebhookURL string `json:"webhook_url" binding:"omitempty,url"`                           // Адрес https webhook во внешней сети, пусто чтобы отключить
+	Email      string `json:"email" binding:"omitempty,email"`                               // Адрес почты, пусто чтобы отключить
+	IsEnabled  *bool  `json:"is_enabled"`                                                    // Флаг включения уведомлений
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"cashcontrol/internal/money"
+
+	"gorm.io/gorm"
+)
+
+// CategorizationRule правило автоматической категоризации расходов. Правило срабатывает, когда выполнены
+// все заданные условия; правила проверяются по возрастанию приоритета, при равном приоритете — по порядку создания.
+type CategorizationRule struct {
+	gorm.Model
+	UserID              uint          `gorm:"not null;index" json:"user_id"`                  // Идентификатор пользователя владельца правила
+	CategoryID          uint          `gorm:"not null;index" json:"category_id"`              // Категория, которая назначается расходу
+	Name                string        `json:"name"`                                           // Название правила
+	Priority            int           `gorm:"not null" json:"priority"`                       // Приоритет, меньшее значение проверяется раньше
+	DescriptionContains string        `json:"description_contains,omitempty"`                 // Подстрока описания без учета регистра
+	DescriptionRegex    string        `json:"description_regex,omitempty"`                    // Регулярное выражение для описания
+	MinAmount           *money.Amount `gorm:"type:decimal(19,4)" json:"min_amount,omitempty"` // Минимальная сумма расхода включительно
+	MaxAmount           *money.Amount `gorm:"type:decimal(19,4)" json:"max_amount,omitempty"` // Максимальная сумма расхода включительно
+	AccountID           *uint         `gorm:"index" json:"account_id,omitempty"`              // Счет оплаты расхода
+	IsActive            bool          `gorm:"not null" json:"is_active"`                      // Участвует ли правило в категоризации
+
+	// Связи
+	User     User     `gorm:"foreignKey:UserID" json:"-"`     // Пользователь владелец правила
+	Category Category `gorm:"foreignKey:CategoryID" json:"-"` // Назначаемая категория
+}
+
+type CreateCategorizationRuleRequest struct {
+	CategoryID          uint          `json:"category_id" binding:"required"` // Назначаемая категория расходов
+	Name                string        `json:"name"`                           // Название правила
+	Priority            *int          `json:"priority"`                       // Приоритет, по умолчанию 100
+	DescriptionContains string        `json:"description_contains"`           // Подстрока описания
+	DescriptionRegex    string        `json:"description_regex"`              // Регулярное выражение для описания
+	MinAmount           *money.Amount `json:"min_amount"`                     // Минимальная сумма
+	MaxAmount           *money.Amount `json:"max_amount"`                     // Максимальная сумма
+	AccountID           *uint         `json:"account_id"`                     // Счет оплаты
+	IsActive            *bool         `json:"is_active"`                      // Активно ли правило, по умолчанию true
+}
+
+type UpdateCategorizationRuleRequest struct {
+	CategoryID          *uint         `json:"category_id,omitempty"`          // Новая категория
+	Name                *string       `json:"name,omitempty"`                 // Новое название
+	Priority            *int          `json:"priority,omitempty"`             // Новый приоритет
+	DescriptionContains *string       `json:"description_contains,omitempty"` // Новая подстрока, пустая строка снимает условие
+	DescriptionRegex    *string       `json:"description_regex,omitempty"`    // Новое выражение, пустая строка снимает условие
+	MinAmount           *money.Amount `json:"min_amount,omitempty"`           // Новая минимальная сумма, 0 снимает условие
+	MaxAmount           *money.Amount `json:"max_amount,omitempty"`           // Новая максимальная сумма, 0 снимает условие
+	AccountID           *uint         `json:"account_id,omitempty"`           // Новый счет, 0 снимает условие
+	IsActive            *bool         `json:"is_active,omitempty"`            // Активно ли правило
+}
+
+// ApplyCategorizationRulesRequest выборка расходов для повторного применения правил
+type ApplyCategorizationRulesRequest struct {
+	CategoryID *uint      `json:"category_id"` // Только расходы из этой категории
+	AccountID  *uint      `json:"account_id"`  // Только расходы с этого счета
+	StartDate  *time.Time `json:"start_date"`  // Начало периода
+	EndDate    *time.Time `json:"end_date"`    // Конец периода включительно
+	DryRun     bool       `json:"dry_run"`     // Только показать изменения, не сохраняя их
+}
+
+// CategorizationChange изменение категории расхода по правилу
+type CategorizationChange struct {
+	ExpenseID      uint         `json:"expense_id"`       // Идентификатор расхода
+	Description    string       `json:"description"`      // Описание расхода
+	Amount         money.Amount `json:"amount"`           // Сумма расхода
+	Currency       string       `json:"currency"`         // Валюта расхода
+	FromCategoryID uint         `json:"from_category_id"` // Прежняя категория
+	ToCategoryID   uint         `json:"to_category_id"`   // Новая категория
+	RuleID         uint         `json:"rule_id"`          // Сработавшее правило
+}
+
+// CategorizationResult итог повторного применения правил
+type CategorizationResult struct {
+	Checked   int                    `json:"checked"`   // Количество проверенных расходов
+	Matched   int                    `json:"matched"`   // Количество расходов, для которых сработало правило
+	Changed   int                    `json:"changed"`   // Количество расходов, у которых изменилась категория
+	DryRun    bool                   `json:"dry_run"`   // Изменения не сохранены
+	Changes   []CategorizationChange `json:"changes"`   // Список изменений, не больше 500
+	Truncated bool                   `json:"truncated"` // Изменений больше, чем перечислено в changes
+}
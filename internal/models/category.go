@@ -4,27 +4,93 @@ import (
 	"gorm.io/gorm"
 )
 
+type CategoryType string
+
+// CategoryDeleteStrategy что делать с записями, которые ссылаются на удаляемую категорию
+type CategoryDeleteStrategy string
+
+const (
+	CategoryTypeExpense CategoryType = "expense" // Категория расходов
+	CategoryTypeIncome  CategoryType = "income"  // Категория доходов
+)
+
+const (
+	CategoryDeleteRefuse   CategoryDeleteStrategy = "refuse"   // Отказать, если на категорию есть ссылки
+	CategoryDeleteReassign CategoryDeleteStrategy = "reassign" // Перенести записи в другую категорию
+)
+
 type Category struct {
 	gorm.Model
-	UserID    uint   `gorm:"not null;index" json:"user_id"`   // Идентификатор пользователя владельца категории
-	Name      string `gorm:"not null" json:"name"`            // Название категории
-	Color     string `gorm:"default:'#3B82F6'" json:"color"`  // Цвет категории
-	Icon      string `json:"icon"`                            // Иконка категории
-	IsDefault bool   `gorm:"default:false" json:"is_default"` // Флаг системной категории по умолчанию
+	UserID    uint         `gorm:"not null;index" json:"user_id"`                // Идентификатор пользователя владельца категории
+	ParentID  *uint        `gorm:"index" json:"parent_id"`                       // Родительская категория, nil для корневой
+	Name      string       `gorm:"not null" json:"name"`                         // Название категории
+	Type      CategoryType `gorm:"not null;default:'expense';index" json:"type"` // Тип категории расходы или доходы
+	Color     string       `gorm:"default:'#3B82F6'" json:"color"`               // Цвет категории
+	Icon      string       `json:"icon"`                                         // Иконка категории
+	IsDefault bool         `gorm:"default:false" json:"is_default"`              // Флаг системной категории по умолчанию
 
 	// Связи
 	User     User      `gorm:"foreignKey:UserID" json:"-"`     // Пользователь владелец категории
+	Parent   *Category `gorm:"foreignKey:ParentID" json:"-"`   // Родительская категория
 	Expenses []Expense `gorm:"foreignKey:CategoryID" json:"-"` // Все расходы в этой категории
+	Incomes  []Income  `gorm:"foreignKey:CategoryID" json:"-"` // Все доходы в этой категории
+}
+
+// CategoryTreeNode категория с вложенными подкатегориями
+type CategoryTreeNode struct {
+	Category
+	Children []CategoryTreeNode `json:"children"` // Подкатегории
+}
+
+// DefaultCategory категория из набора, создаваемого новому пользователю при регистрации
+type DefaultCategory struct {
+	Name     string            `json:"name"`               // Название категории
+	Type     CategoryType      `json:"type"`               // Тип категории, по умолчанию expense
+	Color    string            `json:"color"`              // Цвет категории
+	Icon     string            `json:"icon"`               // Иконка категории
+	Children []DefaultCategory `json:"children,omitempty"` // Подкатегории того же типа
 }
 
 type CreateCategoryRequest struct {
-	Name  string `json:"name" binding:"required"` // Название новой категории
-	Color string `json:"color"`                   // Цвет категории
-	Icon  string `json:"icon"`                    // Иконка категории
+	Name     string       `json:"name" binding:"required"`                       // Название новой категории
+	Type     CategoryType `json:"type" binding:"omitempty,oneof=expense income"` // Тип категории, по умолчанию expense
+	ParentID *uint        `json:"parent_id"`                                     // Родительская категория того же типа
+	Color    string       `json:"color"`                                         // Цвет категории
+	Icon     string       `json:"icon"`                                          // Иконка категории
 }
 
 type UpdateCategoryRequest struct {
-	Name  *string `json:"name,omitempty"`  // Новое название категории
-	Color *string `json:"color,omitempty"` // Новый цвет категории
-	Icon  *string `json:"icon,omitempty"`  // Новая иконка категории
+	Name     *string `json:"name,omitempty"`      // Новое название категории
+	ParentID *uint   `json:"parent_id,omitempty"` // Новая родительская категория, 0 делает категорию корневой
+	Color    *string `json:"color,omitempty"`     // Новый цвет категории
+	Icon     *string `json:"icon,omitempty"`      // Новая иконка категории
+}
+
+// DeleteCategoryRequest параметры удаления категории из строки запроса
+type DeleteCategoryRequest struct {
+	Strategy CategoryDeleteStrategy `form:"strategy" binding:"omitempty,oneof=refuse reassign"` // Стратегия удаления, по умолчанию refuse
+	TargetID uint                   `form:"target_id"`                                          // Категория, в которую переносятся записи при reassign
+}
+
+type MergeCategoriesRequest struct {
+	SourceIDs []uint `json:"source_ids" binding:"required,min=1"` // Объединяемые категории, удаляются после переноса
+	TargetID  uint   `json:"target_id" binding:"required"`        // Категория, в которую переносятся записи
+}
+
+// CategoryUsage количество записей, ссылающихся на категорию
+type CategoryUsage struct {
+	Expenses            int64 `json:"expenses"`             // Расходы
+	RecurringExpenses   int64 `json:"recurring_expenses"`   // Регулярные расходы
+	Incomes             int64 `json:"incomes"`              // Доходы
+	Budgets             int64 `json:"budgets"`              // Бюджеты категории
+	CategorizationRules int64 `json:"categorization_rules"` // Правила категоризации
+	Subcategories       int64 `json:"subcategories"`        // Подкатегории
+}
+
+// CategoryMergeResult итог переноса записей при объединении категорий или удалении с переносом
+type CategoryMergeResult struct {
+	TargetID           uint          `json:"target_id,omitempty"`  // Категория, в которую перенесены записи
+	DeletedCategoryIDs []uint        `json:"deleted_category_ids"` // Удаленные категории
+	Moved              CategoryUsage `json:"moved"`                // Количество перенесенных записей без учета корзины
+	BudgetsDeleted     int64         `json:"budgets_deleted"`      // Бюджеты, удаленные из-за бюджета целевой категории на тот же месяц
 }
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"cashcontrol/internal/money"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultCurrency базовая валюта пользователя, если она не указана при регистрации
+const DefaultCurrency = "RUB"
+
+type ExchangeRateSource string
+
+const (
+	RateSourceManual ExchangeRateSource = "manual" // Курс добавлен пользователем вручную
+	RateSourceFile   ExchangeRateSource = "file"   // Курс импортирован из файла
+)
+
+type ExchangeRate struct {
+	gorm.Model
+	UserID       *uint              `gorm:"index" json:"user_id"`                       // Владелец курса, пусто для общих курсов
+	FromCurrency string             `gorm:"type:char(3);not null" json:"from_currency"` // Исходная валюта
+	ToCurrency   string             `gorm:"type:char(3);not null" json:"to_currency"`   // Целевая валюта
+	Rate         money.Rate         `gorm:"not null;type:decimal(18,8)" json:"rate"`    // Стоимость единицы исходной валюты в целевой
+	Date         time.Time          `gorm:"type:date;not null;index" json:"date"`       // Дата, с которой действует курс
+	Source       ExchangeRateSource `gorm:"not null;default:'manual'" json:"source"`    // Источник курса
+
+	// Связи
+	User *User `gorm:"foreignKey:UserID" json:"-"` // Пользователь владелец курса
+}
+
+type CreateExchangeRateRequest struct {
+	FromCurrency string     `json:"from_currency" binding:"required,len=3"` // Исходная валюта
+	ToCurrency   string     `json:"to_currency" binding:"required,len=3"`   // Целевая валюта
+	Rate         money.Rate `json:"rate" binding:"required,gt=0"`           // Курс строкой, например "92.5"; должен быть больше нуля
+	Date         time.Time  `json:"date" binding:"required"`                // Дата, с которой действует курс
+}
+
+type ExchangeRateFilter struct {
+	UserID       uint   // Пользователь, чьи курсы возвращаются вместе с общими
+	FromCurrency string // Исходная валюта, пусто для любой
+	ToCurrency   string // Целевая валюта, пусто для любой
+}
+
+type ExchangeRateImportResult struct {
+	Imported int `json:"imported"` // Количество добавленных или обновленных курсов
+}
@@ -3,51 +3,95 @@ package models
 import (
 	"time"
 
+	"cashcontrol/internal/money"
+
 	"gorm.io/gorm"
 )
 
 type Expense struct {
 	gorm.Model
 
-	UserID      uint      `gorm:"not null;index" json:"user_id"`             // Идентификатор пользователя
-	CategoryID  uint      `gorm:"not null;index" json:"category_id"`         // Идентификатор категории расхода
-	Amount      float64   `gorm:"not null;type:decimal(10,2)" json:"amount"` // Сумма расхода
-	Description string    `json:"description"`                               // Описание расхода
-	Date        time.Time `gorm:"not null;index" json:"date"`                // Дата расхода
+	UserID      uint         `gorm:"not null;index" json:"user_id"`                       // Идентификатор пользователя
+	CategoryID  uint         `gorm:"not null;index" json:"category_id"`                   // Идентификатор категории расхода
+	AccountID   *uint        `gorm:"index" json:"account_id"`                             // Счет, с которого оплачен расход
+	Amount      money.Amount `gorm:"not null;type:decimal(19,4)" json:"amount"`           // Сумма расхода
+	Currency    string       `gorm:"type:char(3);not null;default:'RUB'" json:"currency"` // Валюта суммы расхода
+	Description string       `json:"description"`                                         // Описание расхода
+	Date        time.Time    `gorm:"not null;index" json:"date"`                          // Дата расхода
+
+	RecurringExpenseID *uint      `gorm:"uniqueIndex:idx_expense_recurring_occurrence" json:"recurring_expense_id,omitempty"`      // Регулярный расход, из которого создан расход
+	OccurrenceDate     *time.Time `gorm:"type:date;uniqueIndex:idx_expense_recurring_occurrence" json:"occurrence_date,omitempty"` // Дата повторения регулярного расхода
+
+	SearchRank *float64 `gorm:"->;-:migration" json:"search_rank,omitempty"` // Релевантность расхода запросу полнотекстового поиска
+
 	// Связи
-	User     User     `gorm:"foreignKey:UserID" json:"-"`            // Пользователь владелец расхода
-	Category Category `gorm:"foreignKey:CategoryID" json:"category"` // Категория расхода
+	User     User     `gorm:"foreignKey:UserID" json:"-"`                   // Пользователь владелец расхода
+	Category Category `gorm:"foreignKey:CategoryID" json:"category"`        // Категория расхода
+	Account  *Account `gorm:"foreignKey:AccountID" json:"-"`                // Счет оплаты
+	Tags     []Tag    `gorm:"many2many:expense_tags" json:"tags,omitempty"` // Метки расхода
 }
 
 type CreateExpenseRequest struct {
-	CategoryID  uint      `json:"category_id" binding:"required"` // Идентификатор категории расхода
-	Amount      float64   `json:"amount" binding:"required,gt=0"` // Сумма расхода должна быть больше нуля
-	Description string    `json:"description"`                    // Описание расхода
-	Date        time.Time `json:"date" binding:"required"`        // Дата расхода
+	CategoryID  uint         `json:"category_id"`                        // Идентификатор категории расхода, без нее категория подбирается правилами
+	AccountID   *uint        `json:"account_id"`                         // Счет оплаты, валюта расхода должна совпадать с валютой счета
+	Amount      money.Amount `json:"amount" binding:"required,gt=0"`     // Сумма расхода должна быть больше нуля
+	Currency    string       `json:"currency" binding:"omitempty,len=3"` // Валюта расхода, по умолчанию базовая валюта пользователя
+	Description string       `json:"description"`                        // Описание расхода
+	Date        time.Time    `json:"date" binding:"required"`            // Дата расхода
+	TagIDs      []uint       `json:"tag_ids"`                            // Метки расхода
 }
 
 type UpdateExpenseRequest struct {
-	CategoryID  *uint      `json:"category_id,omitempty"` // Новый идентификатор категории
-	Amount      *float64   `json:"amount,omitempty"`      // Новая сумма расхода
-	Description *string    `json:"description,omitempty"` // Новое описание расхода
-	Date        *time.Time `json:"date,omitempty"`        // Новая дата расхода
+	CategoryID  *uint         `json:"category_id,omitempty"`                        // Новый идентификатор категории
+	AccountID   *uint         `json:"account_id,omitempty"`                         // Новый счет оплаты, 0 отвязывает расход от счета
+	Amount      *money.Amount `json:"amount,omitempty"`                             // Новая сумма расхода
+	Currency    *string       `json:"currency,omitempty" binding:"omitempty,len=3"` // Новая валюта расхода
+	Description *string       `json:"description,omitempty"`                        // Новое описание расхода
+	Date        *time.Time    `json:"date,omitempty"`                               // Новая дата расхода
+	TagIDs      *[]uint       `json:"tag_ids,omitempty"`                            // Новый набор меток, пустой список снимает все метки
 }
 
 type ExpenseFilter struct {
-	UserID     uint       // Идентификатор пользователя для фильтрации
-	CategoryID *uint      // Идентификатор категории для фильтрации
-	StartDate  *time.Time // Начальная дата периода для фильтрации
-	EndDate    *time.Time // Конечная дата периода для фильтрации
-	MinAmount  *float64   // Минимальная сумма для фильтрации
-	MaxAmount  *float64   // Максимальная сумма для фильтрации
-	Limit      *int       // количество записей
-	Offset     *int       // смещение
+	UserID               uint          // Идентификатор пользователя для фильтрации
+	CategoryID           *uint         // Идентификатор категории для фильтрации
+	IncludeSubcategories bool          // Учитывать вместе с категорией все ее подкатегории
+	AccountID            *uint         // Идентификатор счета для фильтрации
+	StartDate            *time.Time    // Начальная дата периода для фильтрации
+	EndDate              *time.Time    // Конечная дата периода для фильтрации
+	MinAmount            *money.Amount // Минимальная сумма для фильтрации
+	MaxAmount            *money.Amount // Максимальная сумма для фильтрации
+	Search               string        // Слова для полнотекстового поиска по описанию и названию категории
+	TagIDs               []uint        // Метки для фильтрации
+	TagMatch             TagMatch      // Нужна любая из меток или все сразу, по умолчанию любая
 }
 
 type ExpenseGroup struct {
-	Period   string    `json:"period"`   // Период группировки день неделя месяц
-	Date     time.Time `json:"date"`     // Дата начала периода
-	Total    float64   `json:"total"`    // Общая сумма расходов за период
-	Count    int       `json:"count"`    // Количество расходов за период
-	Expenses []Expense `json:"expenses"` // Список расходов в этом периоде
+	Period   string       `json:"period"`   // Период группировки день неделя месяц
+	Date     time.Time    `json:"date"`     // Дата начала периода
+	Total    money.Amount `json:"total"`    // Общая сумма расходов за период в базовой валюте
+	Currency string       `json:"currency"` // Базовая валюта пользователя
+	Count    int          `json:"count"`    // Количество расходов за период
+	Expenses []Expense    `json:"expenses"` // Список расходов в этом периоде
+}
+
+type ExpenseGroupFilter struct {
+	UserID          uint             // Идентификатор пользователя
+	By              StatisticsPeriod // Период группировки день неделя месяц
+	StartDate       *time.Time       // Начальная дата диапазона
+	EndDate         *time.Time       // Конечная дата диапазона включительно
+	IncludeExpenses bool             // Включать ли список расходов в каждую группу
+	FillEmpty       bool             // Заполнять ли периоды без расходов нулями
+}
+
+// ExpenseExportRow строка выгрузки расходов вместе с названием и цветом категории
+type ExpenseExportRow struct {
+	ID            uint         `json:"id"`             // Идентификатор расхода
+	Date          time.Time    `json:"date"`           // Дата расхода
+	CategoryID    uint         `json:"category_id"`    // Идентификатор категории
+	CategoryName  string       `json:"category_name"`  // Название категории
+	CategoryColor string       `json:"category_color"` // Цвет категории
+	AccountID     *uint        `json:"account_id"`     // Счет оплаты
+	Amount        money.Amount `json:"amount"`         // Сумма расхода
+	Currency      string       `json:"currency"`       // Валюта суммы расхода
+	Description   string       `json:"description"`    // Описание расхода
 }
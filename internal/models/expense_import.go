@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"cashcontrol/internal/money"
+)
+
+// ImportAmountSign определяет, какие суммы выписки считаются расходами
+type ImportAmountSign string
+
+const (
+	ImportAmountSignNegative ImportAmountSign = "negative" // Расходы отрицательные, поступления пропускаются
+	ImportAmountSignPositive ImportAmountSign = "positive" // Расходы положительные, возвраты пропускаются
+)
+
+// ImportRowStatus результат проверки строки выписки
+type ImportRowStatus string
+
+const (
+	ImportRowStatusNew       ImportRowStatus = "new"       // Строка будет импортирована
+	ImportRowStatusDuplicate ImportRowStatus = "duplicate" // Такой расход уже есть
+	ImportRowStatusSkipped   ImportRowStatus = "skipped"   // Поступление, а не расход
+	ImportRowStatusInvalid   ImportRowStatus = "invalid"   // Строку не удалось разобрать
+)
+
+// ExpenseImportRequest параметры импорта выписки, передаются полями multipart формы вместе с файлом
+type ExpenseImportRequest struct {
+	Format            string           `form:"format" binding:"omitempty,oneof=csv ofx qif"`            // Формат файла, по умолчанию по расширению
+	CategoryID        uint             `form:"category_id"`                                             // Категория для строк, к которым не подошло ни одно правило
+	AccountID         *uint            `form:"account_id"`                                              // Счет, с которого списаны расходы
+	Currency          string           `form:"currency" binding:"omitempty,len=3"`                      // Валюта, если ее нет в файле; по умолчанию валюта счета или базовая
+	AmountSign        ImportAmountSign `form:"amount_sign" binding:"omitempty,oneof=negative positive"` // Знак расходов, по умолчанию negative для OFX и QIF и positive для CSV
+	DateFormat        string           `form:"date_format"`                                             // Формат даты, например DD.MM.YYYY
+	DecimalSeparator  string           `form:"decimal_separator"`                                       // Десятичный разделитель "." или ","
+	Delimiter         string           `form:"delimiter"`                                               // Разделитель полей CSV, tab для табуляции
+	HasHeader         bool             `form:"has_header,default=true"`                                 // Первая строка CSV содержит названия колонок
+	DateColumn        string           `form:"date_column,default=date"`                                // Колонка даты: название или номер с единицы
+	AmountColumn      string           `form:"amount_column,default=amount"`                            // Колонка суммы
+	DescriptionColumn string           `form:"description_column"`                                      // Колонка описания, по умолчанию description, если она есть
+	CurrencyColumn    string           `form:"currency_column"`                                         // Колонка валюты, по умолчанию currency, если она есть
+	SkipDuplicates    bool             `form:"skip_duplicates,default=true"`                            // Не импортировать найденные дубликаты
+}
+
+// ExpenseImportRow строка выписки после разбора и проверки на дубликаты
+type ExpenseImportRow struct {
+	Line        int             `json:"line"`                  // Номер строки CSV или порядковый номер операции
+	Date        *time.Time      `json:"date,omitempty"`        // Дата операции
+	Amount      money.Amount    `json:"amount"`                // Сумма расхода
+	Currency    string          `json:"currency"`              // Валюта расхода
+	Description string          `json:"description"`           // Описание операции
+	CategoryID  uint            `json:"category_id,omitempty"` // Категория, в которую попадет расход
+	RuleID      *uint           `json:"rule_id,omitempty"`     // Правило категоризации, по которому выбрана категория
+	Status      ImportRowStatus `json:"status"`                // Результат проверки строки
+	Error       string          `json:"error,omitempty"`       // Причина, по которой строка некорректна
+}
+
+// ExpenseImportPreview результат разбора выписки без сохранения
+type ExpenseImportPreview struct {
+	Format     string             `json:"format"`     // Формат файла
+	Currency   string             `json:"currency"`   // Валюта импортируемых расходов
+	Rows       []ExpenseImportRow `json:"rows"`       // Строки выписки
+	New        int                `json:"new"`        // Количество новых расходов
+	Duplicates int                `json:"duplicates"` // Количество дубликатов
+	Skipped    int                `json:"skipped"`    // Количество пропущенных поступлений
+	Invalid    int                `json:"invalid"`    // Количество некорректных строк
+}
+
+// ExpenseImportResult итог импорта выписки
+type ExpenseImportResult struct {
+	Imported   int `json:"imported"`   // Количество созданных расходов
+	Duplicates int `json:"duplicates"` // Количество пропущенных дубликатов
+	Skipped    int `json:"skipped"`    // Количество пропущенных поступлений
+}
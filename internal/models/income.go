@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"cashcontrol/internal/money"
+
+	"gorm.io/gorm"
+)
+
+type Income struct {
+	gorm.Model
+
+	UserID      uint         `gorm:"not null;index" json:"user_id"`                       // Идентификатор пользователя
+	CategoryID  uint         `gorm:"not null;index" json:"category_id"`                   // Идентификатор категории дохода
+	AccountID   *uint        `gorm:"index" json:"account_id"`                             // Счет, на который поступил доход
+	Amount      money.Amount `gorm:"not null;type:decimal(19,4)" json:"amount"`           // Сумма дохода
+	Currency    string       `gorm:"type:char(3);not null;default:'RUB'" json:"currency"` // Валюта суммы дохода
+	Description string       `json:"description"`                                         // Описание дохода
+	Date        time.Time    `gorm:"not null;index" json:"date"`                          // Дата поступления
+
+	// Связи
+	User     User     `gorm:"foreignKey:UserID" json:"-"`            // Пользователь получатель дохода
+	Category Category `gorm:"foreignKey:CategoryID" json:"category"` // Категория дохода
+	Account  *Account `gorm:"foreignKey:AccountID" json:"-"`         // Счет зачисления
+}
+
+type CreateIncomeRequest struct {
+	CategoryID  uint         `json:"category_id" binding:"required"`     // Идентификатор категории дохода
+	AccountID   *uint        `json:"account_id"`                         // Счет зачисления, валюта дохода должна совпадать с валютой счета
+	Amount      money.Amount `json:"amount" binding:"required,gt=0"`     // Сумма дохода должна быть больше нуля
+	Currency    string       `json:"currency" binding:"omitempty,len=3"` // Валюта дохода, по умолчанию базовая валюта пользователя
+	Description string       `json:"description"`                        // Описание дохода
+	Date        time.Time    `json:"date" binding:"required"`            // Дата поступления
+}
+
+type UpdateIncomeRequest struct {
+	CategoryID  *uint         `json:"category_id,omitempty"`                        // Новый идентификатор категории
+	AccountID   *uint         `json:"account_id,omitempty"`                         // Новый счет зачисления, 0 отвязывает доход от счета
+	Amount      *money.Amount `json:"amount,omitempty"`                             // Новая сумма дохода
+	Currency    *string       `json:"currency,omitempty" binding:"omitempty,len=3"` // Новая валюта дохода
+	Description *string       `json:"description,omitempty"`                        // Новое описание дохода
+	Date        *time.Time    `json:"date,omitempty"`                               // Новая дата поступления
+}
+
+type IncomeFilter struct {
+	UserID     uint          // Идентификатор пользователя для фильтрации
+	CategoryID *uint         // Идентификатор категории для фильтрации
+	AccountID  *uint         // Идентификатор счета для фильтрации
+	StartDate  *time.Time    // Начальная дата периода для фильтрации
+	EndDate    *time.Time    // Конечная дата периода для фильтрации
+	MinAmount  *money.Amount // Минимальная сумма для фильтрации
+	MaxAmount  *money.Amount // Максимальная сумма для фильтрации
+	Limit      *int          // количество записей
+	Offset     *int          // смещение
+}
+
+// CashFlowPeriod доходы, расходы и их разница за период в базовой валюте
+type CashFlowPeriod struct {
+	Period    StatisticsPeriod `json:"period"`     // Период группировки день неделя месяц год
+	StartDate time.Time        `json:"start_date"` // Начальная дата периода
+	EndDate   time.Time        `json:"end_date"`   // Конечная дата периода
+	Currency  string           `json:"currency"`   // Базовая валюта, в которой посчитаны суммы
+	Income    money.Amount     `json:"income"`     // Сумма доходов за период
+	Expenses  money.Amount     `json:"expenses"`   // Сумма расходов за период
+	Net       money.Amount     `json:"net"`        // Доходы минус расходы, отрицательное при дефиците
+}
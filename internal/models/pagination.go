@@ -0,0 +1,39 @@
+package models
+
+const (
+	DefaultPageSize = 50  // Размер страницы, если limit не указан
+	MaxPageSize     = 200 // Максимальный размер страницы
+)
+
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// Поля сортировки списков; какие из них доступны, зависит от списка
+const (
+	SortByDate      = "date"
+	SortByAmount    = "amount"
+	SortByCreatedAt = "created_at"
+	SortByName      = "name"
+	SortByNextDate  = "next_date"
+	SortByPeriod    = "period"
+	SortByRelevance = "relevance"
+)
+
+// PageRequest параметры страницы списка с постраничным выводом по курсору
+type PageRequest struct {
+	Limit  int       // Размер страницы
+	Cursor string    // Курсор из next_cursor предыдущей страницы, пусто для первой страницы
+	SortBy string    // Поле сортировки
+	Order  SortOrder // Направление сортировки
+}
+
+// Page страница списка
+type Page[T any] struct {
+	Items      []T     `json:"items"`       // Записи страницы
+	NextCursor *string `json:"next_cursor"` // Курсор следующей страницы, null на последней странице
+	Total      int64   `json:"total"`       // Число записей по фильтру на всех страницах
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"cashcontrol/internal/money"
+
 	"gorm.io/gorm"
 )
 
@@ -17,15 +19,17 @@ const (
 
 type RecurringExpense struct {
 	gorm.Model
-	UserID      uint                 `gorm:"not null;index" json:"user_id"`             // Идентификатор пользователя
-	CategoryID  uint                 `gorm:"not null;index" json:"category_id"`         // Идентификатор категории расхода
-	Amount      float64              `gorm:"not null;type:decimal(10,2)" json:"amount"` // Сумма регулярного расхода
-	Description string               `json:"description"`                               // Описание регулярного расхода
-	Type        RecurringExpenseType `gorm:"not null" json:"type"`                      // Тип повторения ежедневно еженедельно ежемесячно ежегодно
-	DayOfMonth  *int                 `json:"day_of_month"`                              // День месяца для ежемесячных расходов от 1 до 31
-	DayOfWeek   *int                 `json:"day_of_week"`                               // День недели для еженедельных расходов от 0 до 6 где 0 воскресенье
-	IsActive    bool                 `gorm:"default:true" json:"is_active"`             // Флаг активности регулярного расхода
-	NextDate    time.Time            `gorm:"not null;index" json:"next_date"`           // Следующая дата автоматического создания расхода
+	UserID      uint                 `gorm:"not null;index" json:"user_id"`                       // Идентификатор пользователя
+	CategoryID  uint                 `gorm:"not null;index" json:"category_id"`                   // Идентификатор категории расхода
+	AccountID   *uint                `gorm:"index" json:"account_id"`                             // Счет, с которого списываются созданные расходы
+	Amount      money.Amount         `gorm:"not null;type:decimal(19,4)" json:"amount"`           // Сумма регулярного расхода
+	Currency    string               `gorm:"type:char(3);not null;default:'RUB'" json:"currency"` // Валюта суммы регулярного расхода
+	Description string               `json:"description"`                                         // Описание регулярного расхода
+	Type        RecurringExpenseType `gorm:"not null" json:"type"`                                // Тип повторения ежедневно еженедельно ежемесячно ежегодно
+	DayOfMonth  *int                 `json:"day_of_month"`                                        // День месяца для ежемесячных расходов от 1 до 31
+	DayOfWeek   *int                 `json:"day_of_week"`                                         // День недели для еженедельных расходов от 0 до 6 где 0 воскресенье
+	IsActive    bool                 `gorm:"default:true" json:"is_active"`                       // Флаг активности регулярного расхода
+	NextDate    time.Time            `gorm:"not null;index" json:"next_date"`                     // Следующая дата автоматического создания расхода
 
 	// Связи
 	User     User     `gorm:"foreignKey:UserID" json:"-"`            // Пользователь владелец регулярного расхода
@@ -34,7 +38,9 @@ type RecurringExpense struct {
 
 type CreateRecurringExpenseRequest struct {
 	CategoryID  uint                 `json:"category_id" binding:"required"`                            // Идентификатор категории расхода
-	Amount      float64              `json:"amount" binding:"required,gt=0"`                            // Сумма расхода должна быть больше нуля
+	AccountID   *uint                `json:"account_id"`                                                // Счет оплаты, валюта должна совпадать с валютой счета
+	Amount      money.Amount         `json:"amount" binding:"required,gt=0"`                            // Сумма расхода должна быть больше нуля
+	Currency    string               `json:"currency" binding:"omitempty,len=3"`                        // Валюта расхода, по умолчанию базовая валюта пользователя
 	Description string               `json:"description"`                                               // Описание регулярного расхода
 	Type        RecurringExpenseType `json:"type" binding:"required,oneof=daily weekly monthly yearly"` // Тип повторения
 	DayOfMonth  *int                 `json:"day_of_month"`                                              // День месяца для ежемесячных расходов
@@ -42,11 +48,13 @@ type CreateRecurringExpenseRequest struct {
 }
 
 type UpdateRecurringExpenseRequest struct {
-	CategoryID  *uint                 `json:"category_id,omitempty"`  // Новый идентификатор категории
-	Amount      *float64              `json:"amount,omitempty"`       // Новая сумма расхода
-	Description *string               `json:"description,omitempty"`  // Новое описание расхода
-	Type        *RecurringExpenseType `json:"type,omitempty"`         // Новый тип повторения
-	DayOfMonth  *int                  `json:"day_of_month,omitempty"` // Новый день месяца
-	DayOfWeek   *int                  `json:"day_of_week,omitempty"`  // Новый день недели
-	IsActive    *bool                 `json:"is_active,omitempty"`    // Новый статус активности
+	CategoryID  *uint                 `json:"category_id,omitempty"`                        // Новый идентификатор категории
+	AccountID   *uint                 `json:"account_id,omitempty"`                         // Новый счет оплаты, 0 отвязывает от счета
+	Amount      *money.Amount         `json:"amount,omitempty"`                             // Новая сумма расхода
+	Currency    *string               `json:"currency,omitempty" binding:"omitempty,len=3"` // Новая валюта расхода
+	Description *string               `json:"description,omitempty"`                        // Новое описание расхода
+	Type        *RecurringExpenseType `json:"type,omitempty"`                               // Новый тип повторения
+	DayOfMonth  *int                  `json:"day_of_month,omitempty"`                       // Новый день месяца
+	DayOfWeek   *int                  `json:"day_of_week,omitempty"`                        // Новый день недели
+	IsActive    *bool                 `json:"is_active,omitempty"`                          // Новый статус активности
 }
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type RefreshToken struct {
+	gorm.Model
+	UserID       uint       `gorm:"not null;index" json:"user_id"`   // Идентификатор пользователя
+	TokenHash    string     `gorm:"not null;uniqueIndex" json:"-"`   // SHA-256 хеш токена, сам токен не хранится
+	FamilyID     string     `gorm:"not null;index" json:"family_id"` // Идентификатор цепочки ротаций одной сессии
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`      // Время истечения токена
+	RevokedAt    *time.Time `gorm:"index" json:"revoked_at"`         // Время отзыва или ротации токена
+	ReplacedByID *uint      `json:"replaced_by_id"`                  // Токен, выданный взамен при ротации
+
+	// Связи
+	User User `gorm:"foreignKey:UserID" json:"-"` // Пользователь владелец сессии
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"` // Refresh токен, полученный при входе или предыдущем обновлении
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"cashcontrol/internal/money"
+)
 
 type StatisticsPeriod string
 
@@ -12,28 +16,61 @@ const (
 )
 
 type CategoryStatistics struct {
-	CategoryID    int     `json:"category_id"`    // Идентификатор категории
-	CategoryName  string  `json:"category_name"`  // Название категории
-	CategoryColor string  `json:"category_color"` // Цвет категории
-	TotalAmount   float64 `json:"total_amount"`   // Общая сумма расходов в категории
-	Count         int     `json:"count"`          // Количество расходов в категории
-	Percentage    float64 `json:"percentage"`     // Процент от общей суммы всех расходов
+	CategoryID    int                  `json:"category_id"`             // Идентификатор категории
+	CategoryName  string               `json:"category_name"`           // Название категории
+	CategoryColor string               `json:"category_color"`          // Цвет категории
+	TotalAmount   money.Amount         `json:"total_amount"`            // Общая сумма расходов в категории и ее подкатегориях
+	Count         int                  `json:"count"`                   // Количество расходов в категории и ее подкатегориях
+	Percentage    float64              `json:"percentage"`              // Процент от общей суммы всех расходов
+	Subcategories []CategoryStatistics `json:"subcategories,omitempty"` // Статистика по подкатегориям
 }
 
 type PeriodStatistics struct {
 	Period        StatisticsPeriod     `json:"period"`         // Период статистики день неделя месяц год
 	StartDate     time.Time            `json:"start_date"`     // Начальная дата периода
 	EndDate       time.Time            `json:"end_date"`       // Конечная дата периода
-	TotalAmount   float64              `json:"total_amount"`   // Общая сумма расходов за период
+	Currency      string               `json:"currency"`       // Базовая валюта, в которой посчитаны суммы
+	TotalAmount   money.Amount         `json:"total_amount"`   // Общая сумма расходов за период
 	Count         int                  `json:"count"`          // Количество расходов за период
-	AverageAmount float64              `json:"average_amount"` // Средняя сумма расхода за период
+	AverageAmount money.Amount         `json:"average_amount"` // Средняя сумма расхода за период
 	ByCategory    []CategoryStatistics `json:"by_category"`    // Статистика по каждой категории
+	ByTag         []TagStatistics      `json:"by_tag"`         // Статистика по меткам, расход с несколькими метками учитывается в каждой
 }
 
 type ExpenseDistribution struct {
-	CategoryID    int     `json:"category_id"`    // Идентификатор категории
-	CategoryName  string  `json:"category_name"`  // Название категории
-	CategoryColor string  `json:"category_color"` // Цвет категории
-	Amount        float64 `json:"amount"`         // Сумма расходов в категории
-	Percentage    float64 `json:"percentage"`     // Процент расходов в категории от общей суммы
+	CategoryID    int                   `json:"category_id"`             // Идентификатор категории
+	CategoryName  string                `json:"category_name"`           // Название категории
+	CategoryColor string                `json:"category_color"`          // Цвет категории
+	Amount        money.Amount          `json:"amount"`                  // Сумма расходов в категории и ее подкатегориях
+	Currency      string                `json:"currency"`                // Базовая валюта, в которой посчитана сумма
+	Percentage    float64               `json:"percentage"`              // Процент расходов в категории от общей суммы
+	Subcategories []ExpenseDistribution `json:"subcategories,omitempty"` // Распределение по подкатегориям
+}
+
+// DailyTotal дневная сумма расходов категории в одной валюте, возвращаемая репозиторием
+type DailyTotal struct {
+	Day           time.Time    // День расхода в UTC
+	Currency      string       // Валюта суммы
+	CategoryID    uint         // Идентификатор категории
+	CategoryName  string       // Название категории
+	CategoryColor string       // Цвет категории
+	TotalAmount   money.Amount // Сумма расходов
+	Count         int          // Количество расходов
+}
+
+// CategoryPeriodTotal агрегат расходов по категории за период в базовой валюте
+type CategoryPeriodTotal struct {
+	PeriodStart   time.Time    // Начало периода, нулевое значение при агрегации без периода
+	CategoryID    uint         // Идентификатор категории
+	CategoryName  string       // Название категории
+	CategoryColor string       // Цвет категории
+	TotalAmount   money.Amount // Сумма расходов
+	Count         int          // Количество расходов
+}
+
+// PeriodTotal агрегат расходов за период в базовой валюте
+type PeriodTotal struct {
+	PeriodStart time.Time    // Начало периода
+	TotalAmount money.Amount // Сумма расходов
+	Count       int          // Количество расходов
 }
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"cashcontrol/internal/money"
+
+	"gorm.io/gorm"
+)
+
+// TagMatch как расход должен совпасть с метками фильтра
+type TagMatch string
+
+const (
+	TagMatchAny TagMatch = "any" // Расход отмечен хотя бы одной из меток
+	TagMatchAll TagMatch = "all" // Расход отмечен всеми метками
+)
+
+// Tag метка пользователя, которой можно отметить любые расходы независимо от категории,
+// например командировку или расходы к возмещению
+type Tag struct {
+	gorm.Model
+	UserID uint   `gorm:"not null;index" json:"user_id"`  // Идентификатор пользователя владельца метки
+	Name   string `gorm:"not null" json:"name"`           // Название метки, уникально у пользователя без учета регистра
+	Color  string `gorm:"default:'#6B7280'" json:"color"` // Цвет метки
+
+	// Связи
+	User User `gorm:"foreignKey:UserID" json:"-"` // Пользователь владелец метки
+}
+
+type CreateTagRequest struct {
+	Name  string `json:"name" binding:"required"` // Название метки
+	Color string `json:"color"`                   // Цвет метки
+}
+
+type UpdateTagRequest struct {
+	Name  *string `json:"name,omitempty"`  // Новое название метки
+	Color *string `json:"color,omitempty"` // Новый цвет метки
+}
+
+type TagStatistics struct {
+	TagID       uint         `json:"tag_id"`       // Идентификатор метки
+	TagName     string       `json:"tag_name"`     // Название метки
+	TagColor    string       `json:"tag_color"`    // Цвет метки
+	TotalAmount money.Amount `json:"total_amount"` // Общая сумма расходов с меткой
+	Count       int          `json:"count"`        // Количество расходов с меткой
+	Percentage  float64      `json:"percentage"`   // Процент от общей суммы всех расходов
+}
+
+// TagDailyTotal дневная сумма расходов с меткой в одной валюте, возвращаемая репозиторием
+type TagDailyTotal struct {
+	Day         time.Time    // День расхода в UTC
+	Currency    string       // Валюта суммы
+	TagID       uint         // Идентификатор метки
+	TagName     string       // Название метки
+	TagColor    string       // Цвет метки
+	TotalAmount money.Amount // Сумма расходов
+	Count       int          // Количество расходов
+}
+
+// TagPeriodTotal агрегат расходов с меткой за период в базовой валюте
+type TagPeriodTotal struct {
+	PeriodStart time.Time    // Начало периода, нулевое значение при агрегации без периода
+	TagID       uint         // Идентификатор метки
+	TagName     string       // Название метки
+	TagColor    string       // Цвет метки
+	TotalAmount money.Amount // Сумма расходов
+	Count       int          // Количество расходов
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"cashcontrol/internal/money"
+	"time"
+)
+
+// TrashEntityTypes типы сущностей, которые при удалении попадают в корзину
+var TrashEntityTypes = []string{
+	EntityTypeExpense,
+	EntityTypeIncome,
+	EntityTypeTransfer,
+	EntityTypeAccount,
+	EntityTypeCategory,
+	EntityTypeBudget,
+	EntityTypeRecurringExpense,
+	EntityTypeCategorizationRule,
+	EntityTypeTag,
+}
+
+// TrashItem удаленная запись в корзине пользователя
+type TrashItem struct {
+	EntityType string        `json:"entity_type"`        // Тип сущности
+	EntityID   uint          `json:"entity_id"`          // Идентификатор сущности
+	Title      string        `json:"title"`              // Описание или название записи
+	Amount     *money.Amount `json:"amount,omitempty"`   // Сумма операции, бюджета или остаток счета
+	Currency   string        `json:"currency,omitempty"` // Валюта суммы
+	DeletedAt  time.Time     `json:"deleted_at"`         // Время удаления
+	PurgeAt    *time.Time    `json:"purge_at,omitempty"` // Когда запись будет удалена окончательно, пусто если очистка отключена
+}
+
+type TrashFilter struct {
+	UserID     uint    // Идентификатор пользователя для фильтрации
+	EntityType *string // Тип сущности для фильтрации
+	Limit      *int    // количество записей
+	Offset     *int    // смещение
+}
+
+// TrashEntityRef ссылка на запись, восстановленную из корзины
+type TrashEntityRef struct {
+	EntityType string `json:"entity_type"` // Тип сущности
+	EntityID   uint   `json:"entity_id"`   // Идентификатор сущности
+}
+
+// TrashRestoreResult восстановленная запись и удаленные записи, на которые она ссылалась и которые восстановлены вместе с ней
+type TrashRestoreResult struct {
+	EntityType   string           `json:"entity_type"`  // Тип восстановленной сущности
+	EntityID     uint             `json:"entity_id"`    // Идентификатор восстановленной сущности
+	Dependencies []TrashEntityRef `json:"dependencies"` // Восстановленные вместе с ней категории и счета
+}
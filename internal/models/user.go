@@ -1,14 +1,18 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type User struct {
 	gorm.Model
-	Email     string `gorm:"uniqueIndex;not null" json:"email"`    // Электронная почта пользователя
-	Username  string `gorm:"uniqueIndex;not null" json:"username"` // Имя пользователя
-	Password  string `gorm:"not null" json:"-"`                    // Хешированный пароль пользователя
+	Email    string `gorm:"uniqueIndex;not null" json:"email"`    // Электронная почта пользователя
+	Username string `gorm:"uniqueIndex;not null" json:"username"` // Имя пользователя
+	Password string `gorm:"not null" json:"-"`                    // Хешированный пароль пользователя
+
+	BaseCurrency string `gorm:"type:char(3);not null;default:'RUB'" json:"base_currency"` // Валюта, в которую пересчитываются бюджеты и статистика
 
 	// Связи
 	Expenses          []Expense          `gorm:"foreignKey:UserID" json:"-"` // Все расходы пользователя
@@ -22,6 +26,9 @@ type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`    // Электронная почта для регистрации
 	Username string `json:"username" binding:"required,min=3"` // Имя пользователя для регистрации
 	Password string `json:"password" binding:"required,min=6"` // Пароль для регистрации
+
+	BaseCurrency string `json:"base_currency" binding:"omitempty,len=3"` // Базовая валюта, по умолчанию RUB
+	Locale       string `json:"locale" binding:"omitempty,max=16"`       // Язык названий категорий по умолчанию, например ru или en
 }
 
 type LoginRequest struct {
@@ -30,6 +37,9 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string `json:"token"` // JWT токен для аутентификации
-	User  *User  `json:"user"`  // Информация о пользователе
+	Token            string    `json:"token"`              // JWT токен доступа для аутентификации
+	TokenExpiresAt   time.Time `json:"token_expires_at"`   // Время истечения токена доступа
+	RefreshToken     string    `json:"refresh_token"`      // Токен для получения новой пары токенов
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"` // Время истечения refresh токена
+	User             *User     `json:"user"`               // Информация о пользователе
 }
@@ -0,0 +1,294 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Scale число знаков после запятой во внутреннем представлении суммы
+	Scale = 4
+	// unit количество внутренних единиц в одной денежной единице
+	unit = 10000
+	// maxIntegerDigits ограничение целой части: помещается в колонку decimal(19,4) и в int64 с запасом,
+	// поэтому округление до единицы валюты не переполняется
+	maxIntegerDigits = 14
+)
+
+var (
+	ErrInvalidAmount = errors.New("некорректная сумма")
+	ErrTooPrecise    = errors.New("сумма содержит больше четырех знаков после запятой")
+	ErrTooLarge      = errors.New("сумма слишком велика")
+)
+
+// Amount денежная сумма с фиксированной точностью: целое число десятитысячных долей единицы валюты.
+// В JSON сериализуется строкой, чтобы клиенты не теряли точность, в БД хранится как numeric.
+type Amount int64
+
+// Parse разбирает десятичную запись суммы, например "1234.5", "-0,01" или "100"
+func Parse(s string) (Amount, error) {
+	v, err := parseFixed(s, Scale, maxIntegerDigits)
+	if err != nil {
+		return 0, err
+	}
+	return Amount(v), nil
+}
+
+// parseFixed разбирает десятичную запись в целое число долей 10^-scale.
+// Ошибки — ErrInvalidAmount, ErrTooPrecise и ErrTooLarge; вызывающий может заменить их своими.
+func parseFixed(s string, scale, maxIntegerDigits int) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, hasFrac := strings.Cut(strings.Replace(s, ",", ".", 1), ".")
+	if intPart == "" && (!hasFrac || fracPart == "") {
+		return 0, ErrInvalidAmount
+	}
+	if hasFrac && fracPart == "" {
+		return 0, ErrInvalidAmount
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, ErrInvalidAmount
+	}
+
+	intPart = strings.TrimLeft(intPart, "0")
+	if len(intPart) > maxIntegerDigits {
+		return 0, ErrTooLarge
+	}
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > scale {
+		return 0, ErrTooPrecise
+	}
+
+	digits := intPart + fracPart + strings.Repeat("0", scale-len(fracPart))
+	v, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, ErrTooLarge
+		}
+		return 0, ErrInvalidAmount
+	}
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+// FromFloat переводит число с плавающей точкой в сумму с округлением до внутренней точности
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * unit))
+}
+
+// Float64 возвращает приближенное значение суммы, пригодное только для расчета долей и логов
+func (a Amount) Float64() float64 {
+	return float64(a) / unit
+}
+
+// String возвращает десятичную запись суммы; всегда содержит не меньше двух знаков после запятой
+func (a Amount) String() string {
+	v := int64(a)
+	sign := ""
+	if v < 0 {
+		sign = "-"
+	}
+	abs := uint64(v)
+	if v < 0 {
+		abs = uint64(-v)
+	}
+
+	frac := fmt.Sprintf("%04d", abs%unit)
+	frac = strings.TrimRight(frac, "0")
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	return fmt.Sprintf("%s%d.%s", sign, abs/unit, frac)
+}
+
+// Round округляет сумму до минимальной единицы валюты, половина округляется от нуля
+func (a Amount) Round(currency string) Amount {
+	step := currencyStep(currency)
+	return Amount(divRound(int64(a), step) * step)
+}
+
+// MulRate умножает сумму на курс и округляет до минимальной единицы валюты currency, половина от нуля.
+// Курс передается точной дробью (см. Rate.Rat), поэтому умножение идет без потери точности.
+// Результат за пределами Amount ограничивается крайним значением; без курса результат 0.
+func (a Amount) MulRate(rate *big.Rat, currency string) Amount {
+	if rate == nil {
+		return 0
+	}
+	step := currencyStep(currency)
+	r := new(big.Rat).Mul(rate, big.NewRat(int64(a), step))
+
+	// Частное в минимальных единицах валюты с округлением половины от нуля
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if m.Abs(m).Lsh(m, 1).Cmp(r.Denom()) >= 0 {
+		if r.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	q.Mul(q, big.NewInt(step))
+	switch {
+	case q.IsInt64():
+		return Amount(q.Int64())
+	case q.Sign() < 0:
+		return Amount(math.MinInt64 / step * step)
+	default:
+		return Amount(math.MaxInt64 / step * step)
+	}
+}
+
+// Div делит сумму на n с округлением до минимальной единицы валюты currency
+func (a Amount) Div(n int, currency string) Amount {
+	if n == 0 {
+		return 0
+	}
+	return Amount(divRound(int64(a), int64(n))).Round(currency)
+}
+
+// Percent возвращает долю part от total в процентах, отброшенную до сотых.
+// Расчет целочисленный, поэтому сравнение с порогом не зависит от ошибок округления float64.
+func Percent(part, total Amount) float64 {
+	if total == 0 {
+		return 0
+	}
+	n := new(big.Int).Mul(big.NewInt(int64(part)), big.NewInt(10000))
+	n.Quo(n, big.NewInt(int64(total)))
+	return float64(n.Int64()) / 100
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON принимает сумму строкой ("12.30") или числом (12.3); число разбирается как текст без потери точности
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" {
+		return nil
+	}
+	if strings.HasPrefix(raw, `"`) {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+
+	v, err := Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, raw)
+	}
+	*a = v
+	return nil
+}
+
+// Value передает сумму в БД десятичной строкой
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan читает numeric из БД; значение с большей точностью округляется до внутренней
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+		return nil
+	case int64:
+		*a = Amount(v * unit)
+		return nil
+	case float64:
+		*a = FromFloat(v)
+		return nil
+	case []byte:
+		return a.scanString(string(v))
+	case string:
+		return a.scanString(v)
+	default:
+		return fmt.Errorf("money: неподдерживаемый тип %T", src)
+	}
+}
+
+func (a *Amount) scanString(s string) error {
+	v, err := Parse(s)
+	if errors.Is(err, ErrTooPrecise) {
+		// Например, результат AVG: округляем до внутренней точности
+		f, ferr := strconv.ParseFloat(s, 64)
+		if ferr != nil {
+			return ferr
+		}
+		v, err = FromFloat(f), nil
+	}
+	if err != nil {
+		return fmt.Errorf("money: %w: %q", err, s)
+	}
+	*a = v
+	return nil
+}
+
+// Decimals возвращает число знаков после запятой в минимальной единице валюты по ISO 4217
+func Decimals(currency string) int {
+	switch strings.ToUpper(currency) {
+	case "BIF", "CLP", "DJF", "GNF", "ISK", "JPY", "KMF", "KRW", "PYG", "RWF", "UGX", "VND", "VUV", "XAF", "XOF", "XPF":
+		return 0
+	case "BHD", "IQD", "JOD", "KWD", "LYD", "OMR", "TND":
+		return 3
+	default:
+		return 2
+	}
+}
+
+// currencyStep возвращает минимальную единицу валюты во внутренних единицах суммы
+func currencyStep(currency string) int64 {
+	step := int64(1)
+	for i := Decimals(currency); i < Scale; i++ {
+		step *= 10
+	}
+	return step
+}
+
+// divRound делит с округлением половины от нуля
+func divRound(v, d int64) int64 {
+	if d < 0 {
+		v, d = -v, -d
+	}
+	q, r := v/d, v%d
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= d {
+		if v < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Amount
+		err  error
+	}{
+		{in: "100", want: 1000000},
+		{in: "1234.5", want: 12345000},
+		{in: "-0,01", want: -100},
+		{in: "+0.0001", want: 1},
+		{in: " 007.1200 ", want: 71200},
+		{in: ".5", want: 5000},
+		{in: "99999999999999.9999", want: 999999999999999999},
+		{in: "-99999999999999.9999", want: -999999999999999999},
+		{in: "999999999999999.9999", err: ErrTooLarge},
+		{in: "922337203685477.5808", err: ErrTooLarge},
+		{in: "100000000000000", err: ErrTooLarge},
+		{in: "1.00001", err: ErrTooPrecise},
+		{in: "", err: ErrInvalidAmount},
+		{in: "-", err: ErrInvalidAmount},
+		{in: ".", err: ErrInvalidAmount},
+		{in: "1.", err: ErrInvalidAmount},
+		{in: "1e3", err: ErrInvalidAmount},
+		{in: "1.2.3", err: ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%q) error = %v, want %v", tt.in, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		in       Amount
+		currency string
+		want     Amount
+	}{
+		{in: 12345, currency: "RUB", want: 12300},
+		{in: 12350, currency: "RUB", want: 12400},
+		{in: -12350, currency: "RUB", want: -12400},
+		{in: 15000, currency: "JPY", want: 20000},
+		{in: -15000, currency: "jpy", want: -20000},
+		{in: 14999, currency: "JPY", want: 10000},
+		{in: 12345, currency: "KWD", want: 12350},
+		{in: 999999999999999999, currency: "JPY", want: 1000000000000000000},
+	}
+	for _, tt := range tests {
+		if got := tt.in.Round(tt.currency); got != tt.want {
+			t.Errorf("Amount(%d).Round(%q) = %d, want %d", tt.in, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestMulRate(t *testing.T) {
+	tests := []struct {
+		in       Amount
+		rate     string
+		inverse  bool
+		currency string
+		want     Amount
+	}{
+		{in: 1000000, rate: "0.5", currency: "USD", want: 500000},
+		{in: 1000000, rate: "0.0125", currency: "USD", want: 12500},
+		{in: 100, rate: "0.5", currency: "USD", want: 100},
+		{in: -100, rate: "0.5", currency: "USD", want: -100},
+		{in: 10000, rate: "1.5", currency: "JPY", want: 20000},
+		{in: 1000000, rate: "0.00000001", currency: "USD", want: 0},
+		{in: 10000000000, rate: "0.01085071", currency: "USD", want: 108507100},
+		// Больше 2^53 десятитысячных: через float64 младшие разряды терялись бы
+		{in: 999999999999999999, rate: "1", currency: "USD", want: 1000000000000000000},
+		{in: 900719925474099300, rate: "1", currency: "USD", want: 900719925474099300},
+		{in: 999999999999999999, rate: "100", currency: "USD", want: math.MaxInt64 / 100 * 100},
+		{in: -999999999999999999, rate: "100", currency: "USD", want: math.MinInt64 / 100 * 100},
+		// Обратный курс считается точной дробью, а не округленным до восьми знаков числом
+		{in: 1000000, rate: "3", inverse: true, currency: "USD", want: 333300},
+		{in: 920000000, rate: "92", inverse: true, currency: "USD", want: 10000000},
+		{in: 10000000000000, rate: "92.5", inverse: true, currency: "USD", want: 108108108100},
+	}
+	for _, tt := range tests {
+		rate, err := ParseRate(tt.rate)
+		if err != nil {
+			t.Fatalf("ParseRate(%q): %v", tt.rate, err)
+		}
+		r := rate.Rat()
+		if tt.inverse {
+			r = rate.Inverse()
+		}
+		if got := tt.in.MulRate(r, tt.currency); got != tt.want {
+			t.Errorf("Amount(%d).MulRate(%s, inverse=%v, %q) = %d, want %d", tt.in, tt.rate, tt.inverse, tt.currency, got, tt.want)
+		}
+	}
+
+	if got := Amount(1000000).MulRate(nil, "USD"); got != 0 {
+		t.Errorf("MulRate(nil) = %d, want 0", got)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   Amount
+		want string
+	}{
+		{in: 0, want: "0.00"},
+		{in: 12345000, want: "1234.50"},
+		{in: -100, want: "-0.01"},
+		{in: 1, want: "0.0001"},
+		{in: 999999999999999999, want: "99999999999999.9999"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// RateScale число знаков после запятой в курсе, как в колонке decimal(18,8)
+	RateScale = 8
+	// rateUnit количество внутренних единиц в единице курса
+	rateUnit = 100_000_000
+	// maxRateIntegerDigits ограничение целой части курса: decimal(18,8) хранит десять знаков до запятой
+	maxRateIntegerDigits = 10
+)
+
+var (
+	ErrInvalidRate    = errors.New("некорректный курс")
+	ErrRateTooPrecise = errors.New("курс содержит больше восьми знаков после запятой")
+	ErrRateTooLarge   = errors.New("курс слишком велик")
+)
+
+// Rate курс обмена с фиксированной точностью: целое число стомиллионных долей.
+// В JSON сериализуется строкой, в БД хранится как decimal(18,8) без округления.
+type Rate int64
+
+// ParseRate разбирает десятичную запись курса, например "92.5", "0,01085" или "1"
+func ParseRate(s string) (Rate, error) {
+	v, err := parseFixed(s, RateScale, maxRateIntegerDigits)
+	switch {
+	case errors.Is(err, ErrTooPrecise):
+		return 0, ErrRateTooPrecise
+	case errors.Is(err, ErrTooLarge):
+		return 0, ErrRateTooLarge
+	case err != nil:
+		return 0, ErrInvalidRate
+	}
+	return Rate(v), nil
+}
+
+// Rat возвращает курс точной дробью для пересчета сумм
+func (r Rate) Rat() *big.Rat {
+	return big.NewRat(int64(r), rateUnit)
+}
+
+// Inverse возвращает обратный курс точной дробью; для нулевого курса возвращает nil
+func (r Rate) Inverse() *big.Rat {
+	if r == 0 {
+		return nil
+	}
+	return big.NewRat(rateUnit, int64(r))
+}
+
+// String возвращает десятичную запись курса без лишних нулей после запятой
+func (r Rate) String() string {
+	v := int64(r)
+	sign := ""
+	if v < 0 {
+		sign = "-"
+	}
+	abs := uint64(v)
+	if v < 0 {
+		abs = uint64(-v)
+	}
+
+	frac := strings.TrimRight(fmt.Sprintf("%08d", abs%rateUnit), "0")
+	if frac == "" {
+		return fmt.Sprintf("%s%d", sign, abs/rateUnit)
+	}
+	return fmt.Sprintf("%s%d.%s", sign, abs/rateUnit, frac)
+}
+
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON принимает курс строкой ("92.5") или числом (92.5); число разбирается как текст без потери точности
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" {
+		return nil
+	}
+	if strings.HasPrefix(raw, `"`) {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+	}
+
+	v, err := ParseRate(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, raw)
+	}
+	*r = v
+	return nil
+}
+
+// Value передает курс в БД десятичной строкой
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// Scan читает decimal из БД по его текстовой записи
+func (r *Rate) Scan(src interface{}) error {
+	var raw string
+	switch v := src.(type) {
+	case nil:
+		*r = 0
+		return nil
+	case int64:
+		raw = strconv.FormatInt(v, 10)
+	case float64:
+		raw = strconv.FormatFloat(v, 'f', -1, 64)
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return fmt.Errorf("money: неподдерживаемый тип курса %T", src)
+	}
+
+	v, err := ParseRate(raw)
+	if err != nil {
+		return fmt.Errorf("money: %w: %q", err, raw)
+	}
+	*r = v
+	return nil
+}
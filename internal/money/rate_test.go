@@ -0,0 +1,113 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Rate
+		err  error
+	}{
+		{in: "1", want: 100000000},
+		{in: "92.5", want: 9250000000},
+		{in: "0,01085071", want: 1085071},
+		{in: " 0.00000001 ", want: 1},
+		{in: "9999999999.99999999", want: 999999999999999999},
+		{in: "12345678901", err: ErrRateTooLarge},
+		{in: "0.000000001", err: ErrRateTooPrecise},
+		{in: "", err: ErrInvalidRate},
+		{in: "1e-3", err: ErrInvalidRate},
+		{in: "abc", err: ErrInvalidRate},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseRate(%q) error = %v, want %v", tt.in, err, tt.err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRate(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRateString(t *testing.T) {
+	tests := []struct {
+		in   Rate
+		want string
+	}{
+		{in: 100000000, want: "1"},
+		{in: 9250000000, want: "92.5"},
+		{in: 1085071, want: "0.01085071"},
+		{in: 1, want: "0.00000001"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Rate(%d).String() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRateJSON(t *testing.T) {
+	var req struct {
+		Rate Rate `json:"rate"`
+	}
+	for _, body := range []string{`{"rate":"0.01085071"}`, `{"rate":0.01085071}`} {
+		if err := json.Unmarshal([]byte(body), &req); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", body, err)
+		}
+		if req.Rate != 1085071 {
+			t.Errorf("Unmarshal(%s) = %d, want 1085071", body, req.Rate)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`{"rate":"0.000000001"}`), &req); !errors.Is(err, ErrRateTooPrecise) {
+		t.Errorf("Unmarshal лишней точности: %v, want ErrRateTooPrecise", err)
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(data) != `{"rate":"0.01085071"}` {
+		t.Errorf("Marshal = %s", data)
+	}
+}
+
+func TestRateScan(t *testing.T) {
+	tests := []struct {
+		src  interface{}
+		want Rate
+	}{
+		{src: []byte("92.50000000"), want: 9250000000},
+		{src: "0.01085071", want: 1085071},
+		{src: int64(3), want: 300000000},
+		{src: nil, want: 0},
+	}
+	for _, tt := range tests {
+		var r Rate
+		if err := r.Scan(tt.src); err != nil {
+			t.Errorf("Scan(%v): %v", tt.src, err)
+			continue
+		}
+		if r != tt.want {
+			t.Errorf("Scan(%v) = %d, want %d", tt.src, r, tt.want)
+		}
+	}
+}
+
+func TestRateInverse(t *testing.T) {
+	rate, _ := ParseRate("3")
+	want := big.NewRat(1, 3)
+	if got := rate.Inverse(); got.Cmp(want) != 0 {
+		t.Errorf("Inverse(3) = %s, want %s", got, want)
+	}
+	if Rate(0).Inverse() != nil {
+		t.Error("Inverse(0) должен быть nil")
+	}
+}
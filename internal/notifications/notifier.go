@@ -0,0 +1,62 @@
+package notifications
+
+import (
+	"cashcontrol/internal/config"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// webhookTimeout ограничивает время ожидания ответа webhook
+const webhookTimeout = 10 * time.Second
+
+// Notification уведомление пользователю; каждый канал доставляет его, только если для него указан получатель
+type Notification struct {
+	Subject    string      // Тема уведомления
+	Text       string      // Текст уведомления
+	Email      string      // Адрес почты получателя
+	WebhookURL string      // Адрес webhook получателя
+	Payload    interface{} // Структурированные данные для webhook
+}
+
+// Notifier канал доставки уведомлений
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+type multiNotifier struct {
+	notifiers []Notifier
+}
+
+// NewMultiNotifier объединяет несколько каналов, уведомление отправляется во все
+func NewMultiNotifier(notifiers ...Notifier) Notifier {
+	return &multiNotifier{notifiers: notifiers}
+}
+
+func (m *multiNotifier) Notify(ctx context.Context, n Notification) error {
+	var errs []error
+	for _, notifier := range m.notifiers {
+		if err := notifier.Notify(ctx, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FromConfig собирает каналы доставки: webhook доступен всегда, почта — если задан SMTP_HOST
+func FromConfig(cfg *config.Config, logger *slog.Logger) Notifier {
+	notifiers := []Notifier{
+		NewWebhookNotifier(NewWebhookClient(webhookTimeout, IsPublicAddr), logger),
+	}
+	if cfg.SMTPHost != "" {
+		notifiers = append(notifiers, NewSMTPNotifier(SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		}, logger))
+	}
+	return NewMultiNotifier(notifiers...)
+}
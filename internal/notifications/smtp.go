@@ -0,0 +1,67 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPConfig параметры подключения к почтовому серверу
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type smtpNotifier struct {
+	cfg    SMTPConfig
+	logger *slog.Logger
+}
+
+// NewSMTPNotifier отправляет уведомления письмом через SMTP; без логина авторизация не выполняется
+func NewSMTPNotifier(cfg SMTPConfig, logger *slog.Logger) Notifier {
+	return &smtpNotifier{cfg: cfg, logger: logger}
+}
+
+func (s *smtpNotifier) Notify(ctx context.Context, n Notification) error {
+	if n.Email == "" {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	if err := smtp.SendMail(addr, auth, s.cfg.From, []string{n.Email}, s.buildMessage(n)); err != nil {
+		return fmt.Errorf("smtp: отправка письма: %w", err)
+	}
+
+	s.logger.Debug("email notification sent",
+		slog.String("subject", n.Subject),
+	)
+	return nil
+}
+
+func (s *smtpNotifier) buildMessage(n Notification) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + s.cfg.From + "\r\n")
+	b.WriteString("To: " + n.Email + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", n.Subject) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(n.Text, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
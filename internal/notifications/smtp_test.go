@@ -0,0 +1,142 @@
+package notifications
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+)
+
+// fakeSMTPServer минимальный SMTP сервер: принимает одно письмо и запоминает конверт и данные
+type fakeSMTPServer struct {
+	listener   net.Listener
+	rejectRcpt bool
+
+	from string
+	rcpt []string
+	data string
+	done chan struct{}
+}
+
+func startFakeSMTPServer(t *testing.T, rejectRcpt bool) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeSMTPServer{listener: listener, rejectRcpt: rejectRcpt, done: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		switch upper := strings.ToUpper(cmd); {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			s.from = strings.Trim(cmd[len("MAIL FROM:"):], "<>")
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			if s.rejectRcpt {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			s.rcpt = append(s.rcpt, strings.Trim(cmd[len("RCPT TO:"):], "<>"))
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.data = data.String()
+			reply("250 OK")
+		case upper == "RSET", upper == "NOOP":
+			reply("250 OK")
+		case upper == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) config() SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return SMTPConfig{Host: host, Port: port, From: "alerts@cashcontrol.local"}
+}
+
+func TestSMTPNotifierSendsMessage(t *testing.T) {
+	server := startFakeSMTPServer(t, false)
+
+	notifier := NewSMTPNotifier(server.config(), testLogger())
+	err := notifier.Notify(context.Background(), Notification{
+		Subject: "Бюджет превышен",
+		Text:    "Потрачено 1200 из 1000\nПроверьте расходы",
+		Email:   "user@example.com",
+	})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	<-server.done
+
+	if server.from != "alerts@cashcontrol.local" {
+		t.Errorf("MAIL FROM = %q", server.from)
+	}
+	if len(server.rcpt) != 1 || server.rcpt[0] != "user@example.com" {
+		t.Errorf("RCPT TO = %v", server.rcpt)
+	}
+	for _, want := range []string{
+		"To: user@example.com\r\n",
+		"Subject: =?utf-8?q?",
+		"Content-Type: text/plain; charset=utf-8\r\n",
+		"Потрачено 1200 из 1000\r\nПроверьте расходы\r\n",
+	} {
+		if !strings.Contains(server.data, want) {
+			t.Errorf("письмо не содержит %q:\n%s", want, server.data)
+		}
+	}
+}
+
+func TestSMTPNotifierRejectedRecipient(t *testing.T) {
+	server := startFakeSMTPServer(t, true)
+
+	notifier := NewSMTPNotifier(server.config(), testLogger())
+	err := notifier.Notify(context.Background(), Notification{Subject: "s", Text: "t", Email: "user@example.com"})
+	if err == nil || !strings.Contains(err.Error(), "550") {
+		t.Fatalf("Notify: %v, want ошибку 550", err)
+	}
+}
+
+func TestSMTPNotifierSkipsEmptyEmail(t *testing.T) {
+	notifier := NewSMTPNotifier(SMTPConfig{Host: "127.0.0.1", Port: "1"}, testLogger())
+	if err := notifier.Notify(context.Background(), Notification{Subject: "s"}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+}
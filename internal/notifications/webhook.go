@@ -0,0 +1,132 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrInvalidWebhookURL возвращается для адреса webhook, на который сервер не будет отправлять запросы
+	ErrInvalidWebhookURL = errors.New("адрес webhook должен быть https адресом во внешней сети")
+
+	errForbiddenWebhookAddress = errors.New("webhook: адрес во внутренней сети запрещен")
+)
+
+// sharedAddressSpace диапазон адресов операторов связи (RFC 6598), из интернета он недоступен так же, как частные сети
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+type webhookNotifier struct {
+	client *http.Client
+	logger *slog.Logger
+}
+
+// NewWebhookNotifier отправляет уведомления POST запросом с JSON телом на адрес получателя
+func NewWebhookNotifier(client *http.Client, logger *slog.Logger) Notifier {
+	return &webhookNotifier{client: client, logger: logger}
+}
+
+// NewWebhookClient возвращает HTTP клиент для webhook, который соединяется только с адресами,
+// разрешенными allowAddr, и не следует перенаправлениям. Адрес проверяется после разрешения имени,
+// поэтому DNS запись, указывающая на запрещенный адрес, тоже отклоняется.
+func NewWebhookClient(timeout time.Duration, allowAddr func(netip.Addr) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addr, err := netip.ParseAddrPort(address)
+			if err != nil || !allowAddr(addr.Addr()) {
+				return errForbiddenWebhookAddress
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		// Прокси из окружения не используется: иначе проверялся бы адрес прокси, а не получателя
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// ValidateWebhookURL проверяет, что адрес webhook использует https и не указывает на адрес во внутренней сети.
+// Вызывается при сохранении настроек; имена хостов окончательно проверяются при соединении.
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.Hostname() == "localhost" || u.User != nil {
+		return ErrInvalidWebhookURL
+	}
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil && !IsPublicAddr(addr) {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+// IsPublicAddr сообщает, доступен ли адрес из интернета: loopback, частные, link-local,
+// multicast и неуказанные адреса считаются внутренними
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+type webhookBody struct {
+	Subject string      `json:"subject"`
+	Text    string      `json:"text"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (w *webhookNotifier) Notify(ctx context.Context, n Notification) error {
+	if n.WebhookURL == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(webhookBody{Subject: n.Subject, Text: n.Text, Data: n.Payload})
+	if err != nil {
+		return fmt.Errorf("webhook: сериализация уведомления: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: создание запроса: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: отправка запроса: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: неожиданный статус ответа %d", resp.StatusCode)
+	}
+
+	w.logger.Debug("webhook notification sent",
+		slog.String("subject", n.Subject),
+		slog.Int("status", resp.StatusCode),
+	)
+	return nil
+}
@@ -0,0 +1,114 @@
+package rates
+
+import (
+	"cashcontrol/internal/money"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// dateLayout формат даты в файле курсов
+const dateLayout = "2006-01-02"
+
+type csvProvider struct {
+	name string
+	open func() (io.ReadCloser, error)
+}
+
+// NewCSVProvider читает курсы из CSV с колонками date,from,to,rate; строка заголовка необязательна.
+// Разделителем может быть запятая или точка с запятой, дробная часть курса — через точку или запятую.
+func NewCSVProvider(name string, r io.Reader) Provider {
+	return &csvProvider{
+		name: name,
+		open: func() (io.ReadCloser, error) { return io.NopCloser(r), nil },
+	}
+}
+
+// NewFileProvider читает курсы из CSV файла при каждом вызове Fetch
+func NewFileProvider(path string) Provider {
+	return &csvProvider{
+		name: path,
+		open: func() (io.ReadCloser, error) { return os.Open(path) },
+	}
+}
+
+func (p *csvProvider) Name() string {
+	return p.name
+}
+
+func (p *csvProvider) Fetch(ctx context.Context) ([]Rate, error) {
+	rc, err := p.open()
+	if err != nil {
+		return nil, fmt.Errorf("курсы %s: %w", p.name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("курсы %s: %w", p.name, err)
+	}
+
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.Comma = detectDelimiter(string(data))
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	var result []Rate
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("курсы %s: %w", p.name, err)
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "date") {
+			continue
+		}
+
+		rate, err := parseRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("курсы %s, строка %d: %w", p.name, line, err)
+		}
+		result = append(result, rate)
+	}
+
+	return result, nil
+}
+
+func parseRecord(record []string) (Rate, error) {
+	date, err := time.Parse(dateLayout, strings.TrimSpace(record[0]))
+	if err != nil {
+		return Rate{}, fmt.Errorf("некорректная дата %q, ожидается ГГГГ-ММ-ДД", record[0])
+	}
+
+	value, err := money.ParseRate(record[3])
+	if err != nil || value <= 0 {
+		return Rate{}, fmt.Errorf("некорректный курс %q", record[3])
+	}
+
+	return Rate{
+		Date:  date,
+		From:  strings.ToUpper(strings.TrimSpace(record[1])),
+		To:    strings.ToUpper(strings.TrimSpace(record[2])),
+		Value: value,
+	}, nil
+}
+
+// detectDelimiter выбирает точку с запятой, если она встречается в первой строке
+func detectDelimiter(data string) rune {
+	first, _, _ := strings.Cut(data, "\n")
+	if strings.Contains(first, ";") {
+		return ';'
+	}
+	return ','
+}
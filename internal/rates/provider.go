@@ -0,0 +1,21 @@
+package rates
+
+import (
+	"cashcontrol/internal/money"
+	"context"
+	"time"
+)
+
+// Rate курс обмена: одна единица From стоит Value единиц To начиная с Date
+type Rate struct {
+	Date  time.Time  // Дата, с которой действует курс
+	From  string     // Исходная валюта
+	To    string     // Целевая валюта
+	Value money.Rate // Курс
+}
+
+// Provider источник курсов валют для импорта в таблицу курсов
+type Provider interface {
+	Name() string
+	Fetch(ctx context.Context) ([]Rate, error)
+}
@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errAccountNil  error = errors.New("account is nil")
+	errTransferNil error = errors.New("transfer is nil")
+)
+
+type AccountRepository interface {
+	GetByID(id uint) (*models.Account, error)
+	GetByUserID(userID uint) ([]models.Account, error)
+	Create(account *models.Account) error
+	Update(account *models.Account) error
+	Delete(id uint) error
+	CountOperations(id uint) (int64, error)
+
+	ListTransfers(filter models.TransferFilter) ([]models.Transfer, error)
+	GetTransferByID(id uint) (*models.Transfer, error)
+	CreateTransfer(transfer *models.Transfer) error
+	DeleteTransfer(id uint) error
+}
+
+type gormAccountRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewAccountRepository(db *gorm.DB, logger *slog.Logger) AccountRepository {
+	return &gormAccountRepository{db: db, logger: logger}
+}
+
+func (r *gormAccountRepository) GetByID(id uint) (*models.Account, error) {
+	r.logger.Debug("repo.account.get_by_id",
+		slog.String("op", "repo.account.get_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var account models.Account
+	if err := r.db.First(&account, id).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("repo.account.get_by_id failed",
+				slog.String("op", "repo.account.get_by_id"),
+				slog.Uint64("id", uint64(id)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *gormAccountRepository) GetByUserID(userID uint) ([]models.Account, error) {
+	r.logger.Debug("repo.account.get_by_user_id",
+		slog.String("op", "repo.account.get_by_user_id"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+	var accounts []models.Account
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&accounts).Error; err != nil {
+		r.logger.Error("repo.account.get_by_user_id failed",
+			slog.String("op", "repo.account.get_by_user_id"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *gormAccountRepository) Create(account *models.Account) error {
+	if account == nil {
+		return errAccountNil
+	}
+	r.logger.Debug("repo.account.create",
+		slog.String("op", "repo.account.create"),
+		slog.Uint64("user_id", uint64(account.UserID)),
+	)
+	if err := r.db.Create(account).Error; err != nil {
+		r.logger.Error("repo.account.create failed",
+			slog.String("op", "repo.account.create"),
+			slog.Uint64("user_id", uint64(account.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// Update сохраняет название и тип счета. Остаток меняется только операциями, поэтому не перезаписывается.
+func (r *gormAccountRepository) Update(account *models.Account) error {
+	if account == nil {
+		return errAccountNil
+	}
+	r.logger.Debug("repo.account.update",
+		slog.String("op", "repo.account.update"),
+		slog.Uint64("id", uint64(account.ID)),
+	)
+	if err := r.db.Model(account).Select("name", "type").Updates(account).Error; err != nil {
+		r.logger.Error("repo.account.update failed",
+			slog.String("op", "repo.account.update"),
+			slog.Uint64("id", uint64(account.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormAccountRepository) Delete(id uint) error {
+	r.logger.Debug("repo.account.delete",
+		slog.String("op", "repo.account.delete"),
+		slog.Uint64("id", uint64(id)),
+	)
+	if err := r.db.Delete(&models.Account{}, id).Error; err != nil {
+		r.logger.Error("repo.account.delete failed",
+			slog.String("op", "repo.account.delete"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// CountOperations возвращает число расходов, доходов, регулярных расходов и переводов, привязанных к счету
+func (r *gormAccountRepository) CountOperations(id uint) (int64, error) {
+	r.logger.Debug("repo.account.count_operations",
+		slog.String("op", "repo.account.count_operations"),
+		slog.Uint64("id", uint64(id)),
+	)
+
+	var total int64
+	for _, q := range []*gorm.DB{
+		r.db.Model(&models.Expense{}).Where("account_id = ?", id),
+		r.db.Model(&models.Income{}).Where("account_id = ?", id),
+		r.db.Model(&models.RecurringExpense{}).Where("account_id = ?", id),
+		r.db.Model(&models.Transfer{}).Where("from_account_id = ? OR to_account_id = ?", id, id),
+	} {
+		var count int64
+		if err := q.Count(&count).Error; err != nil {
+			r.logger.Error("repo.account.count_operations failed",
+				slog.String("op", "repo.account.count_operations"),
+				slog.Uint64("id", uint64(id)),
+				slog.String("error", err.Error()),
+			)
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+func (r *gormAccountRepository) ListTransfers(filter models.TransferFilter) ([]models.Transfer, error) {
+	r.logger.Debug("repo.account.list_transfers",
+		slog.String("op", "repo.account.list_transfers"),
+		slog.Uint64("user_id", uint64(filter.UserID)),
+	)
+
+	query := r.db.Model(&models.Transfer{}).Where("user_id = ?", filter.UserID)
+	if filter.AccountID != nil {
+		query = query.Where("from_account_id = ? OR to_account_id = ?", *filter.AccountID, *filter.AccountID)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("date <= ?", *filter.EndDate)
+	}
+	if filter.Limit != nil {
+		query = query.Limit(*filter.Limit)
+	}
+	if filter.Offset != nil {
+		query = query.Offset(*filter.Offset)
+	}
+
+	var transfers []models.Transfer
+	if err := query.Order("date DESC, id DESC").Find(&transfers).Error; err != nil {
+		r.logger.Error("repo.account.list_transfers failed",
+			slog.String("op", "repo.account.list_transfers"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return transfers, nil
+}
+
+func (r *gormAccountRepository) GetTransferByID(id uint) (*models.Transfer, error) {
+	r.logger.Debug("repo.account.get_transfer_by_id",
+		slog.String("op", "repo.account.get_transfer_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var transfer models.Transfer
+	if err := r.db.First(&transfer, id).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("repo.account.get_transfer_by_id failed",
+				slog.String("op", "repo.account.get_transfer_by_id"),
+				slog.Uint64("id", uint64(id)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// CreateTransfer в одной транзакции сохраняет перевод, списывает сумму со счета списания и зачисляет на счет зачисления
+func (r *gormAccountRepository) CreateTransfer(transfer *models.Transfer) error {
+	if transfer == nil {
+		return errTransferNil
+	}
+	r.logger.Debug("repo.account.create_transfer",
+		slog.String("op", "repo.account.create_transfer"),
+		slog.Uint64("from_account_id", uint64(transfer.FromAccountID)),
+		slog.Uint64("to_account_id", uint64(transfer.ToAccountID)),
+		slog.String("amount", transfer.Amount.String()),
+	)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(transfer).Error; err != nil {
+			return err
+		}
+		if err := adjustAccountBalance(tx, &transfer.FromAccountID, -transfer.Amount); err != nil {
+			return err
+		}
+		return adjustAccountBalance(tx, &transfer.ToAccountID, transfer.ToAmount)
+	})
+	if err != nil {
+		r.logger.Error("repo.account.create_transfer failed",
+			slog.String("op", "repo.account.create_transfer"),
+			slog.Uint64("from_account_id", uint64(transfer.FromAccountID)),
+			slog.Uint64("to_account_id", uint64(transfer.ToAccountID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// DeleteTransfer в одной транзакции удаляет перевод и возвращает суммы на счета
+func (r *gormAccountRepository) DeleteTransfer(id uint) error {
+	r.logger.Debug("repo.account.delete_transfer",
+		slog.String("op", "repo.account.delete_transfer"),
+		slog.Uint64("id", uint64(id)),
+	)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var transfer models.Transfer
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transfer, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&transfer).Error; err != nil {
+			return err
+		}
+		if err := adjustAccountBalance(tx, &transfer.FromAccountID, transfer.Amount); err != nil {
+			return err
+		}
+		return adjustAccountBalance(tx, &transfer.ToAccountID, -transfer.ToAmount)
+	})
+	if err != nil {
+		r.logger.Error("repo.account.delete_transfer failed",
+			slog.String("op", "repo.account.delete_transfer"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// adjustAccountBalance атомарно меняет остаток счета на delta внутри транзакции tx.
+// Операция без счета (accountID == nil) остаток не затрагивает.
+func adjustAccountBalance(tx *gorm.DB, accountID *uint, delta money.Amount) error {
+	if accountID == nil || delta == 0 {
+		return nil
+	}
+	res := tx.Model(&models.Account{}).
+		Where("id = ?", *accountID).
+		Update("balance", gorm.Expr("balance + ?", delta))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -11,7 +11,7 @@ import (
 var errActivityLogNil = errors.New("activity log is nil")
 
 type ActivityLogRepository interface {
-	Get(filter models.ActivityFilter) ([]models.ActivityHistory, error)
+	Get(filter models.ActivityFilter, page models.PageRequest) (*models.Page[models.ActivityHistory], error)
 	Create(logEntry *models.ActivityHistory) error
 }
 
@@ -43,7 +43,7 @@ func (r *activityLogRepository) Create(logEntry *models.ActivityHistory) error {
 	)
 
 	// Создание записи
-	if err := r.db.Create(&logEntry).Error; err != nil {
+	if err := r.db.Create(logEntry).Error; err != nil {
 		r.logger.Error("failed to create activity log",
 			slog.String("op", op),
 			slog.Uint64("user_id", uint64(logEntry.UserID)),
@@ -67,8 +67,15 @@ func (r *activityLogRepository) Create(logEntry *models.ActivityHistory) error {
 	return nil
 }
 
-// Get возвращает список записей по фильтру
-func (r *activityLogRepository) Get(filter models.ActivityFilter) ([]models.ActivityHistory, error) {
+var activityLogKeyset = keyset[models.ActivityHistory]{
+	id: func(a *models.ActivityHistory) uint { return a.ID },
+	sorts: map[string]sortKey[models.ActivityHistory]{
+		models.SortByCreatedAt: {expr: "created_at", value: func(a *models.ActivityHistory) interface{} { return a.CreatedAt }},
+	},
+}
+
+// Get возвращает страницу записей по фильтру
+func (r *activityLogRepository) Get(filter models.ActivityFilter, page models.PageRequest) (*models.Page[models.ActivityHistory], error) {
 	const op = "repo.activity_log.get"
 
 	r.logger.Debug("retrieving activity logs",
@@ -100,7 +107,6 @@ func (r *activityLogRepository) Get(filter models.ActivityFilter) ([]models.Acti
 		}()),
 	)
 
-	var logs []models.ActivityHistory
 	query := r.db.Model(&models.ActivityHistory{}).Where("user_id = ?", filter.UserID)
 
 	if filter.ActivityType != nil {
@@ -116,25 +122,22 @@ func (r *activityLogRepository) Get(filter models.ActivityFilter) ([]models.Acti
 		query = query.Where("created_at <= ?", *filter.EndDate)
 	}
 
-	if filter.Limit != nil {
-		query = query.Limit(*filter.Limit)
-	}
-	if filter.Offset != nil {
-		query = query.Offset(*filter.Offset)
-	}
-
-	if err := query.Order("created_at DESC").Find(&logs).Error; err != nil {
-		r.logger.Error("failed to retrieve activity logs",
-			slog.String("op", op),
-			slog.Uint64("user_id", uint64(filter.UserID)),
-			slog.String("error", err.Error()),
-		)
+	logs, err := activityLogKeyset.page(query, page)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidCursor) {
+			r.logger.Error("failed to retrieve activity logs",
+				slog.String("op", op),
+				slog.Uint64("user_id", uint64(filter.UserID)),
+				slog.String("error", err.Error()),
+			)
+		}
 		return nil, err
 	}
 
 	r.logger.Debug("retrieved activity logs",
 		slog.String("op", op),
-		slog.Int("count", len(logs)),
+		slog.Int("count", len(logs.Items)),
+		slog.Int64("total", logs.Total),
 	)
 
 	return logs, nil
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errAlertSettingsNil = errors.New("alert settings is nil")
+	errBudgetAlertNil   = errors.New("budget alert is nil")
+)
+
+type BudgetAlertRepository interface {
+	GetSettings(userID uint) (*models.AlertSettings, error)
+	SaveSettings(settings *models.AlertSettings) error
+	CreateIfAbsent(alert *models.BudgetAlert) (bool, error)
+	ListByUserID(userID uint) ([]models.BudgetAlert, error)
+}
+
+type gormBudgetAlertRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewBudgetAlertRepository(db *gorm.DB, logger *slog.Logger) BudgetAlertRepository {
+	return &gormBudgetAlertRepository{db: db, logger: logger}
+}
+
+func (r *gormBudgetAlertRepository) GetSettings(userID uint) (*models.AlertSettings, error) {
+	r.logger.Debug("repo.budget_alert.get_settings",
+		slog.String("op", "repo.budget_alert.get_settings"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+	var settings models.AlertSettings
+	if err := r.db.Where("user_id = ?", userID).First(&settings).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("repo.budget_alert.get_settings failed",
+				slog.String("op", "repo.budget_alert.get_settings"),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (r *gormBudgetAlertRepository) SaveSettings(settings *models.AlertSettings) error {
+	if settings == nil {
+		return errAlertSettingsNil
+	}
+	r.logger.Debug("repo.budget_alert.save_settings",
+		slog.String("op", "repo.budget_alert.save_settings"),
+		slog.Uint64("user_id", uint64(settings.UserID)),
+	)
+	if err := r.db.Save(settings).Error; err != nil {
+		r.logger.Error("repo.budget_alert.save_settings failed",
+			slog.String("op", "repo.budget_alert.save_settings"),
+			slog.Uint64("user_id", uint64(settings.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// CreateIfAbsent сохраняет уведомление, если для бюджета и порога оно еще не создавалось.
+// Возвращает false, если такое уведомление уже есть.
+func (r *gormBudgetAlertRepository) CreateIfAbsent(alert *models.BudgetAlert) (bool, error) {
+	if alert == nil {
+		return false, errBudgetAlertNil
+	}
+	r.logger.Debug("repo.budget_alert.create_if_absent",
+		slog.String("op", "repo.budget_alert.create_if_absent"),
+		slog.Uint64("budget_id", uint64(alert.BudgetID)),
+		slog.Int("threshold", alert.Threshold),
+	)
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(alert)
+	if res.Error != nil {
+		r.logger.Error("repo.budget_alert.create_if_absent failed",
+			slog.String("op", "repo.budget_alert.create_if_absent"),
+			slog.Uint64("budget_id", uint64(alert.BudgetID)),
+			slog.Int("threshold", alert.Threshold),
+			slog.String("error", res.Error.Error()),
+		)
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *gormBudgetAlertRepository) ListByUserID(userID uint) ([]models.BudgetAlert, error) {
+	r.logger.Debug("repo.budget_alert.list_by_user_id",
+		slog.String("op", "repo.budget_alert.list_by_user_id"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+	var alerts []models.BudgetAlert
+	if err := r.db.Where("user_id = ?", userID).Order("triggered_at DESC").Find(&alerts).Error; err != nil {
+		r.logger.Error("repo.budget_alert.list_by_user_id failed",
+			slog.String("op", "repo.budget_alert.list_by_user_id"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return alerts, nil
+}
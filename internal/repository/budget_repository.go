@@ -13,8 +13,10 @@ var errBudgetNil error = errors.New("budget is nil")
 type BudgetRepository interface {
 	List() ([]models.Budget, error)
 	GetByID(id uint) (*models.Budget, error)
-	GetByUserIDAndMonth(userID uint, month, year int) (*models.Budget, error)
-	GetByUserID(userID uint) ([]models.Budget, error)
+	GetByUserIDAndMonth(userID uint, month, year int, categoryID *uint) (*models.Budget, error)
+	ListByUserIDAndMonth(userID uint, month, year int) ([]models.Budget, error)
+	ListPrevious(userID uint, categoryID *uint, month, year, limit int) ([]models.Budget, error)
+	ListPage(userID uint, page models.PageRequest) (*models.Page[models.Budget], error)
 	Create(budget *models.Budget) error
 	Update(budget *models.Budget) error
 	Delete(id uint) error
@@ -61,17 +63,52 @@ func (r *gormBudgetRepository) GetByID(id uint) (*models.Budget, error) {
 	return &budget, nil
 }
 
-func (r *gormBudgetRepository) GetByUserIDAndMonth(userID uint, month, year int) (*models.Budget, error) {
+// GetByUserIDAndMonth возвращает бюджет категории за месяц или общий бюджет, если categoryID не указан
+func (r *gormBudgetRepository) GetByUserIDAndMonth(userID uint, month, year int, categoryID *uint) (*models.Budget, error) {
 	r.logger.Debug("repo.budget.get_by_user_id_and_month",
 		slog.String("op", "repo.budget.get_by_user_id_and_month"),
 		slog.Uint64("user_id", uint64(userID)),
 		slog.Int("month", month),
 		slog.Int("year", year),
 	)
+	query := r.db.Where("user_id = ? AND month = ? AND year = ?", userID, month, year)
+	if categoryID != nil {
+		query = query.Where("category_id = ?", *categoryID)
+	} else {
+		query = query.Where("category_id IS NULL")
+	}
+
 	var budget models.Budget
-	if err := r.db.Where("user_id = ? AND month = ? AND year = ?", userID, month, year).First(&budget).Error; err != nil {
-		r.logger.Error("repo.budget.get_by_user_id_and_month failed",
-			slog.String("op", "repo.budget.get_by_user_id_and_month"),
+	if err := query.First(&budget).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("repo.budget.get_by_user_id_and_month failed",
+				slog.String("op", "repo.budget.get_by_user_id_and_month"),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.Int("month", month),
+				slog.Int("year", year),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return &budget, nil
+}
+
+// ListByUserIDAndMonth возвращает общий бюджет и бюджеты категорий за месяц
+func (r *gormBudgetRepository) ListByUserIDAndMonth(userID uint, month, year int) ([]models.Budget, error) {
+	r.logger.Debug("repo.budget.list_by_user_id_and_month",
+		slog.String("op", "repo.budget.list_by_user_id_and_month"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("month", month),
+		slog.Int("year", year),
+	)
+	var budgets []models.Budget
+	err := r.db.Where("user_id = ? AND month = ? AND year = ?", userID, month, year).
+		Order("category_id NULLS FIRST").
+		Find(&budgets).Error
+	if err != nil {
+		r.logger.Error("repo.budget.list_by_user_id_and_month failed",
+			slog.String("op", "repo.budget.list_by_user_id_and_month"),
 			slog.Uint64("user_id", uint64(userID)),
 			slog.Int("month", month),
 			slog.Int("year", year),
@@ -79,19 +116,31 @@ func (r *gormBudgetRepository) GetByUserIDAndMonth(userID uint, month, year int)
 		)
 		return nil, err
 	}
-	return &budget, nil
+	return budgets, nil
 }
 
-func (r *gormBudgetRepository) GetByUserID(userID uint) ([]models.Budget, error) {
-	r.logger.Debug("repo.budget.get_by_user_id",
-		slog.String("op", "repo.budget.get_by_user_id"),
+// ListPrevious возвращает бюджеты той же категории (или общие) за месяцы до указанного, от новых к старым
+func (r *gormBudgetRepository) ListPrevious(userID uint, categoryID *uint, month, year, limit int) ([]models.Budget, error) {
+	r.logger.Debug("repo.budget.list_previous",
+		slog.String("op", "repo.budget.list_previous"),
 		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("month", month),
+		slog.Int("year", year),
 	)
+	query := r.db.Where("user_id = ? AND (year < ? OR (year = ? AND month < ?))", userID, year, year, month)
+	if categoryID != nil {
+		query = query.Where("category_id = ?", *categoryID)
+	} else {
+		query = query.Where("category_id IS NULL")
+	}
+
 	var budgets []models.Budget
-	if err := r.db.Where("user_id = ?", userID).Order("year DESC, month DESC").Find(&budgets).Error; err != nil {
-		r.logger.Error("repo.budget.get_by_user_id failed",
-			slog.String("op", "repo.budget.get_by_user_id"),
+	if err := query.Order("year DESC, month DESC").Limit(limit).Find(&budgets).Error; err != nil {
+		r.logger.Error("repo.budget.list_previous failed",
+			slog.String("op", "repo.budget.list_previous"),
 			slog.Uint64("user_id", uint64(userID)),
+			slog.Int("month", month),
+			slog.Int("year", year),
 			slog.String("error", err.Error()),
 		)
 		return nil, err
@@ -99,6 +148,36 @@ func (r *gormBudgetRepository) GetByUserID(userID uint) ([]models.Budget, error)
 	return budgets, nil
 }
 
+var budgetKeyset = keyset[models.Budget]{
+	id: func(b *models.Budget) uint { return b.ID },
+	sorts: map[string]sortKey[models.Budget]{
+		models.SortByPeriod:    {expr: "year * 100 + month", value: func(b *models.Budget) interface{} { return b.Year*100 + b.Month }},
+		models.SortByAmount:    {expr: "amount", value: func(b *models.Budget) interface{} { return b.Amount }},
+		models.SortByCreatedAt: {expr: "created_at", value: func(b *models.Budget) interface{} { return b.CreatedAt }},
+	},
+}
+
+// ListPage возвращает страницу бюджетов пользователя в порядке page
+func (r *gormBudgetRepository) ListPage(userID uint, page models.PageRequest) (*models.Page[models.Budget], error) {
+	r.logger.Debug("repo.budget.list_page",
+		slog.String("op", "repo.budget.list_page"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("sort", page.SortBy),
+	)
+	result, err := budgetKeyset.page(r.db.Model(&models.Budget{}).Where("user_id = ?", userID), page)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidCursor) {
+			r.logger.Error("repo.budget.list_page failed",
+				slog.String("op", "repo.budget.list_page"),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
 func (r *gormBudgetRepository) Create(budget *models.Budget) error {
 	if budget == nil {
 		return errBudgetNil
@@ -107,7 +186,7 @@ func (r *gormBudgetRepository) Create(budget *models.Budget) error {
 	r.logger.Debug("repo.budget.create",
 		slog.String("op", "repo.budget.create"),
 		slog.Uint64("user_id", uint64(budget.UserID)),
-		slog.Float64("amount", budget.Amount),
+		slog.String("amount", budget.Amount.String()),
 		slog.Int("month", budget.Month),
 		slog.Int("year", budget.Year),
 	)
@@ -116,7 +195,7 @@ func (r *gormBudgetRepository) Create(budget *models.Budget) error {
 		r.logger.Error("repo.budget.create failed",
 			slog.String("op", "repo.budget.create"),
 			slog.Uint64("user_id", uint64(budget.UserID)),
-			slog.Float64("amount", budget.Amount),
+			slog.String("amount", budget.Amount.String()),
 			slog.Int("month", budget.Month),
 			slog.Int("year", budget.Year),
 			slog.String("error", err.Error()),
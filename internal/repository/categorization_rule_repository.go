@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var errCategorizationRuleNil error = errors.New("categorization rule is nil")
+
+type CategorizationRuleRepository interface {
+	GetByID(id uint) (*models.CategorizationRule, error)
+	GetByUserID(userID uint) ([]models.CategorizationRule, error)
+	GetActiveByUserID(userID uint) ([]models.CategorizationRule, error)
+	Create(rule *models.CategorizationRule) error
+	Update(rule *models.CategorizationRule) error
+	Delete(id uint) error
+}
+
+type gormCategorizationRuleRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewCategorizationRuleRepository(db *gorm.DB, logger *slog.Logger) CategorizationRuleRepository {
+	return &gormCategorizationRuleRepository{db: db, logger: logger}
+}
+
+func (r *gormCategorizationRuleRepository) GetByID(id uint) (*models.CategorizationRule, error) {
+	r.logger.Debug("repo.categorization_rule.get_by_id",
+		slog.String("op", "repo.categorization_rule.get_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var rule models.CategorizationRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("repo.categorization_rule.get_by_id failed",
+				slog.String("op", "repo.categorization_rule.get_by_id"),
+				slog.Uint64("id", uint64(id)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetByUserID возвращает все правила пользователя в порядке проверки
+func (r *gormCategorizationRuleRepository) GetByUserID(userID uint) ([]models.CategorizationRule, error) {
+	r.logger.Debug("repo.categorization_rule.get_by_user_id",
+		slog.String("op", "repo.categorization_rule.get_by_user_id"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+	var rules []models.CategorizationRule
+	if err := r.db.Where("user_id = ?", userID).Order("priority, id").Find(&rules).Error; err != nil {
+		r.logger.Error("repo.categorization_rule.get_by_user_id failed",
+			slog.String("op", "repo.categorization_rule.get_by_user_id"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetActiveByUserID возвращает активные правила пользователя в порядке проверки.
+// Правила, чья категория удалена, пропускаются, чтобы расходы не попадали в удаленную категорию.
+func (r *gormCategorizationRuleRepository) GetActiveByUserID(userID uint) ([]models.CategorizationRule, error) {
+	r.logger.Debug("repo.categorization_rule.get_active_by_user_id",
+		slog.String("op", "repo.categorization_rule.get_active_by_user_id"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+	var rules []models.CategorizationRule
+	err := r.db.
+		Joins("JOIN categories ON categories.id = categorization_rules.category_id AND categories.deleted_at IS NULL").
+		Where("categorization_rules.user_id = ? AND categorization_rules.is_active", userID).
+		Order("categorization_rules.priority, categorization_rules.id").
+		Find(&rules).Error
+	if err != nil {
+		r.logger.Error("repo.categorization_rule.get_active_by_user_id failed",
+			slog.String("op", "repo.categorization_rule.get_active_by_user_id"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *gormCategorizationRuleRepository) Create(rule *models.CategorizationRule) error {
+	if rule == nil {
+		return errCategorizationRuleNil
+	}
+	r.logger.Debug("repo.categorization_rule.create",
+		slog.String("op", "repo.categorization_rule.create"),
+		slog.Uint64("user_id", uint64(rule.UserID)),
+	)
+	if err := r.db.Create(rule).Error; err != nil {
+		r.logger.Error("repo.categorization_rule.create failed",
+			slog.String("op", "repo.categorization_rule.create"),
+			slog.Uint64("user_id", uint64(rule.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormCategorizationRuleRepository) Update(rule *models.CategorizationRule) error {
+	if rule == nil {
+		return errCategorizationRuleNil
+	}
+	r.logger.Debug("repo.categorization_rule.update",
+		slog.String("op", "repo.categorization_rule.update"),
+		slog.Uint64("id", uint64(rule.ID)),
+	)
+	if err := r.db.Save(rule).Error; err != nil {
+		r.logger.Error("repo.categorization_rule.update failed",
+			slog.String("op", "repo.categorization_rule.update"),
+			slog.Uint64("id", uint64(rule.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormCategorizationRuleRepository) Delete(id uint) error {
+	r.logger.Debug("repo.categorization_rule.delete",
+		slog.String("op", "repo.categorization_rule.delete"),
+		slog.Uint64("id", uint64(id)),
+	)
+	if err := r.db.Delete(&models.CategorizationRule{}, id).Error; err != nil {
+		r.logger.Error("repo.categorization_rule.delete failed",
+			slog.String("op", "repo.categorization_rule.delete"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
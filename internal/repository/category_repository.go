@@ -14,9 +14,12 @@ type CategoryRepository interface {
 	List() ([]models.Category, error)
 	GetByID(id uint) (*models.Category, error)
 	GetByUserID(userID uint) ([]models.Category, error)
+	ListPage(userID uint, categoryType *models.CategoryType, page models.PageRequest) (*models.Page[models.Category], error)
 	Create(category *models.Category) error
 	Update(category *models.Category) error
 	Delete(id uint) error
+	CountUsage(id uint) (*models.CategoryUsage, error)
+	Merge(sourceIDs []uint, targetID uint) (*models.CategoryMergeResult, error)
 }
 
 type gormCategoryRepository struct {
@@ -77,6 +80,39 @@ func (r *gormCategoryRepository) GetByUserID(userID uint) ([]models.Category, er
 	return categories, nil
 }
 
+var categoryKeyset = keyset[models.Category]{
+	id: func(c *models.Category) uint { return c.ID },
+	sorts: map[string]sortKey[models.Category]{
+		models.SortByName:      {expr: "name", value: func(c *models.Category) interface{} { return c.Name }},
+		models.SortByCreatedAt: {expr: "created_at", value: func(c *models.Category) interface{} { return c.CreatedAt }},
+	},
+}
+
+// ListPage возвращает страницу категорий пользователя в порядке page; categoryType оставляет категории одного типа
+func (r *gormCategoryRepository) ListPage(userID uint, categoryType *models.CategoryType, page models.PageRequest) (*models.Page[models.Category], error) {
+	r.logger.Debug("repo.category.list_page",
+		slog.String("op", "repo.category.list_page"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("sort", page.SortBy),
+	)
+	query := r.db.Model(&models.Category{}).Where("user_id = ?", userID)
+	if categoryType != nil {
+		query = query.Where("type = ?", *categoryType)
+	}
+	result, err := categoryKeyset.page(query, page)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidCursor) {
+			r.logger.Error("repo.category.list_page failed",
+				slog.String("op", "repo.category.list_page"),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
 func (r *gormCategoryRepository) Create(category *models.Category) error {
 	if category == nil {
 		return errCategoryNil
@@ -120,12 +156,23 @@ func (r *gormCategoryRepository) Update(category *models.Category) error {
 	return nil
 }
 
+// Delete удаляет категорию; ее подкатегории переносятся к родителю удаленной категории,
+// чтобы не ссылаться на удаленную запись
 func (r *gormCategoryRepository) Delete(id uint) error {
 	r.logger.Debug("repo.category.delete",
 		slog.String("op", "repo.category.delete"),
 		slog.Uint64("id", uint64(id)),
 	)
-	if err := r.db.Delete(&models.Category{}, id).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Category{}).
+			Where("parent_id = ?", id).
+			Update("parent_id", gorm.Expr("(SELECT parent_id FROM categories WHERE id = ?)", id)).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&models.Category{}, id).Error
+	})
+	if err != nil {
 		r.logger.Error("repo.category.delete failed",
 			slog.String("op", "repo.category.delete"),
 			slog.Uint64("id", uint64(id)),
@@ -135,3 +182,159 @@ func (r *gormCategoryRepository) Delete(id uint) error {
 	}
 	return nil
 }
+
+// CountUsage считает неудаленные записи, которые ссылаются на категорию
+func (r *gormCategoryRepository) CountUsage(id uint) (*models.CategoryUsage, error) {
+	r.logger.Debug("repo.category.count_usage",
+		slog.String("op", "repo.category.count_usage"),
+		slog.Uint64("id", uint64(id)),
+	)
+
+	var usage models.CategoryUsage
+	counts := []struct {
+		model  interface{}
+		column string
+		dest   *int64
+	}{
+		{&models.Expense{}, "category_id", &usage.Expenses},
+		{&models.RecurringExpense{}, "category_id", &usage.RecurringExpenses},
+		{&models.Income{}, "category_id", &usage.Incomes},
+		{&models.Budget{}, "category_id", &usage.Budgets},
+		{&models.CategorizationRule{}, "category_id", &usage.CategorizationRules},
+		{&models.Category{}, "parent_id", &usage.Subcategories},
+	}
+	for _, c := range counts {
+		if err := r.db.Model(c.model).Where(c.column+" = ?", id).Count(c.dest).Error; err != nil {
+			r.logger.Error("repo.category.count_usage failed",
+				slog.String("op", "repo.category.count_usage"),
+				slog.Uint64("id", uint64(id)),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+	}
+	return &usage, nil
+}
+
+// Merge одной транзакцией переносит в категорию targetID все записи категорий sourceIDs и удаляет их.
+// Расходы, доходы, регулярные расходы и правила переносятся вместе с удаленными в корзину, чтобы
+// не ссылаться на удаленную категорию, но в итоге считаются только неудаленные. Бюджет переносится,
+// только если у целевой категории нет бюджета на тот же месяц, иначе удаляется. Подкатегории
+// становятся подкатегориями целевой.
+func (r *gormCategoryRepository) Merge(sourceIDs []uint, targetID uint) (*models.CategoryMergeResult, error) {
+	r.logger.Debug("repo.category.merge",
+		slog.String("op", "repo.category.merge"),
+		slog.Any("source_ids", sourceIDs),
+		slog.Uint64("target_id", uint64(targetID)),
+	)
+
+	result := &models.CategoryMergeResult{TargetID: targetID, DeletedCategoryIDs: sourceIDs}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		updates := []struct {
+			model interface{}
+			dest  *int64
+		}{
+			{&models.Expense{}, &result.Moved.Expenses},
+			{&models.RecurringExpense{}, &result.Moved.RecurringExpenses},
+			{&models.Income{}, &result.Moved.Incomes},
+			{&models.CategorizationRule{}, &result.Moved.CategorizationRules},
+		}
+		for _, u := range updates {
+			// В итоге учитываются только неудаленные записи, записи из корзины переносятся без подсчета
+			res := tx.Model(u.model).Where("category_id IN ?", sourceIDs).Update("category_id", targetID)
+			if res.Error != nil {
+				return res.Error
+			}
+			*u.dest = res.RowsAffected
+
+			err := tx.Unscoped().Model(u.model).
+				Where("category_id IN ? AND deleted_at IS NOT NULL", sourceIDs).
+				Update("category_id", targetID).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := mergeBudgets(tx, sourceIDs, targetID, result); err != nil {
+			return err
+		}
+
+		res := tx.Model(&models.Category{}).
+			Where("parent_id IN ? AND id NOT IN ?", sourceIDs, sourceIDs).
+			Update("parent_id", targetID)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.Moved.Subcategories = res.RowsAffected
+
+		return tx.Delete(&models.Category{}, sourceIDs).Error
+	})
+	if err != nil {
+		r.logger.Error("repo.category.merge failed",
+			slog.String("op", "repo.category.merge"),
+			slog.Any("source_ids", sourceIDs),
+			slog.Uint64("target_id", uint64(targetID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return result, nil
+}
+
+// mergeBudgets переносит бюджеты категорий sourceIDs в targetID, не нарушая правило
+// одного бюджета категории на месяц: бюджет на уже занятый месяц удаляется
+func mergeBudgets(tx *gorm.DB, sourceIDs []uint, targetID uint, result *models.CategoryMergeResult) error {
+	type month struct{ year, month int }
+
+	var existing []models.Budget
+	if err := tx.Where("category_id = ?", targetID).Find(&existing).Error; err != nil {
+		return err
+	}
+	taken := make(map[month]bool, len(existing))
+	for _, b := range existing {
+		taken[month{b.Year, b.Month}] = true
+	}
+
+	var budgets []models.Budget
+	if err := tx.Where("category_id IN ?", sourceIDs).Order("id").Find(&budgets).Error; err != nil {
+		return err
+	}
+	for _, b := range budgets {
+		key := month{b.Year, b.Month}
+		if taken[key] {
+			if err := tx.Delete(&models.Budget{}, b.ID).Error; err != nil {
+				return err
+			}
+			result.BudgetsDeleted++
+			continue
+		}
+		if err := tx.Model(&models.Budget{}).Where("id = ?", b.ID).Update("category_id", targetID).Error; err != nil {
+			return err
+		}
+		taken[key] = true
+		result.Moved.Budgets++
+	}
+
+	// Удаленные бюджеты не участвуют в уникальном индексе и переносятся без проверок
+	return tx.Unscoped().Model(&models.Budget{}).
+		Where("category_id IN ? AND deleted_at IS NOT NULL", sourceIDs).
+		Update("category_id", targetID).Error
+}
+
+// categorySubtreeQuery выбирает идентификатор категории и всех ее неудаленных подкатегорий;
+// UNION вместо UNION ALL не дает запросу зациклиться
+const categorySubtreeQuery = `WITH RECURSIVE subtree AS (
+	SELECT id FROM categories WHERE id = ?
+	UNION
+	SELECT categories.id FROM categories JOIN subtree ON categories.parent_id = subtree.id
+	WHERE categories.deleted_at IS NULL
+) SELECT id FROM subtree`
+
+// categoryCondition возвращает условие на колонку категории; с includeSubcategories
+// под условие подходят также все подкатегории
+func categoryCondition(column string, includeSubcategories bool) string {
+	if includeSubcategories {
+		return column + " IN (" + categorySubtreeQuery + ")"
+	}
+	return column + " = ?"
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExchangeRateRepository interface {
+	List(filter models.ExchangeRateFilter) ([]models.ExchangeRate, error)
+	GetByID(id uint) (*models.ExchangeRate, error)
+	FindLatest(userID uint, from, to string, date time.Time) (*models.ExchangeRate, error)
+	Upsert(rates []models.ExchangeRate) error
+	Delete(id uint) error
+}
+
+type gormExchangeRateRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewExchangeRateRepository(db *gorm.DB, logger *slog.Logger) ExchangeRateRepository {
+	return &gormExchangeRateRepository{db: db, logger: logger}
+}
+
+// List возвращает курсы пользователя вместе с общими курсами, новые даты первыми
+func (r *gormExchangeRateRepository) List(filter models.ExchangeRateFilter) ([]models.ExchangeRate, error) {
+	r.logger.Debug("repo.exchange_rate.list",
+		slog.String("op", "repo.exchange_rate.list"),
+		slog.Uint64("user_id", uint64(filter.UserID)),
+	)
+
+	var rates []models.ExchangeRate
+	query := r.db.Where("user_id = ? OR user_id IS NULL", filter.UserID)
+	if filter.FromCurrency != "" {
+		query = query.Where("from_currency = ?", filter.FromCurrency)
+	}
+	if filter.ToCurrency != "" {
+		query = query.Where("to_currency = ?", filter.ToCurrency)
+	}
+
+	if err := query.Order("date DESC, from_currency, to_currency").Find(&rates).Error; err != nil {
+		r.logger.Error("repo.exchange_rate.list failed",
+			slog.String("op", "repo.exchange_rate.list"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return rates, nil
+}
+
+func (r *gormExchangeRateRepository) GetByID(id uint) (*models.ExchangeRate, error) {
+	r.logger.Debug("repo.exchange_rate.get_by_id",
+		slog.String("op", "repo.exchange_rate.get_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var rate models.ExchangeRate
+	if err := r.db.First(&rate, id).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("repo.exchange_rate.get_by_id failed",
+				slog.String("op", "repo.exchange_rate.get_by_id"),
+				slog.Uint64("id", uint64(id)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// FindLatest возвращает курс from→to, действующий на дату: последний по дате не позже date.
+// При совпадении дат курс пользователя имеет приоритет над общим.
+func (r *gormExchangeRateRepository) FindLatest(userID uint, from, to string, date time.Time) (*models.ExchangeRate, error) {
+	r.logger.Debug("repo.exchange_rate.find_latest",
+		slog.String("op", "repo.exchange_rate.find_latest"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("from", from),
+		slog.String("to", to),
+		slog.Time("date", date),
+	)
+
+	var rate models.ExchangeRate
+	err := r.db.
+		Where("(user_id = ? OR user_id IS NULL) AND from_currency = ? AND to_currency = ? AND date <= ?",
+			userID, from, to, date.Format(time.DateOnly)).
+		Order("date DESC").
+		Order("user_id IS NULL").
+		First(&rate).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("repo.exchange_rate.find_latest failed",
+				slog.String("op", "repo.exchange_rate.find_latest"),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("from", from),
+				slog.String("to", to),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// Upsert сохраняет курсы одной транзакцией; курс того же владельца, пары и даты перезаписывается
+func (r *gormExchangeRateRepository) Upsert(rates []models.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	r.logger.Debug("repo.exchange_rate.upsert",
+		slog.String("op", "repo.exchange_rate.upsert"),
+		slog.Int("count", len(rates)),
+	)
+
+	// Конфликт проверяется по частичному индексу idx_exchange_rates_owner_pair_date
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "COALESCE(user_id, 0)", Raw: true},
+			{Name: "from_currency"},
+			{Name: "to_currency"},
+			{Name: "date"},
+		},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+		DoUpdates:   clause.AssignmentColumns([]string{"rate", "source", "updated_at"}),
+	}).CreateInBatches(rates, 500).Error
+	if err != nil {
+		r.logger.Error("repo.exchange_rate.upsert failed",
+			slog.String("op", "repo.exchange_rate.upsert"),
+			slog.Int("count", len(rates)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormExchangeRateRepository) Delete(id uint) error {
+	r.logger.Debug("repo.exchange_rate.delete",
+		slog.String("op", "repo.exchange_rate.delete"),
+		slog.Uint64("id", uint64(id)),
+	)
+	if err := r.db.Delete(&models.ExchangeRate{}, id).Error; err != nil {
+		r.logger.Error("repo.exchange_rate.delete failed",
+			slog.String("op", "repo.exchange_rate.delete"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
@@ -2,20 +2,33 @@ package repository
 
 import (
 	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
 	"errors"
 	"log/slog"
+	"strings"
+	"unicode"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var errExpenseNil error = errors.New("expense is nil")
 
+// expenseBatchSize число строк в одном запросе при пакетном создании и изменении расходов
+const expenseBatchSize = 500
+
 type ExpenseRepository interface {
 	List(filter models.ExpenseFilter) ([]models.Expense, error)
+	ListPage(filter models.ExpenseFilter, page models.PageRequest) (*models.Page[models.Expense], error)
 	GetByID(id uint) (*models.Expense, error)
 	Create(expense *models.Expense) error
+	CreateBatch(expenses []models.Expense) error
 	Update(expense *models.Expense) error
 	Delete(id uint) error
+	ReassignCategories(assignments map[uint][]uint) error
+	SumByDay(filter models.ExpenseFilter) ([]models.DailyTotal, error)
+	SumByDayAndTag(filter models.ExpenseFilter) ([]models.TagDailyTotal, error)
+	Stream(filter models.ExpenseFilter, fn func(models.ExpenseExportRow) error) error
 }
 
 type gormExpenseRepository struct {
@@ -34,10 +47,140 @@ func (r *gormExpenseRepository) List(filter models.ExpenseFilter) ([]models.Expe
 	)
 
 	var expenses []models.Expense
-	query := r.db.Model(&models.Expense{}).Preload("Category").Where("user_id = ?", filter.UserID)
+	if err := r.filteredQuery(filter).Preload("Category").Preload("Tags").Order("date DESC, id DESC").Find(&expenses).Error; err != nil {
+		r.logger.Error("repo.expense.list failed",
+			slog.String("op", "repo.expense.list"),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return expenses, nil
+}
+
+var expenseKeyset = keyset[models.Expense]{
+	id: func(e *models.Expense) uint { return e.ID },
+	sorts: map[string]sortKey[models.Expense]{
+		models.SortByDate:      {expr: "date", value: func(e *models.Expense) interface{} { return e.Date }},
+		models.SortByAmount:    {expr: "amount", value: func(e *models.Expense) interface{} { return e.Amount }},
+		models.SortByCreatedAt: {expr: "created_at", value: func(e *models.Expense) interface{} { return e.CreatedAt }},
+	},
+}
+
+// ListPage возвращает страницу расходов по фильтру в порядке page и общее число расходов по фильтру.
+// При поиске расходы получают search_rank и становится доступна сортировка по релевантности.
+func (r *gormExpenseRepository) ListPage(filter models.ExpenseFilter, page models.PageRequest) (*models.Page[models.Expense], error) {
+	r.logger.Debug("repo.expense.list_page",
+		slog.String("op", "repo.expense.list_page"),
+		slog.Uint64("user_id", uint64(filter.UserID)),
+		slog.String("sort", page.SortBy),
+	)
+
+	keys := expenseKeyset
+	scopes := []func(*gorm.DB) *gorm.DB{func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Category").Preload("Tags")
+	}}
+	if terms := searchTerms(filter.Search); len(terms) > 0 {
+		// Релевантность считается по любому из слов, чтобы частичное совпадение описания тоже поднимало расход
+		q := strings.Join(terms, " | ")
+		keys = expenseKeyset.withSort(models.SortByRelevance, sortKey[models.Expense]{
+			expr: expenseSearchRank,
+			args: []interface{}{q, q},
+			value: func(e *models.Expense) interface{} {
+				if e.SearchRank == nil {
+					return float64(0)
+				}
+				return *e.SearchRank
+			},
+		})
+		scopes = append(scopes, func(db *gorm.DB) *gorm.DB {
+			return db.Select("expenses.*, "+expenseSearchRank+" AS search_rank", q, q)
+		})
+	}
+
+	result, err := keys.page(r.filteredQuery(filter), page, scopes...)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidCursor) {
+			r.logger.Error("repo.expense.list_page failed",
+				slog.String("op", "repo.expense.list_page"),
+				slog.Uint64("user_id", uint64(filter.UserID)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// Условия поиска используют GIN индексы из database.createSearchIndexes: описание расхода ищется по сохраненной
+// колонке search_vector, название категории — по выражениям to_tsvector, совпадающим с индексами categories.
+// Условие проверяет одно слово запроса; параметры — слово дважды для описания, владелец категории и слово дважды
+// для названия. Учитываются только неудаленные категории самого пользователя.
+const (
+	expenseSearchWordCondition = `(expenses.search_vector @@ (to_tsquery('russian', ?) || to_tsquery('english', ?))
+		OR expenses.category_id IN (SELECT c.id FROM categories c
+			WHERE c.user_id = ? AND c.deleted_at IS NULL
+				AND (to_tsvector('russian', c.name) @@ to_tsquery('russian', ?)
+					OR to_tsvector('english', c.name) @@ to_tsquery('english', ?))))`
+	// Релевантность считается только по описанию, поэтому расходы, найденные лишь по категории, идут в конце
+	expenseSearchRank = `ts_rank(expenses.search_vector, to_tsquery('russian', ?) || to_tsquery('english', ?))`
+)
+
+// searchTerms превращает текст поиска в слова запроса to_tsquery, каждое ищется по префиксу.
+// В запрос попадают только буквы и цифры, поэтому операторы tsquery из текста не применяются.
+func searchTerms(search string) []string {
+	words := strings.FieldsFunc(strings.ToLower(search), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return words
+}
+
+// Условия фильтра по меткам; метки в корзине не учитываются
+const (
+	expenseAnyTagCondition = `expenses.id IN (SELECT et.expense_id FROM expense_tags et
+		JOIN tags t ON t.id = et.tag_id AND t.deleted_at IS NULL
+		WHERE et.tag_id IN ?)`
+	expenseAllTagsCondition = `expenses.id IN (SELECT et.expense_id FROM expense_tags et
+		JOIN tags t ON t.id = et.tag_id AND t.deleted_at IS NULL
+		WHERE et.tag_id IN ?
+		GROUP BY et.expense_id HAVING COUNT(DISTINCT et.tag_id) = ?)`
+)
+
+// applyExpenseTags добавляет к query условие на метки из фильтра: любая из меток или все сразу
+func applyExpenseTags(query *gorm.DB, filter models.ExpenseFilter) *gorm.DB {
+	if len(filter.TagIDs) == 0 {
+		return query
+	}
+	if filter.TagMatch == models.TagMatchAll {
+		unique := make(map[uint]bool, len(filter.TagIDs))
+		for _, id := range filter.TagIDs {
+			unique[id] = true
+		}
+		return query.Where(expenseAllTagsCondition, filter.TagIDs, len(unique))
+	}
+	return query.Where(expenseAnyTagCondition, filter.TagIDs)
+}
+
+// applyExpenseSearch добавляет к query условие полнотекстового поиска из фильтра: каждое слово должно найтись
+// в описании расхода или в названии его категории, поэтому «uber такси» найдет расход «Uber» в категории «Такси»
+func applyExpenseSearch(query *gorm.DB, filter models.ExpenseFilter) *gorm.DB {
+	for _, term := range searchTerms(filter.Search) {
+		query = query.Where(expenseSearchWordCondition, term, term, filter.UserID, term, term)
+	}
+	return query
+}
+
+// filteredQuery выбирает расходы пользователя по условиям фильтра
+func (r *gormExpenseRepository) filteredQuery(filter models.ExpenseFilter) *gorm.DB {
+	query := r.db.Model(&models.Expense{}).Where("user_id = ?", filter.UserID)
 
 	if filter.CategoryID != nil {
-		query = query.Where("category_id = ?", *filter.CategoryID)
+		query = query.Where(categoryCondition("category_id", filter.IncludeSubcategories), *filter.CategoryID)
+	}
+	if filter.AccountID != nil {
+		query = query.Where("account_id = ?", *filter.AccountID)
 	}
 	if filter.StartDate != nil {
 		query = query.Where("date >= ?", *filter.StartDate)
@@ -51,21 +194,8 @@ func (r *gormExpenseRepository) List(filter models.ExpenseFilter) ([]models.Expe
 	if filter.MaxAmount != nil {
 		query = query.Where("amount <= ?", *filter.MaxAmount)
 	}
-	if filter.Limit != nil {
-		query = query.Limit(*filter.Limit)
-	}
-	if filter.Offset != nil {
-		query = query.Offset(*filter.Offset)
-	}
-
-	if err := query.Find(&expenses).Error; err != nil {
-		r.logger.Error("repo.expense.list failed",
-			slog.String("op", "repo.expense.list"),
-			slog.String("error", err.Error()),
-		)
-		return nil, err
-	}
-	return expenses, nil
+	query = applyExpenseTags(query, filter)
+	return applyExpenseSearch(query, filter)
 }
 
 func (r *gormExpenseRepository) GetByID(id uint) (*models.Expense, error) {
@@ -74,7 +204,7 @@ func (r *gormExpenseRepository) GetByID(id uint) (*models.Expense, error) {
 		slog.Uint64("id", uint64(id)),
 	)
 	var expense models.Expense
-	if err := r.db.First(&expense, id).Error; err != nil {
+	if err := r.db.Preload("Tags").First(&expense, id).Error; err != nil {
 		r.logger.Error("repo.expense.get_by_id failed",
 			slog.String("op", "repo.expense.get_by_id"),
 			slog.Uint64("id", uint64(id)),
@@ -93,15 +223,24 @@ func (r *gormExpenseRepository) Create(expense *models.Expense) error {
 	r.logger.Debug("repo.expense.create",
 		slog.String("op", "repo.expense.create"),
 		slog.Uint64("user_id", uint64(expense.UserID)),
-		slog.Float64("amount", expense.Amount),
+		slog.String("amount", expense.Amount.String()),
 		slog.Uint64("category", uint64(expense.CategoryID)),
 	)
 
-	if err := r.db.Create(expense).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Tags").Create(expense).Error; err != nil {
+			return err
+		}
+		if err := replaceExpenseTags(tx, expense.ID, expense.Tags); err != nil {
+			return err
+		}
+		return adjustAccountBalance(tx, expense.AccountID, -expense.Amount)
+	})
+	if err != nil {
 		r.logger.Error("repo.expense.create failed",
 			slog.String("op", "repo.expense.create"),
 			slog.Uint64("user_id", uint64(expense.UserID)),
-			slog.Float64("amount", expense.Amount),
+			slog.String("amount", expense.Amount.String()),
 			slog.Uint64("category", uint64(expense.CategoryID)),
 			slog.String("error", err.Error()),
 		)
@@ -110,6 +249,49 @@ func (r *gormExpenseRepository) Create(expense *models.Expense) error {
 	return nil
 }
 
+// CreateBatch в одной транзакции сохраняет расходы и списывает их суммы со счетов: либо сохраняются все, либо ни один
+func (r *gormExpenseRepository) CreateBatch(expenses []models.Expense) error {
+	if len(expenses) == 0 {
+		return nil
+	}
+
+	r.logger.Debug("repo.expense.create_batch",
+		slog.String("op", "repo.expense.create_batch"),
+		slog.Uint64("user_id", uint64(expenses[0].UserID)),
+		slog.Int("count", len(expenses)),
+	)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(expenses, expenseBatchSize).Error; err != nil {
+			return err
+		}
+
+		debits := make(map[uint]money.Amount)
+		for _, expense := range expenses {
+			if expense.AccountID != nil {
+				debits[*expense.AccountID] += expense.Amount
+			}
+		}
+		for accountID, amount := range debits {
+			if err := adjustAccountBalance(tx, &accountID, -amount); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("repo.expense.create_batch failed",
+			slog.String("op", "repo.expense.create_batch"),
+			slog.Uint64("user_id", uint64(expenses[0].UserID)),
+			slog.Int("count", len(expenses)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// Update сохраняет расход вместе с набором меток expense.Tags и пересчитывает остатки затронутых счетов
 func (r *gormExpenseRepository) Update(expense *models.Expense) error {
 	if expense == nil {
 		return errExpenseNil
@@ -119,7 +301,24 @@ func (r *gormExpenseRepository) Update(expense *models.Expense) error {
 		slog.Uint64("id", uint64(expense.ID)),
 	)
 
-	if err := r.db.Save(expense).Error; err != nil {
+	// Прежняя запись блокируется, чтобы откатить ее влияние на остаток счета до сохранения новой
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var previous models.Expense
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&previous, expense.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("Tags").Save(expense).Error; err != nil {
+			return err
+		}
+		if err := replaceExpenseTags(tx, expense.ID, expense.Tags); err != nil {
+			return err
+		}
+		if err := adjustAccountBalance(tx, previous.AccountID, previous.Amount); err != nil {
+			return err
+		}
+		return adjustAccountBalance(tx, expense.AccountID, -expense.Amount)
+	})
+	if err != nil {
 		r.logger.Error("repo.expense.update failed",
 			slog.String("op", "repo.expense.update"),
 			slog.Uint64("id", uint64(expense.ID)),
@@ -135,7 +334,17 @@ func (r *gormExpenseRepository) Delete(id uint) error {
 		slog.String("op", "repo.expense.delete"),
 		slog.Uint64("id", uint64(id)),
 	)
-	if err := r.db.Delete(&models.Expense{}, id).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var expense models.Expense
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&expense, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&expense).Error; err != nil {
+			return err
+		}
+		return adjustAccountBalance(tx, expense.AccountID, expense.Amount)
+	})
+	if err != nil {
 		r.logger.Error("repo.expense.delete failed",
 			slog.String("op", "repo.expense.delete"),
 			slog.Uint64("id", uint64(id)),
@@ -145,3 +354,198 @@ func (r *gormExpenseRepository) Delete(id uint) error {
 	}
 	return nil
 }
+
+// expenseTag строка таблицы связей расходов и меток
+type expenseTag struct {
+	ExpenseID uint
+	TagID     uint
+}
+
+// replaceExpenseTags оставляет у расхода только метки tags. Связи с метками из корзины не трогаются,
+// чтобы после восстановления метка вернулась к своим расходам.
+func replaceExpenseTags(tx *gorm.DB, expenseID uint, tags []models.Tag) error {
+	rows := make([]expenseTag, 0, len(tags))
+	ids := make([]uint, 0, len(tags))
+	for _, tag := range tags {
+		rows = append(rows, expenseTag{ExpenseID: expenseID, TagID: tag.ID})
+		ids = append(ids, tag.ID)
+	}
+
+	stale := tx.Table("expense_tags").
+		Where("expense_id = ?", expenseID).
+		Where("tag_id IN (SELECT id FROM tags WHERE deleted_at IS NULL)")
+	if len(ids) > 0 {
+		stale = stale.Where("tag_id NOT IN ?", ids)
+	}
+	if err := stale.Delete(&expenseTag{}).Error; err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.Table("expense_tags").Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+// ReassignCategories в одной транзакции переносит расходы в новые категории; ключ — категория, значение — расходы
+func (r *gormExpenseRepository) ReassignCategories(assignments map[uint][]uint) error {
+	r.logger.Debug("repo.expense.reassign_categories",
+		slog.String("op", "repo.expense.reassign_categories"),
+		slog.Int("categories", len(assignments)),
+	)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for categoryID, expenseIDs := range assignments {
+			for start := 0; start < len(expenseIDs); start += expenseBatchSize {
+				end := min(start+expenseBatchSize, len(expenseIDs))
+				err := tx.Model(&models.Expense{}).
+					Where("id IN ?", expenseIDs[start:end]).
+					Update("category_id", categoryID).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("repo.expense.reassign_categories failed",
+			slog.String("op", "repo.expense.reassign_categories"),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// SumByDay суммирует расходы пользователя по дням (в UTC), валютам и категориям.
+// Суммы не пересчитываются между валютами: для пересчета нужен курс на день расхода.
+func (r *gormExpenseRepository) SumByDay(filter models.ExpenseFilter) ([]models.DailyTotal, error) {
+	r.logger.Debug("repo.expense.sum_by_day",
+		slog.String("op", "repo.expense.sum_by_day"),
+		slog.Uint64("user_id", uint64(filter.UserID)),
+	)
+
+	var totals []models.DailyTotal
+	err := r.aggregateQuery(filter).
+		Select("(expenses.date AT TIME ZONE 'UTC')::date AS day, expenses.currency AS currency, " +
+			"categories.id AS category_id, categories.name AS category_name, categories.color AS category_color, " +
+			"SUM(expenses.amount) AS total_amount, COUNT(*) AS count").
+		Group("day, expenses.currency, categories.id, categories.name, categories.color").
+		Order("day, categories.id, expenses.currency").
+		Scan(&totals).Error
+	if err != nil {
+		r.logger.Error("repo.expense.sum_by_day failed",
+			slog.String("op", "repo.expense.sum_by_day"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return totals, nil
+}
+
+// SumByDayAndTag суммирует расходы пользователя по дням (в UTC), валютам и меткам.
+// Расход с несколькими метками входит в сумму каждой из них, расходы без меток не учитываются.
+func (r *gormExpenseRepository) SumByDayAndTag(filter models.ExpenseFilter) ([]models.TagDailyTotal, error) {
+	r.logger.Debug("repo.expense.sum_by_day_and_tag",
+		slog.String("op", "repo.expense.sum_by_day_and_tag"),
+		slog.Uint64("user_id", uint64(filter.UserID)),
+	)
+
+	var totals []models.TagDailyTotal
+	err := r.aggregateQuery(filter).
+		Joins("JOIN expense_tags ON expense_tags.expense_id = expenses.id").
+		Joins("JOIN tags ON tags.id = expense_tags.tag_id AND tags.deleted_at IS NULL").
+		Select("(expenses.date AT TIME ZONE 'UTC')::date AS day, expenses.currency AS currency, " +
+			"tags.id AS tag_id, tags.name AS tag_name, tags.color AS tag_color, " +
+			"SUM(expenses.amount) AS total_amount, COUNT(*) AS count").
+		Group("day, expenses.currency, tags.id, tags.name, tags.color").
+		Order("day, tags.id, expenses.currency").
+		Scan(&totals).Error
+	if err != nil {
+		r.logger.Error("repo.expense.sum_by_day_and_tag failed",
+			slog.String("op", "repo.expense.sum_by_day_and_tag"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return totals, nil
+}
+
+// Stream построчно передает в fn расходы по фильтру в порядке даты, не загружая выборку в память целиком.
+// Ошибка fn прерывает чтение и возвращается как есть.
+func (r *gormExpenseRepository) Stream(filter models.ExpenseFilter, fn func(models.ExpenseExportRow) error) error {
+	r.logger.Debug("repo.expense.stream",
+		slog.String("op", "repo.expense.stream"),
+		slog.Uint64("user_id", uint64(filter.UserID)),
+	)
+
+	query := r.aggregateQuery(filter).
+		Select("expenses.id, expenses.date, expenses.category_id, categories.name AS category_name, " +
+			"categories.color AS category_color, expenses.account_id, expenses.amount, expenses.currency, expenses.description").
+		Order("expenses.date, expenses.id")
+
+	rows, err := query.Rows()
+	if err != nil {
+		r.logger.Error("repo.expense.stream failed",
+			slog.String("op", "repo.expense.stream"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row models.ExpenseExportRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			r.logger.Error("repo.expense.stream failed",
+				slog.String("op", "repo.expense.stream"),
+				slog.Uint64("user_id", uint64(filter.UserID)),
+				slog.String("error", err.Error()),
+			)
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("repo.expense.stream failed",
+			slog.String("op", "repo.expense.stream"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// aggregateQuery строит запрос по расходам пользователя с категориями и условиями фильтра для агрегации
+func (r *gormExpenseRepository) aggregateQuery(filter models.ExpenseFilter) *gorm.DB {
+	query := r.db.Model(&models.Expense{}).
+		Joins("JOIN categories ON categories.id = expenses.category_id").
+		Where("expenses.user_id = ?", filter.UserID)
+
+	if filter.CategoryID != nil {
+		query = query.Where(categoryCondition("expenses.category_id", filter.IncludeSubcategories), *filter.CategoryID)
+	}
+	if filter.AccountID != nil {
+		query = query.Where("expenses.account_id = ?", *filter.AccountID)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("expenses.date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("expenses.date <= ?", *filter.EndDate)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("expenses.amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("expenses.amount <= ?", *filter.MaxAmount)
+	}
+	query = applyExpenseTags(query, filter)
+	return applyExpenseSearch(query, filter)
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errIncomeNil error = errors.New("income is nil")
+
+type IncomeRepository interface {
+	List(filter models.IncomeFilter) ([]models.Income, error)
+	GetByID(id uint) (*models.Income, error)
+	Create(income *models.Income) error
+	Update(income *models.Income) error
+	Delete(id uint) error
+	SumByDay(filter models.IncomeFilter) ([]models.DailyTotal, error)
+}
+
+type gormIncomeRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewIncomeRepository(db *gorm.DB, logger *slog.Logger) IncomeRepository {
+	return &gormIncomeRepository{db: db, logger: logger}
+}
+
+func (r *gormIncomeRepository) List(filter models.IncomeFilter) ([]models.Income, error) {
+	r.logger.Debug("repo.income.list",
+		slog.String("op", "repo.income.list"),
+	)
+
+	var incomes []models.Income
+	query := r.db.Model(&models.Income{}).Preload("Category").Where("user_id = ?", filter.UserID)
+
+	if filter.CategoryID != nil {
+		query = query.Where("category_id = ?", *filter.CategoryID)
+	}
+	if filter.AccountID != nil {
+		query = query.Where("account_id = ?", *filter.AccountID)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("date <= ?", *filter.EndDate)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("amount <= ?", *filter.MaxAmount)
+	}
+	if filter.Limit != nil {
+		query = query.Limit(*filter.Limit)
+	}
+	if filter.Offset != nil {
+		query = query.Offset(*filter.Offset)
+	}
+
+	if err := query.Find(&incomes).Error; err != nil {
+		r.logger.Error("repo.income.list failed",
+			slog.String("op", "repo.income.list"),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return incomes, nil
+}
+
+func (r *gormIncomeRepository) GetByID(id uint) (*models.Income, error) {
+	r.logger.Debug("repo.income.get_by_id",
+		slog.String("op", "repo.income.get_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var income models.Income
+	if err := r.db.First(&income, id).Error; err != nil {
+		r.logger.Error("repo.income.get_by_id failed",
+			slog.String("op", "repo.income.get_by_id"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return &income, nil
+}
+
+func (r *gormIncomeRepository) Create(income *models.Income) error {
+	if income == nil {
+		return errIncomeNil
+	}
+
+	r.logger.Debug("repo.income.create",
+		slog.String("op", "repo.income.create"),
+		slog.Uint64("user_id", uint64(income.UserID)),
+		slog.String("amount", income.Amount.String()),
+		slog.Uint64("category", uint64(income.CategoryID)),
+	)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(income).Error; err != nil {
+			return err
+		}
+		return adjustAccountBalance(tx, income.AccountID, income.Amount)
+	})
+	if err != nil {
+		r.logger.Error("repo.income.create failed",
+			slog.String("op", "repo.income.create"),
+			slog.Uint64("user_id", uint64(income.UserID)),
+			slog.String("amount", income.Amount.String()),
+			slog.Uint64("category", uint64(income.CategoryID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormIncomeRepository) Update(income *models.Income) error {
+	if income == nil {
+		return errIncomeNil
+	}
+	r.logger.Debug("repo.income.update",
+		slog.String("op", "repo.income.update"),
+		slog.Uint64("id", uint64(income.ID)),
+	)
+
+	// Прежняя запись блокируется, чтобы откатить ее влияние на остаток счета до сохранения новой
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var previous models.Income
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&previous, income.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(income).Error; err != nil {
+			return err
+		}
+		if err := adjustAccountBalance(tx, previous.AccountID, -previous.Amount); err != nil {
+			return err
+		}
+		return adjustAccountBalance(tx, income.AccountID, income.Amount)
+	})
+	if err != nil {
+		r.logger.Error("repo.income.update failed",
+			slog.String("op", "repo.income.update"),
+			slog.Uint64("id", uint64(income.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormIncomeRepository) Delete(id uint) error {
+	r.logger.Debug("repo.income.delete",
+		slog.String("op", "repo.income.delete"),
+		slog.Uint64("id", uint64(id)),
+	)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var income models.Income
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&income, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&income).Error; err != nil {
+			return err
+		}
+		return adjustAccountBalance(tx, income.AccountID, -income.Amount)
+	})
+	if err != nil {
+		r.logger.Error("repo.income.delete failed",
+			slog.String("op", "repo.income.delete"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// SumByDay суммирует доходы пользователя по дням (в UTC), валютам и категориям; Limit и Offset фильтра не учитываются.
+// Результат совпадает по форме с дневными суммами расходов, чтобы пересчет в базовую валюту был общим.
+func (r *gormIncomeRepository) SumByDay(filter models.IncomeFilter) ([]models.DailyTotal, error) {
+	r.logger.Debug("repo.income.sum_by_day",
+		slog.String("op", "repo.income.sum_by_day"),
+		slog.Uint64("user_id", uint64(filter.UserID)),
+	)
+
+	query := r.db.Model(&models.Income{}).
+		Joins("JOIN categories ON categories.id = incomes.category_id").
+		Where("incomes.user_id = ?", filter.UserID)
+	if filter.CategoryID != nil {
+		query = query.Where("incomes.category_id = ?", *filter.CategoryID)
+	}
+	if filter.AccountID != nil {
+		query = query.Where("incomes.account_id = ?", *filter.AccountID)
+	}
+	if filter.StartDate != nil {
+		query = query.Where("incomes.date >= ?", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		query = query.Where("incomes.date <= ?", *filter.EndDate)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("incomes.amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("incomes.amount <= ?", *filter.MaxAmount)
+	}
+
+	var totals []models.DailyTotal
+	err := query.
+		Select("(incomes.date AT TIME ZONE 'UTC')::date AS day, incomes.currency AS currency, " +
+			"categories.id AS category_id, categories.name AS category_name, categories.color AS category_color, " +
+			"SUM(incomes.amount) AS total_amount, COUNT(*) AS count").
+		Group("day, incomes.currency, categories.id, categories.name, categories.color").
+		Order("day, categories.id, incomes.currency").
+		Scan(&totals).Error
+	if err != nil {
+		r.logger.Error("repo.income.sum_by_day failed",
+			slog.String("op", "repo.income.sum_by_day"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return totals, nil
+}
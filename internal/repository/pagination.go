@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errUnknownSortField = errors.New("unknown sort field")
+
+	// ErrInvalidCursor возвращается, если курсор не удалось разобрать или он выдан для другой сортировки
+	ErrInvalidCursor = errors.New("invalid page cursor")
+)
+
+// keyset описывает постраничный вывод списка по курсору: допустимые поля сортировки и идентификатор записи,
+// который делает порядок однозначным при равных значениях поля
+type keyset[T any] struct {
+	id    func(*T) uint
+	sorts map[string]sortKey[T]
+}
+
+// sortKey поле сортировки: SQL выражение с параметрами и значение этого выражения у загруженной записи
+type sortKey[T any] struct {
+	expr  string
+	value func(*T) interface{}
+	args  []interface{}
+}
+
+// withSort возвращает копию keyset с дополнительным полем сортировки, выражение которого зависит от запроса
+func (k keyset[T]) withSort(name string, key sortKey[T]) keyset[T] {
+	sorts := make(map[string]sortKey[T], len(k.sorts)+1)
+	for n, s := range k.sorts {
+		sorts[n] = s
+	}
+	sorts[name] = key
+	return keyset[T]{id: k.id, sorts: sorts}
+}
+
+// pageCursor содержимое курсора: сортировка, для которой он выдан, и ключ последней записи страницы
+type pageCursor struct {
+	SortBy string           `json:"s"`
+	Order  models.SortOrder `json:"o"`
+	Value  json.RawMessage  `json:"v"`
+	ID     uint             `json:"id"`
+}
+
+// page выбирает из query одну страницу после курсора и считает общее число записей по query.
+// scopes применяются только к выборке записей, например для Preload.
+func (k keyset[T]) page(query *gorm.DB, req models.PageRequest, scopes ...func(*gorm.DB) *gorm.DB) (*models.Page[T], error) {
+	key, ok := k.sorts[req.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errUnknownSortField, req.SortBy)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	direction, comparison := "ASC", ">"
+	if req.Order == models.SortDesc {
+		direction, comparison = "DESC", "<"
+	}
+
+	items := query.Session(&gorm.Session{}).Scopes(scopes...)
+	if req.Cursor != "" {
+		value, id, err := k.decodeCursor(req, key)
+		if err != nil {
+			return nil, err
+		}
+		args := append(append([]interface{}{}, key.args...), value, id)
+		items = items.Where(fmt.Sprintf("(%s, id) %s (?, ?)", key.expr, comparison), args...)
+	}
+
+	// Лишняя запись показывает, что за страницей есть продолжение
+	result := make([]T, 0, req.Limit+1)
+	err := items.
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                fmt.Sprintf("%s %s, id %s", key.expr, direction, direction),
+			Vars:               key.args,
+			WithoutParentheses: true,
+		}}).
+		Limit(req.Limit + 1).
+		Find(&result).Error
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.Page[T]{Items: result, Total: total}
+	if len(result) > req.Limit {
+		page.Items = result[:req.Limit]
+		last := &page.Items[req.Limit-1]
+		cursor, err := encodeCursor(req, key.value(last), k.id(last))
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = &cursor
+	}
+	return page, nil
+}
+
+func encodeCursor(req models.PageRequest, value interface{}, id uint) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(pageCursor{SortBy: req.SortBy, Order: req.Order, Value: raw, ID: id})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor разбирает курсор и возвращает значение поля сортировки в том же типе, что у записей
+func (k keyset[T]) decodeCursor(req models.PageRequest, key sortKey[T]) (interface{}, uint, error) {
+	data, err := base64.RawURLEncoding.DecodeString(req.Cursor)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	if cursor.SortBy != req.SortBy || cursor.Order != req.Order || len(cursor.Value) == 0 {
+		return nil, 0, ErrInvalidCursor
+	}
+
+	var zero T
+	value := reflect.New(reflect.TypeOf(key.value(&zero)))
+	if err := json.Unmarshal(cursor.Value, value.Interface()); err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	return value.Elem().Interface(), cursor.ID, nil
+}
@@ -7,17 +7,25 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-var errRecurringExpenseNil error = errors.New("recurring expense is nil")
+var (
+	errRecurringExpenseNil error = errors.New("recurring expense is nil")
+
+	// ErrRecurringExpenseChanged возвращается, если NextDate регулярного расхода изменился с момента чтения
+	ErrRecurringExpenseChanged = errors.New("recurring expense next date changed concurrently")
+)
 
 type RecurringExpenseRepository interface {
 	List() ([]models.RecurringExpense, error)
 	GetByID(id uint) (*models.RecurringExpense, error)
 	GetByUserID(userID uint) ([]models.RecurringExpense, error)
+	ListPage(userID uint, page models.PageRequest) (*models.Page[models.RecurringExpense], error)
 	GetActiveByNextDate(nextDate time.Time) ([]models.RecurringExpense, error)
 	Create(recurringExpense *models.RecurringExpense) error
 	Update(recurringExpense *models.RecurringExpense) error
+	MaterializeOccurrence(recurringExpense *models.RecurringExpense, expense *models.Expense, nextDate time.Time) (bool, error)
 	Delete(id uint) error
 }
 
@@ -79,6 +87,39 @@ func (r *gormRecurringExpenseRepository) GetByUserID(userID uint) ([]models.Recu
 	return recurringExpenses, nil
 }
 
+var recurringExpenseKeyset = keyset[models.RecurringExpense]{
+	id: func(re *models.RecurringExpense) uint { return re.ID },
+	sorts: map[string]sortKey[models.RecurringExpense]{
+		models.SortByNextDate:  {expr: "next_date", value: func(re *models.RecurringExpense) interface{} { return re.NextDate }},
+		models.SortByAmount:    {expr: "amount", value: func(re *models.RecurringExpense) interface{} { return re.Amount }},
+		models.SortByCreatedAt: {expr: "created_at", value: func(re *models.RecurringExpense) interface{} { return re.CreatedAt }},
+	},
+}
+
+// ListPage возвращает страницу регулярных расходов пользователя в порядке page
+func (r *gormRecurringExpenseRepository) ListPage(userID uint, page models.PageRequest) (*models.Page[models.RecurringExpense], error) {
+	r.logger.Debug("repo.recurring_expense.list_page",
+		slog.String("op", "repo.recurring_expense.list_page"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("sort", page.SortBy),
+	)
+	query := r.db.Model(&models.RecurringExpense{}).Where("user_id = ?", userID)
+	result, err := recurringExpenseKeyset.page(query, page, func(db *gorm.DB) *gorm.DB {
+		return db.Preload("Category")
+	})
+	if err != nil {
+		if !errors.Is(err, ErrInvalidCursor) {
+			r.logger.Error("repo.recurring_expense.list_page failed",
+				slog.String("op", "repo.recurring_expense.list_page"),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
 func (r *gormRecurringExpenseRepository) GetActiveByNextDate(nextDate time.Time) ([]models.RecurringExpense, error) {
 	r.logger.Debug("repo.recurring_expense.get_active_by_next_date",
 		slog.String("op", "repo.recurring_expense.get_active_by_next_date"),
@@ -104,7 +145,7 @@ func (r *gormRecurringExpenseRepository) Create(recurringExpense *models.Recurri
 		slog.String("op", "repo.recurring_expense.create"),
 		slog.Uint64("user_id", uint64(recurringExpense.UserID)),
 		slog.Uint64("category_id", uint64(recurringExpense.CategoryID)),
-		slog.Float64("amount", recurringExpense.Amount),
+		slog.String("amount", recurringExpense.Amount.String()),
 		slog.String("type", string(recurringExpense.Type)),
 		slog.Time("next_date", recurringExpense.NextDate),
 	)
@@ -113,7 +154,7 @@ func (r *gormRecurringExpenseRepository) Create(recurringExpense *models.Recurri
 			slog.String("op", "repo.recurring_expense.create"),
 			slog.Uint64("user_id", uint64(recurringExpense.UserID)),
 			slog.Uint64("category_id", uint64(recurringExpense.CategoryID)),
-			slog.Float64("amount", recurringExpense.Amount),
+			slog.String("amount", recurringExpense.Amount.String()),
 			slog.String("type", string(recurringExpense.Type)),
 			slog.Time("next_date", recurringExpense.NextDate),
 			slog.String("error", err.Error()),
@@ -132,7 +173,7 @@ func (r *gormRecurringExpenseRepository) Update(recurringExpense *models.Recurri
 		slog.Uint64("id", uint64(recurringExpense.ID)),
 		slog.Uint64("user_id", uint64(recurringExpense.UserID)),
 		slog.Uint64("category_id", uint64(recurringExpense.CategoryID)),
-		slog.Float64("amount", recurringExpense.Amount),
+		slog.String("amount", recurringExpense.Amount.String()),
 		slog.String("type", string(recurringExpense.Type)),
 		slog.Time("next_date", recurringExpense.NextDate),
 	)
@@ -142,7 +183,7 @@ func (r *gormRecurringExpenseRepository) Update(recurringExpense *models.Recurri
 			slog.Uint64("id", uint64(recurringExpense.ID)),
 			slog.Uint64("user_id", uint64(recurringExpense.UserID)),
 			slog.Uint64("category_id", uint64(recurringExpense.CategoryID)),
-			slog.Float64("amount", recurringExpense.Amount),
+			slog.String("amount", recurringExpense.Amount.String()),
 			slog.String("type", string(recurringExpense.Type)),
 			slog.Time("next_date", recurringExpense.NextDate),
 			slog.String("error", err.Error()),
@@ -152,6 +193,64 @@ func (r *gormRecurringExpenseRepository) Update(recurringExpense *models.Recurri
 	return nil
 }
 
+// MaterializeOccurrence в одной транзакции создает расход за повторение, списывает его со счета и сдвигает NextDate.
+// Повторение идентифицируется парой (recurring_expense_id, occurrence_date) с уникальным индексом,
+// поэтому уже созданный ранее расход не дублируется: возвращается false, а NextDate все равно сдвигается.
+// Если NextDate в БД уже не совпадает с прочитанным, транзакция откатывается с ErrRecurringExpenseChanged.
+func (r *gormRecurringExpenseRepository) MaterializeOccurrence(recurringExpense *models.RecurringExpense, expense *models.Expense, nextDate time.Time) (bool, error) {
+	if recurringExpense == nil {
+		return false, errRecurringExpenseNil
+	}
+	if expense == nil {
+		return false, errExpenseNil
+	}
+
+	r.logger.Debug("repo.recurring_expense.materialize_occurrence",
+		slog.String("op", "repo.recurring_expense.materialize_occurrence"),
+		slog.Uint64("id", uint64(recurringExpense.ID)),
+		slog.Time("next_date", recurringExpense.NextDate),
+	)
+
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(expense)
+		if res.Error != nil {
+			return res.Error
+		}
+		created = res.RowsAffected > 0
+		if created {
+			if err := adjustAccountBalance(tx, expense.AccountID, -expense.Amount); err != nil {
+				return err
+			}
+		}
+
+		res = tx.Model(&models.RecurringExpense{}).
+			Where("id = ? AND next_date = ?", recurringExpense.ID, recurringExpense.NextDate).
+			Update("next_date", nextDate)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRecurringExpenseChanged
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrRecurringExpenseChanged) {
+			r.logger.Error("repo.recurring_expense.materialize_occurrence failed",
+				slog.String("op", "repo.recurring_expense.materialize_occurrence"),
+				slog.Uint64("id", uint64(recurringExpense.ID)),
+				slog.Time("next_date", recurringExpense.NextDate),
+				slog.String("error", err.Error()),
+			)
+		}
+		return false, err
+	}
+
+	recurringExpense.NextDate = nextDate
+	return created, nil
+}
+
 func (r *gormRecurringExpenseRepository) Delete(id uint) error {
 	r.logger.Debug("repo.recurring_expense.delete",
 		slog.String("op", "repo.recurring_expense.delete"),
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	errRefreshTokenNil = errors.New("refresh token is nil")
+
+	// ErrRefreshTokenRevoked возвращается при попытке ротации уже отозванного токена
+	ErrRefreshTokenRevoked = errors.New("refresh token already revoked")
+)
+
+type RefreshTokenRepository interface {
+	GetByHash(hash string) (*models.RefreshToken, error)
+	Create(token *models.RefreshToken) error
+	Rotate(oldID uint, next *models.RefreshToken) error
+	RevokeFamily(familyID string) error
+	RevokeAllByUserID(userID uint) error
+	IsFamilyActive(familyID string, userID uint) (bool, error)
+}
+
+type gormRefreshTokenRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewRefreshTokenRepository(db *gorm.DB, logger *slog.Logger) RefreshTokenRepository {
+	return &gormRefreshTokenRepository{db: db, logger: logger}
+}
+
+func (r *gormRefreshTokenRepository) GetByHash(hash string) (*models.RefreshToken, error) {
+	r.logger.Debug("repo.refresh_token.get_by_hash",
+		slog.String("op", "repo.refresh_token.get_by_hash"),
+	)
+	var token models.RefreshToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("repo.refresh_token.get_by_hash failed",
+				slog.String("op", "repo.refresh_token.get_by_hash"),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *gormRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	if token == nil {
+		return errRefreshTokenNil
+	}
+
+	r.logger.Debug("repo.refresh_token.create",
+		slog.String("op", "repo.refresh_token.create"),
+		slog.Uint64("user_id", uint64(token.UserID)),
+		slog.String("family_id", token.FamilyID),
+	)
+
+	if err := r.db.Create(token).Error; err != nil {
+		r.logger.Error("repo.refresh_token.create failed",
+			slog.String("op", "repo.refresh_token.create"),
+			slog.Uint64("user_id", uint64(token.UserID)),
+			slog.String("family_id", token.FamilyID),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// Rotate атомарно отзывает старый токен и сохраняет выданный взамен.
+// Если старый токен уже был отозван (например, параллельным запросом), возвращает ErrRefreshTokenRevoked.
+func (r *gormRefreshTokenRepository) Rotate(oldID uint, next *models.RefreshToken) error {
+	if next == nil {
+		return errRefreshTokenNil
+	}
+
+	r.logger.Debug("repo.refresh_token.rotate",
+		slog.String("op", "repo.refresh_token.rotate"),
+		slog.Uint64("old_id", uint64(oldID)),
+		slog.String("family_id", next.FamilyID),
+	)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+
+		res := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", oldID).
+			Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"replaced_by_id": next.ID,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRefreshTokenRevoked
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrRefreshTokenRevoked) {
+		r.logger.Error("repo.refresh_token.rotate failed",
+			slog.String("op", "repo.refresh_token.rotate"),
+			slog.Uint64("old_id", uint64(oldID)),
+			slog.String("family_id", next.FamilyID),
+			slog.String("error", err.Error()),
+		)
+	}
+	return err
+}
+
+func (r *gormRefreshTokenRepository) RevokeFamily(familyID string) error {
+	r.logger.Debug("repo.refresh_token.revoke_family",
+		slog.String("op", "repo.refresh_token.revoke_family"),
+		slog.String("family_id", familyID),
+	)
+	err := r.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.logger.Error("repo.refresh_token.revoke_family failed",
+			slog.String("op", "repo.refresh_token.revoke_family"),
+			slog.String("family_id", familyID),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormRefreshTokenRepository) RevokeAllByUserID(userID uint) error {
+	r.logger.Debug("repo.refresh_token.revoke_all_by_user_id",
+		slog.String("op", "repo.refresh_token.revoke_all_by_user_id"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+	err := r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		r.logger.Error("repo.refresh_token.revoke_all_by_user_id failed",
+			slog.String("op", "repo.refresh_token.revoke_all_by_user_id"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// IsFamilyActive проверяет, что в цепочке сессии пользователя остался действующий (не отозванный и не истекший)
+// токен, а сам пользователь не удален
+func (r *gormRefreshTokenRepository) IsFamilyActive(familyID string, userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.RefreshToken{}).
+		Joins("JOIN users ON users.id = refresh_tokens.user_id AND users.deleted_at IS NULL").
+		Where("refresh_tokens.family_id = ? AND refresh_tokens.user_id = ?", familyID, userID).
+		Where("refresh_tokens.revoked_at IS NULL AND refresh_tokens.expires_at > ?", time.Now()).
+		Count(&count).Error
+	if err != nil {
+		r.logger.Error("repo.refresh_token.is_family_active failed",
+			slog.String("op", "repo.refresh_token.is_family_active"),
+			slog.String("family_id", familyID),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var errTagNil error = errors.New("tag is nil")
+
+type TagRepository interface {
+	GetByID(id uint) (*models.Tag, error)
+	GetByUserID(userID uint) ([]models.Tag, error)
+	GetByIDs(userID uint, ids []uint) ([]models.Tag, error)
+	GetByName(userID uint, name string) (*models.Tag, error)
+	Create(tag *models.Tag) error
+	Update(tag *models.Tag) error
+	Delete(id uint) error
+}
+
+type gormTagRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewTagRepository(db *gorm.DB, logger *slog.Logger) TagRepository {
+	return &gormTagRepository{db: db, logger: logger}
+}
+
+func (r *gormTagRepository) GetByID(id uint) (*models.Tag, error) {
+	r.logger.Debug("repo.tag.get_by_id",
+		slog.String("op", "repo.tag.get_by_id"),
+		slog.Uint64("id", uint64(id)),
+	)
+	var tag models.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("repo.tag.get_by_id failed",
+				slog.String("op", "repo.tag.get_by_id"),
+				slog.Uint64("id", uint64(id)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetByUserID возвращает все метки пользователя по алфавиту
+func (r *gormTagRepository) GetByUserID(userID uint) ([]models.Tag, error) {
+	r.logger.Debug("repo.tag.get_by_user_id",
+		slog.String("op", "repo.tag.get_by_user_id"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+	var tags []models.Tag
+	if err := r.db.Where("user_id = ?", userID).Order("lower(name), id").Find(&tags).Error; err != nil {
+		r.logger.Error("repo.tag.get_by_user_id failed",
+			slog.String("op", "repo.tag.get_by_user_id"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetByIDs возвращает метки пользователя из списка ids; чужие и удаленные метки не возвращаются
+func (r *gormTagRepository) GetByIDs(userID uint, ids []uint) ([]models.Tag, error) {
+	r.logger.Debug("repo.tag.get_by_ids",
+		slog.String("op", "repo.tag.get_by_ids"),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(ids)),
+	)
+	var tags []models.Tag
+	if err := r.db.Where("user_id = ? AND id IN ?", userID, ids).Order("id").Find(&tags).Error; err != nil {
+		r.logger.Error("repo.tag.get_by_ids failed",
+			slog.String("op", "repo.tag.get_by_ids"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetByName ищет метку пользователя по названию без учета регистра
+func (r *gormTagRepository) GetByName(userID uint, name string) (*models.Tag, error) {
+	r.logger.Debug("repo.tag.get_by_name",
+		slog.String("op", "repo.tag.get_by_name"),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+	var tag models.Tag
+	if err := r.db.Where("user_id = ? AND lower(name) = lower(?)", userID, name).First(&tag).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.logger.Error("repo.tag.get_by_name failed",
+				slog.String("op", "repo.tag.get_by_name"),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *gormTagRepository) Create(tag *models.Tag) error {
+	if tag == nil {
+		return errTagNil
+	}
+	r.logger.Debug("repo.tag.create",
+		slog.String("op", "repo.tag.create"),
+		slog.Uint64("user_id", uint64(tag.UserID)),
+	)
+	if err := r.db.Create(tag).Error; err != nil {
+		r.logger.Error("repo.tag.create failed",
+			slog.String("op", "repo.tag.create"),
+			slog.Uint64("user_id", uint64(tag.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+func (r *gormTagRepository) Update(tag *models.Tag) error {
+	if tag == nil {
+		return errTagNil
+	}
+	r.logger.Debug("repo.tag.update",
+		slog.String("op", "repo.tag.update"),
+		slog.Uint64("id", uint64(tag.ID)),
+	)
+	if err := r.db.Save(tag).Error; err != nil {
+		r.logger.Error("repo.tag.update failed",
+			slog.String("op", "repo.tag.update"),
+			slog.Uint64("id", uint64(tag.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// Delete переносит метку в корзину; связи с расходами сохраняются, чтобы вернуться при восстановлении
+func (r *gormTagRepository) Delete(id uint) error {
+	r.logger.Debug("repo.tag.delete",
+		slog.String("op", "repo.tag.delete"),
+		slog.Uint64("id", uint64(id)),
+	)
+	if err := r.db.Delete(&models.Tag{}, id).Error; err != nil {
+		r.logger.Error("repo.tag.delete failed",
+			slog.String("op", "repo.tag.delete"),
+			slog.Uint64("id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errUnknownTrashEntityType = errors.New("unknown trash entity type")
+
+	// ErrRestoreConflict возвращается, если на место удаленной записи уже создана новая, например бюджет на тот же месяц
+	// или метка с тем же названием
+	ErrRestoreConflict = errors.New("restored record conflicts with an active one")
+)
+
+type TrashRepository interface {
+	List(filter models.TrashFilter) ([]models.TrashItem, error)
+	Restore(userID uint, entityType string, id uint) (*models.TrashRestoreResult, error)
+	Purge(deletedBefore time.Time) (map[string]int64, error)
+}
+
+type gormTrashRepository struct {
+	db     *gorm.DB
+	logger *slog.Logger
+}
+
+func NewTrashRepository(db *gorm.DB, logger *slog.Logger) TrashRepository {
+	return &gormTrashRepository{db: db, logger: logger}
+}
+
+// trashSource описывает, как показать удаленные записи одной таблицы в общем списке корзины
+type trashSource struct {
+	entityType string
+	table      string
+	title      string // SQL выражение заголовка записи
+	amount     string // SQL выражение суммы
+	currency   string // SQL выражение валюты
+}
+
+var trashSources = []trashSource{
+	{models.EntityTypeExpense, "expenses", "description", "amount", "currency"},
+	{models.EntityTypeIncome, "incomes", "description", "amount", "currency"},
+	{models.EntityTypeTransfer, "transfers", "description", "amount",
+		"(SELECT a.currency FROM accounts a WHERE a.id = transfers.from_account_id)"},
+	{models.EntityTypeAccount, "accounts", "name", "balance", "currency"},
+	{models.EntityTypeCategory, "categories", "name", "NULL::numeric", "NULL"},
+	{models.EntityTypeBudget, "budgets", "concat(year, '-', lpad(month::text, 2, '0'))", "amount", "currency"},
+	{models.EntityTypeRecurringExpense, "recurring_expenses", "description", "amount", "currency"},
+	{models.EntityTypeCategorizationRule, "categorization_rules", "name", "NULL::numeric", "NULL"},
+	{models.EntityTypeTag, "tags", "name", "NULL::numeric", "NULL"},
+}
+
+// List возвращает удаленные записи пользователя из всех таблиц, сначала удаленные последними
+func (r *gormTrashRepository) List(filter models.TrashFilter) ([]models.TrashItem, error) {
+	r.logger.Debug("repo.trash.list",
+		slog.String("op", "repo.trash.list"),
+		slog.Uint64("user_id", uint64(filter.UserID)),
+	)
+
+	var (
+		parts []string
+		args  []interface{}
+	)
+	for _, source := range trashSources {
+		if filter.EntityType != nil && *filter.EntityType != source.entityType {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf(
+			`SELECT '%s' AS entity_type, id AS entity_id, COALESCE(%s, '') AS title, %s AS amount, COALESCE(%s, '') AS currency, deleted_at
+			FROM %s WHERE user_id = ? AND deleted_at IS NOT NULL`,
+			source.entityType, source.title, source.amount, source.currency, source.table,
+		))
+		args = append(args, filter.UserID)
+	}
+	if len(parts) == 0 {
+		return nil, errUnknownTrashEntityType
+	}
+
+	query := strings.Join(parts, " UNION ALL ") + " ORDER BY deleted_at DESC, entity_type, entity_id"
+	if filter.Limit != nil {
+		query += " LIMIT ?"
+		args = append(args, *filter.Limit)
+	}
+	if filter.Offset != nil {
+		query += " OFFSET ?"
+		args = append(args, *filter.Offset)
+	}
+
+	var items []models.TrashItem
+	if err := r.db.Raw(query, args...).Scan(&items).Error; err != nil {
+		r.logger.Error("repo.trash.list failed",
+			slog.String("op", "repo.trash.list"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return items, nil
+}
+
+// Restore в одной транзакции восстанавливает запись пользователя вместе с удаленными категориями и счетами,
+// на которые она ссылается, и заново применяет операцию к остаткам счетов.
+// Запись, которой нет в корзине пользователя, дает gorm.ErrRecordNotFound.
+func (r *gormTrashRepository) Restore(userID uint, entityType string, id uint) (*models.TrashRestoreResult, error) {
+	r.logger.Debug("repo.trash.restore",
+		slog.String("op", "repo.trash.restore"),
+		slog.String("entity_type", entityType),
+		slog.Uint64("id", uint64(id)),
+	)
+
+	result := &models.TrashRestoreResult{EntityType: entityType, EntityID: id, Dependencies: []models.TrashEntityRef{}}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		t := &trashRestore{tx: tx, userID: userID, result: result}
+		switch entityType {
+		case models.EntityTypeExpense:
+			return t.expense(id)
+		case models.EntityTypeIncome:
+			return t.income(id)
+		case models.EntityTypeTransfer:
+			return t.transfer(id)
+		case models.EntityTypeAccount:
+			return t.account(id)
+		case models.EntityTypeCategory:
+			return t.category(id)
+		case models.EntityTypeBudget:
+			return t.budget(id)
+		case models.EntityTypeRecurringExpense:
+			return t.recurringExpense(id)
+		case models.EntityTypeCategorizationRule:
+			return t.categorizationRule(id)
+		case models.EntityTypeTag:
+			return t.tag(id)
+		}
+		return errUnknownTrashEntityType
+	})
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, ErrRestoreConflict) {
+			r.logger.Error("repo.trash.restore failed",
+				slog.String("op", "repo.trash.restore"),
+				slog.String("entity_type", entityType),
+				slog.Uint64("id", uint64(id)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// Purge окончательно удаляет записи, удаленные раньше deletedBefore, и возвращает их число по типам.
+// Категории и счета, на которые еще ссылаются другие записи, остаются до следующей очистки.
+func (r *gormTrashRepository) Purge(deletedBefore time.Time) (map[string]int64, error) {
+	r.logger.Debug("repo.trash.purge",
+		slog.String("op", "repo.trash.purge"),
+		slog.Time("deleted_before", deletedBefore),
+	)
+
+	purged := make(map[string]int64, len(trashSources))
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		expired := func() *gorm.DB {
+			return tx.Unscoped().Where("deleted_at < ?", deletedBefore)
+		}
+
+		// Уведомления ссылаются на бюджет и удаляются вместе с ним
+		expiredBudgets := tx.Unscoped().Model(&models.Budget{}).Select("id").Where("deleted_at < ?", deletedBefore)
+		if err := tx.Unscoped().Where("budget_id IN (?)", expiredBudgets).Delete(&models.BudgetAlert{}).Error; err != nil {
+			return err
+		}
+
+		// Связи с метками удаляются вместе с расходом или меткой
+		expiredExpenses := tx.Unscoped().Model(&models.Expense{}).Select("id").Where("deleted_at < ?", deletedBefore)
+		expiredTags := tx.Unscoped().Model(&models.Tag{}).Select("id").Where("deleted_at < ?", deletedBefore)
+		err := tx.Where("expense_id IN (?) OR tag_id IN (?)", expiredExpenses, expiredTags).Delete(&expenseTag{}).Error
+		if err != nil {
+			return err
+		}
+
+		// Сначала записи, которые ссылаются на категории и счета, затем сами категории и счета
+		steps := []struct {
+			entityType string
+			query      *gorm.DB
+			model      interface{}
+		}{
+			{models.EntityTypeExpense, expired(), &models.Expense{}},
+			{models.EntityTypeIncome, expired(), &models.Income{}},
+			{models.EntityTypeTransfer, expired(), &models.Transfer{}},
+			{models.EntityTypeRecurringExpense, expired(), &models.RecurringExpense{}},
+			{models.EntityTypeCategorizationRule, expired(), &models.CategorizationRule{}},
+			{models.EntityTypeBudget, expired(), &models.Budget{}},
+			{models.EntityTypeTag, expired(), &models.Tag{}},
+			{models.EntityTypeCategory, expired().
+				Where("NOT EXISTS (SELECT 1 FROM expenses e WHERE e.category_id = categories.id)").
+				Where("NOT EXISTS (SELECT 1 FROM incomes i WHERE i.category_id = categories.id)").
+				Where("NOT EXISTS (SELECT 1 FROM recurring_expenses re WHERE re.category_id = categories.id)").
+				Where("NOT EXISTS (SELECT 1 FROM categorization_rules cr WHERE cr.category_id = categories.id)").
+				Where("NOT EXISTS (SELECT 1 FROM budgets b WHERE b.category_id = categories.id)").
+				Where("NOT EXISTS (SELECT 1 FROM categories c WHERE c.parent_id = categories.id)"), &models.Category{}},
+			{models.EntityTypeAccount, expired().
+				Where("NOT EXISTS (SELECT 1 FROM expenses e WHERE e.account_id = accounts.id)").
+				Where("NOT EXISTS (SELECT 1 FROM incomes i WHERE i.account_id = accounts.id)").
+				Where("NOT EXISTS (SELECT 1 FROM recurring_expenses re WHERE re.account_id = accounts.id)").
+				Where("NOT EXISTS (SELECT 1 FROM categorization_rules cr WHERE cr.account_id = accounts.id)").
+				Where("NOT EXISTS (SELECT 1 FROM transfers t WHERE t.from_account_id = accounts.id OR t.to_account_id = accounts.id)"), &models.Account{}},
+		}
+		for _, step := range steps {
+			res := step.query.Delete(step.model)
+			if res.Error != nil {
+				return res.Error
+			}
+			purged[step.entityType] = res.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.Error("repo.trash.purge failed",
+			slog.String("op", "repo.trash.purge"),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return purged, nil
+}
+
+// trashRestore восстанавливает записи внутри транзакции и запоминает восстановленные вместе с ними зависимости
+type trashRestore struct {
+	tx     *gorm.DB
+	userID uint
+	result *models.TrashRestoreResult
+}
+
+// lockDeleted читает с блокировкой запись пользователя, которая находится в корзине
+func (t *trashRestore) lockDeleted(dest interface{}, id uint) error {
+	return t.tx.Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", t.userID).
+		First(dest, id).Error
+}
+
+func (t *trashRestore) undelete(model interface{}, id uint) error {
+	return t.tx.Unscoped().Model(model).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// dependency восстанавливает запись, на которую ссылается восстанавливаемая, если она тоже в корзине
+func (t *trashRestore) dependency(model interface{}, entityType string, id uint) (bool, error) {
+	res := t.tx.Unscoped().Model(model).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, t.userID).
+		Update("deleted_at", nil)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+	t.result.Dependencies = append(t.result.Dependencies, models.TrashEntityRef{EntityType: entityType, EntityID: id})
+	return true, nil
+}
+
+func (t *trashRestore) accountDependency(id *uint) error {
+	if id == nil {
+		return nil
+	}
+	_, err := t.dependency(&models.Account{}, models.EntityTypeAccount, *id)
+	return err
+}
+
+// categoryDependency восстанавливает категорию операции вместе с удаленными родителями
+func (t *trashRestore) categoryDependency(id uint) error {
+	restored, err := t.dependency(&models.Category{}, models.EntityTypeCategory, id)
+	if err != nil || !restored {
+		return err
+	}
+	var category models.Category
+	if err := t.tx.Unscoped().First(&category, id).Error; err != nil {
+		return err
+	}
+	return t.ancestors(category.ParentID)
+}
+
+// ancestors восстанавливает удаленных родителей категории, чтобы она вернулась на свое место в дереве.
+// У активной категории удаленных родителей нет: при удалении родителя дочерние поднимаются на уровень выше.
+func (t *trashRestore) ancestors(parentID *uint) error {
+	for parentID != nil {
+		restored, err := t.dependency(&models.Category{}, models.EntityTypeCategory, *parentID)
+		if err != nil || !restored {
+			return err
+		}
+		var parent models.Category
+		if err := t.tx.Unscoped().First(&parent, *parentID).Error; err != nil {
+			return err
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
+func (t *trashRestore) expense(id uint) error {
+	var expense models.Expense
+	if err := t.lockDeleted(&expense, id); err != nil {
+		return err
+	}
+	if err := t.categoryDependency(expense.CategoryID); err != nil {
+		return err
+	}
+	if err := t.accountDependency(expense.AccountID); err != nil {
+		return err
+	}
+	if err := t.undelete(&models.Expense{}, id); err != nil {
+		return err
+	}
+	return adjustAccountBalance(t.tx, expense.AccountID, -expense.Amount)
+}
+
+func (t *trashRestore) income(id uint) error {
+	var income models.Income
+	if err := t.lockDeleted(&income, id); err != nil {
+		return err
+	}
+	if err := t.categoryDependency(income.CategoryID); err != nil {
+		return err
+	}
+	if err := t.accountDependency(income.AccountID); err != nil {
+		return err
+	}
+	if err := t.undelete(&models.Income{}, id); err != nil {
+		return err
+	}
+	return adjustAccountBalance(t.tx, income.AccountID, income.Amount)
+}
+
+func (t *trashRestore) transfer(id uint) error {
+	var transfer models.Transfer
+	if err := t.lockDeleted(&transfer, id); err != nil {
+		return err
+	}
+	if err := t.accountDependency(&transfer.FromAccountID); err != nil {
+		return err
+	}
+	if err := t.accountDependency(&transfer.ToAccountID); err != nil {
+		return err
+	}
+	if err := t.undelete(&models.Transfer{}, id); err != nil {
+		return err
+	}
+	if err := adjustAccountBalance(t.tx, &transfer.FromAccountID, -transfer.Amount); err != nil {
+		return err
+	}
+	return adjustAccountBalance(t.tx, &transfer.ToAccountID, transfer.ToAmount)
+}
+
+func (t *trashRestore) account(id uint) error {
+	var account models.Account
+	if err := t.lockDeleted(&account, id); err != nil {
+		return err
+	}
+	return t.undelete(&models.Account{}, id)
+}
+
+func (t *trashRestore) category(id uint) error {
+	var category models.Category
+	if err := t.lockDeleted(&category, id); err != nil {
+		return err
+	}
+	if err := t.ancestors(category.ParentID); err != nil {
+		return err
+	}
+	return t.undelete(&models.Category{}, id)
+}
+
+// budget восстанавливает бюджет, если на его месяц и категорию не создан новый
+func (t *trashRestore) budget(id uint) error {
+	var budget models.Budget
+	if err := t.lockDeleted(&budget, id); err != nil {
+		return err
+	}
+
+	query := t.tx.Model(&models.Budget{}).Where("user_id = ? AND year = ? AND month = ?", budget.UserID, budget.Year, budget.Month)
+	if budget.CategoryID != nil {
+		query = query.Where("category_id = ?", *budget.CategoryID)
+	} else {
+		query = query.Where("category_id IS NULL")
+	}
+	var active int64
+	if err := query.Count(&active).Error; err != nil {
+		return err
+	}
+	if active > 0 {
+		return ErrRestoreConflict
+	}
+
+	if budget.CategoryID != nil {
+		if err := t.categoryDependency(*budget.CategoryID); err != nil {
+			return err
+		}
+	}
+	return t.undelete(&models.Budget{}, id)
+}
+
+func (t *trashRestore) recurringExpense(id uint) error {
+	var recurringExpense models.RecurringExpense
+	if err := t.lockDeleted(&recurringExpense, id); err != nil {
+		return err
+	}
+	if err := t.categoryDependency(recurringExpense.CategoryID); err != nil {
+		return err
+	}
+	if err := t.accountDependency(recurringExpense.AccountID); err != nil {
+		return err
+	}
+	return t.undelete(&models.RecurringExpense{}, id)
+}
+
+func (t *trashRestore) categorizationRule(id uint) error {
+	var rule models.CategorizationRule
+	if err := t.lockDeleted(&rule, id); err != nil {
+		return err
+	}
+	if err := t.categoryDependency(rule.CategoryID); err != nil {
+		return err
+	}
+	if err := t.accountDependency(rule.AccountID); err != nil {
+		return err
+	}
+	return t.undelete(&models.CategorizationRule{}, id)
+}
+
+// tag восстанавливает метку вместе с ее связями с расходами, если ее название не занято новой меткой
+func (t *trashRestore) tag(id uint) error {
+	var tag models.Tag
+	if err := t.lockDeleted(&tag, id); err != nil {
+		return err
+	}
+
+	var active int64
+	err := t.tx.Model(&models.Tag{}).
+		Where("user_id = ? AND lower(name) = lower(?)", tag.UserID, tag.Name).
+		Count(&active).Error
+	if err != nil {
+		return err
+	}
+	if active > 0 {
+		return ErrRestoreConflict
+	}
+	return t.undelete(&models.Tag{}, id)
+}
@@ -4,6 +4,7 @@ import (
 	"cashcontrol/internal/models"
 	"errors"
 	"log/slog"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -11,10 +12,10 @@ import (
 var errUserNil error = errors.New("user is nil")
 
 type UserRepository interface {
-	List() ([]models.User, error)
 	GetByID(id uint) (*models.User, error)
 	GetByEmail(email string) (*models.User, error)
 	Create(user *models.User) error
+	CreateWithCategories(user *models.User, categories []models.DefaultCategory) error
 	Update(user *models.User) error
 	Delete(id uint) error
 }
@@ -28,21 +29,6 @@ func NewUserRepository(db *gorm.DB, logger *slog.Logger) UserRepository {
 	return &gormUserRepository{db: db, logger: logger}
 }
 
-func (r *gormUserRepository) List() ([]models.User, error) {
-	r.logger.Debug("repo.user.list",
-		slog.String("op", "repo.user.list"),
-	)
-	var users []models.User
-	if err := r.db.Find(&users).Error; err != nil {
-		r.logger.Error("repo.user.list failed",
-			slog.String("op", "repo.user.list"),
-			slog.String("error", err.Error()),
-		)
-		return nil, err
-	}
-	return users, nil
-}
-
 func (r *gormUserRepository) GetByID(id uint) (*models.User, error) {
 	r.logger.Debug("repo.user.get_by_id",
 		slog.String("op", "repo.user.get_by_id"),
@@ -100,6 +86,58 @@ func (r *gormUserRepository) Create(user *models.User) error {
 	return nil
 }
 
+// CreateWithCategories создает пользователя вместе с категориями по умолчанию одной транзакцией,
+// чтобы пользователь не остался без категорий при ошибке
+func (r *gormUserRepository) CreateWithCategories(user *models.User, categories []models.DefaultCategory) error {
+	if user == nil {
+		return errUserNil
+	}
+
+	r.logger.Debug("repo.user.create_with_categories",
+		slog.String("op", "repo.user.create_with_categories"),
+		slog.String("email", user.Email),
+		slog.Int("categories", len(categories)),
+	)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return createDefaultCategories(tx, user.ID, nil, categories)
+	})
+	if err != nil {
+		r.logger.Error("repo.user.create_with_categories failed",
+			slog.String("op", "repo.user.create_with_categories"),
+			slog.String("email", user.Email),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// createDefaultCategories создает категории уровня с родителем parentID и рекурсивно их подкатегории
+func createDefaultCategories(tx *gorm.DB, userID uint, parentID *uint, defaults []models.DefaultCategory) error {
+	for _, d := range defaults {
+		category := models.Category{
+			UserID:    userID,
+			ParentID:  parentID,
+			Name:      d.Name,
+			Type:      d.Type,
+			Color:     d.Color,
+			Icon:      d.Icon,
+			IsDefault: true,
+		}
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+		if err := createDefaultCategories(tx, userID, &category.ID, d.Children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *gormUserRepository) Update(user *models.User) error {
 	if user == nil {
 		return errUserNil
@@ -120,12 +158,23 @@ func (r *gormUserRepository) Update(user *models.User) error {
 	return nil
 }
 
+// Delete удаляет пользователя и в той же транзакции отзывает все его refresh токены,
+// чтобы выданные ранее токены доступа перестали действовать
 func (r *gormUserRepository) Delete(id uint) error {
 	r.logger.Debug("repo.user.delete",
 		slog.String("op", "repo.user.delete"),
 		slog.Uint64("id", uint64(id)),
 	)
-	if err := r.db.Delete(&models.User{}, id).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, id).Error
+	})
+	if err != nil {
 		r.logger.Error("repo.user.delete failed",
 			slog.String("op", "repo.user.delete"),
 			slog.Uint64("id", uint64(id)),
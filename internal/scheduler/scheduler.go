@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Job периодическая фоновая задача
+type Job struct {
+	Name     string                          // Название задачи для логов
+	Interval time.Duration                   // Интервал между запусками
+	LockKey  int64                           // Ключ advisory lock, чтобы задачу выполняла только одна реплика
+	Run      func(ctx context.Context) error // Тело задачи
+}
+
+type Scheduler struct {
+	db     *gorm.DB
+	jobs   []Job
+	logger *slog.Logger
+}
+
+func New(db *gorm.DB, logger *slog.Logger) *Scheduler {
+	return &Scheduler{db: db, logger: logger}
+}
+
+// Add регистрирует задачу; задачи с нулевым интервалом отключены и пропускаются
+func (s *Scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		s.logger.Info("scheduler job disabled", slog.String("job", job.Name))
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Run запускает все задачи и блокируется до отмены контекста и завершения текущих запусков
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	s.logger.Info("scheduler job started",
+		slog.String("job", job.Name),
+		slog.Duration("interval", job.Interval),
+	)
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	// Первый запуск сразу при старте, чтобы догнать пропущенное за время простоя
+	s.runOnce(ctx, job)
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("scheduler job stopped", slog.String("job", job.Name))
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, job)
+		}
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	started := time.Now()
+
+	acquired, err := s.withAdvisoryLock(ctx, job.LockKey, job.Run)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return
+	case err != nil:
+		s.logger.Error("scheduler job failed",
+			slog.String("job", job.Name),
+			slog.String("error", err.Error()),
+		)
+	case !acquired:
+		s.logger.Debug("scheduler job skipped, lock held by another instance",
+			slog.String("job", job.Name),
+		)
+	default:
+		s.logger.Info("scheduler job finished",
+			slog.String("job", job.Name),
+			slog.Duration("duration", time.Since(started)),
+		)
+	}
+}
+
+// withAdvisoryLock выполняет fn, только если удалось взять сессионный advisory lock Postgres.
+// Lock берется на выделенном соединении, так как он привязан к сессии.
+func (s *Scheduler) withAdvisoryLock(ctx context.Context, key int64, fn func(ctx context.Context) error) (bool, error) {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return false, fmt.Errorf("получение sql.DB: %w", err)
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("получение соединения: %w", err)
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		return false, fmt.Errorf("захват advisory lock: %w", err)
+	}
+	if !acquired {
+		return false, nil
+	}
+
+	defer func() {
+		// Снимаем lock даже при отмененном контексте, иначе он останется до закрытия соединения
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			s.logger.Error("failed to release advisory lock",
+				slog.Int64("lock_key", key),
+				slog.String("error", err.Error()),
+			)
+		}
+	}()
+
+	return true, fn(ctx)
+}
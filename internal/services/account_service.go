@@ -0,0 +1,482 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
+	"cashcontrol/internal/repository"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAccountNotFound         = errors.New("счет не найден")
+	ErrTransferNotFound        = errors.New("перевод не найден")
+	ErrAccountCurrencyMismatch = errors.New("валюта операции должна совпадать с валютой счета")
+	ErrAccountInUse            = errors.New("к счету привязаны операции, удалите или перенесите их")
+	ErrSameAccountTransfer     = errors.New("счета списания и зачисления должны различаться")
+)
+
+type AccountService interface {
+	CreateAccount(userID uint, req models.CreateAccountRequest) (*models.Account, error)
+	GetAccountList(userID uint) ([]models.Account, error)
+	GetAccountByID(userID, id uint) (*models.Account, error)
+	UpdateAccount(userID, id uint, req models.UpdateAccountRequest) (*models.Account, error)
+	DeleteAccount(userID, id uint) error
+	CreateTransfer(userID uint, req models.CreateTransferRequest) (*models.Transfer, error)
+	GetTransferList(filter models.TransferFilter) ([]models.Transfer, error)
+	DeleteTransfer(userID, id uint) error
+}
+
+type accountService struct {
+	accounts     repository.AccountRepository
+	currencies   CurrencyService
+	activityLogs ActivityLogService
+	logger       *slog.Logger
+}
+
+func NewAccountService(
+	accounts repository.AccountRepository,
+	currencies CurrencyService,
+	activityLogs ActivityLogService,
+	logger *slog.Logger,
+) AccountService {
+	return &accountService{
+		accounts:     accounts,
+		currencies:   currencies,
+		activityLogs: activityLogs,
+		logger:       logger,
+	}
+}
+
+func (s *accountService) CreateAccount(userID uint, req models.CreateAccountRequest) (*models.Account, error) {
+	if err := validateAccountFields(req.Name, req.Type); err != nil {
+		s.logger.Warn("account create validation failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("name", req.Name),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	currency, err := s.currencies.ResolveCurrency(userID, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	initial := req.InitialBalance.Round(currency)
+
+	account := &models.Account{
+		UserID:         userID,
+		Name:           strings.TrimSpace(req.Name),
+		Type:           req.Type,
+		Currency:       currency,
+		InitialBalance: initial,
+		Balance:        initial,
+	}
+	if err := s.accounts.Create(account); err != nil {
+		s.logger.Error("account create failed",
+			slog.String("op", "create_account"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("account created",
+		slog.Uint64("account_id", uint64(account.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("type", string(account.Type)),
+		slog.String("currency", account.Currency),
+		slog.String("balance", account.Balance.String()),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeAccountCreated,
+		EntityType:   models.EntityTypeAccount,
+		EntityID:     account.ID,
+		Description:  "создан счет",
+		Metadata:     activityMetadata(nil, account),
+	})
+
+	return account, nil
+}
+
+func (s *accountService) GetAccountList(userID uint) ([]models.Account, error) {
+	accounts, err := s.accounts.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to list accounts",
+			slog.String("op", "list_accounts"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("accounts listed",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(accounts)),
+	)
+
+	return accounts, nil
+}
+
+func (s *accountService) GetAccountByID(userID, id uint) (*models.Account, error) {
+	account, err := s.getOwnedAccount(userID, id, "get_account_by_id")
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("account retrieved",
+		slog.Uint64("account_id", uint64(account.ID)),
+		slog.String("balance", account.Balance.String()),
+	)
+
+	return account, nil
+}
+
+// UpdateAccount меняет название и тип счета. Валюта и остаток не редактируются:
+// остаток меняется только расходами, доходами и переводами.
+func (s *accountService) UpdateAccount(userID, id uint, req models.UpdateAccountRequest) (*models.Account, error) {
+	account, err := s.getOwnedAccount(userID, id, "update_account")
+	if err != nil {
+		return nil, err
+	}
+
+	before := *account
+
+	if req.Name != nil {
+		account.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Type != nil {
+		account.Type = *req.Type
+	}
+	if err := validateAccountFields(account.Name, account.Type); err != nil {
+		s.logger.Warn("account update validation failed",
+			slog.Uint64("account_id", uint64(id)),
+			slog.Any("request", req),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := s.accounts.Update(account); err != nil {
+		s.logger.Error("account update failed",
+			slog.String("op", "update_account"),
+			slog.Uint64("account_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("account updated",
+		slog.Uint64("account_id", uint64(id)),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeAccountUpdated,
+		EntityType:   models.EntityTypeAccount,
+		EntityID:     account.ID,
+		Description:  "изменен счет",
+		Metadata:     activityMetadata(before, account),
+	})
+
+	return account, nil
+}
+
+// DeleteAccount удаляет счет без операций; иначе остатки связанных операций потеряли бы источник
+func (s *accountService) DeleteAccount(userID, id uint) error {
+	account, err := s.getOwnedAccount(userID, id, "delete_account")
+	if err != nil {
+		return err
+	}
+
+	count, err := s.accounts.CountOperations(id)
+	if err != nil {
+		s.logger.Error("failed to count account operations",
+			slog.String("op", "delete_account"),
+			slog.Uint64("account_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	if count > 0 {
+		s.logger.Warn("account has operations",
+			slog.Uint64("account_id", uint64(id)),
+			slog.Int64("operations", count),
+		)
+		return ErrAccountInUse
+	}
+
+	if err := s.accounts.Delete(id); err != nil {
+		s.logger.Error("account delete failed",
+			slog.String("op", "delete_account"),
+			slog.Uint64("account_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("account deleted",
+		slog.Uint64("account_id", uint64(id)),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeAccountDeleted,
+		EntityType:   models.EntityTypeAccount,
+		EntityID:     id,
+		Description:  "удален счет",
+		Metadata:     activityMetadata(account, nil),
+	})
+
+	return nil
+}
+
+// CreateTransfer переводит деньги между счетами пользователя. Перевод не является расходом
+// и не учитывается в бюджетах и статистике. Для счетов в разных валютах сумма зачисления
+// берется из запроса или пересчитывается по курсу на дату перевода.
+func (s *accountService) CreateTransfer(userID uint, req models.CreateTransferRequest) (*models.Transfer, error) {
+	if req.FromAccountID == req.ToAccountID {
+		return nil, ErrSameAccountTransfer
+	}
+
+	from, err := accountReference(s.accounts, s.logger, userID, req.FromAccountID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := accountReference(s.accounts, s.logger, userID, req.ToAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := roundToCurrency(req.Amount, from.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	var toAmount money.Amount
+	switch {
+	case req.ToAmount != nil:
+		if toAmount, err = roundToCurrency(*req.ToAmount, to.Currency); err != nil {
+			return nil, err
+		}
+	case from.Currency == to.Currency:
+		toAmount = amount
+	default:
+		rate, err := s.currencies.Rate(userID, from.Currency, to.Currency, req.Date)
+		if err != nil {
+			return nil, err
+		}
+		toAmount = amount.MulRate(rate, to.Currency)
+	}
+
+	transfer := &models.Transfer{
+		UserID:        userID,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		ToAmount:      toAmount,
+		Description:   req.Description,
+		Date:          req.Date,
+	}
+	if err := s.accounts.CreateTransfer(transfer); err != nil {
+		s.logger.Error("transfer create failed",
+			slog.String("op", "create_transfer"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("transfer created",
+		slog.Uint64("transfer_id", uint64(transfer.ID)),
+		slog.Uint64("from_account_id", uint64(from.ID)),
+		slog.Uint64("to_account_id", uint64(to.ID)),
+		slog.String("amount", amount.String()),
+		slog.String("to_amount", toAmount.String()),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeTransferCreated,
+		EntityType:   models.EntityTypeTransfer,
+		EntityID:     transfer.ID,
+		Description:  "создан перевод между счетами",
+		Metadata:     activityMetadata(nil, transfer),
+	})
+
+	return transfer, nil
+}
+
+func (s *accountService) GetTransferList(filter models.TransferFilter) ([]models.Transfer, error) {
+	transfers, err := s.accounts.ListTransfers(filter)
+	if err != nil {
+		s.logger.Error("failed to list transfers",
+			slog.String("op", "list_transfers"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("transfers listed",
+		slog.Uint64("user_id", uint64(filter.UserID)),
+		slog.Int("count", len(transfers)),
+	)
+
+	return transfers, nil
+}
+
+// DeleteTransfer удаляет перевод и возвращает суммы на оба счета
+func (s *accountService) DeleteTransfer(userID, id uint) error {
+	transfer, err := s.accounts.GetTransferByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("transfer not found",
+				slog.Uint64("transfer_id", uint64(id)),
+			)
+			return ErrTransferNotFound
+		}
+		s.logger.Error("failed to fetch transfer",
+			slog.String("op", "delete_transfer"),
+			slog.Uint64("transfer_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	if transfer.UserID != userID {
+		s.logger.Warn("transfer belongs to another user",
+			slog.Uint64("transfer_id", uint64(id)),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		return ErrForbidden
+	}
+
+	if err := s.accounts.DeleteTransfer(id); err != nil {
+		s.logger.Error("transfer delete failed",
+			slog.String("op", "delete_transfer"),
+			slog.Uint64("transfer_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("transfer deleted",
+		slog.Uint64("transfer_id", uint64(id)),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeTransferDeleted,
+		EntityType:   models.EntityTypeTransfer,
+		EntityID:     id,
+		Description:  "удален перевод между счетами",
+		Metadata:     activityMetadata(transfer, nil),
+	})
+
+	return nil
+}
+
+// getOwnedAccount загружает счет и проверяет, что он принадлежит пользователю
+func (s *accountService) getOwnedAccount(userID, id uint, op string) (*models.Account, error) {
+	account, err := s.accounts.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("account not found",
+				slog.String("op", op),
+				slog.Uint64("account_id", uint64(id)),
+			)
+			return nil, ErrAccountNotFound
+		}
+		s.logger.Error("failed to fetch account",
+			slog.String("op", op),
+			slog.Uint64("account_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if account.UserID != userID {
+		s.logger.Warn("account belongs to another user",
+			slog.String("op", op),
+			slog.Uint64("account_id", uint64(id)),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		return nil, ErrForbidden
+	}
+
+	return account, nil
+}
+
+// accountReference проверяет счет, указанный в операции; чужой счет выглядит как несуществующий
+func accountReference(accounts repository.AccountRepository, logger *slog.Logger, userID, accountID uint) (*models.Account, error) {
+	account, err := accounts.GetByID(accountID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccountNotFound
+		}
+		logger.Error("failed to check account existence",
+			slog.Uint64("account_id", uint64(accountID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, errors.New("ошибка при проверке счета")
+	}
+
+	if account.UserID != userID {
+		logger.Warn("account belongs to another user",
+			slog.Uint64("account_id", uint64(accountID)),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		return nil, ErrAccountNotFound
+	}
+
+	return account, nil
+}
+
+// operationCurrency определяет валюту расхода или дохода. Операция по счету ведется в валюте счета:
+// пустой код заменяется ею, другой код отклоняется. Без счета пустой код заменяется базовой валютой.
+func operationCurrency(accounts repository.AccountRepository, currencies CurrencyService, logger *slog.Logger, userID uint, accountID *uint, code string) (string, error) {
+	if accountID == nil {
+		return currencies.ResolveCurrency(userID, code)
+	}
+
+	account, err := accountReference(accounts, logger, userID, *accountID)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(code) == "" {
+		return account.Currency, nil
+	}
+
+	currency, err := normalizeCurrency(code)
+	if err != nil {
+		return "", err
+	}
+	if currency != account.Currency {
+		return "", ErrAccountCurrencyMismatch
+	}
+	return currency, nil
+}
+
+// accountIDUpdate возвращает новый счет операции из запроса на изменение: 0 отвязывает операцию от счета
+func accountIDUpdate(accountID uint) *uint {
+	if accountID == 0 {
+		return nil
+	}
+	return &accountID
+}
+
+func validateAccountFields(name string, accountType models.AccountType) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("название счета не может быть пустым")
+	}
+	switch accountType {
+	case models.AccountTypeCash, models.AccountTypeCard, models.AccountTypeBank, models.AccountTypeSavings:
+		return nil
+	}
+	return errors.New("тип счета должен быть одним из: cash, card, bank, savings")
+}
@@ -10,7 +10,7 @@ import (
 
 type ActivityLogService interface {
 	CreateActivityLog(req models.CreateActivityLogRequest) (*models.ActivityHistory, error)
-	GetActivityLogs(filter models.ActivityFilter) ([]models.ActivityHistory, error)
+	GetActivityLogs(filter models.ActivityFilter, page models.PageRequest) (*models.Page[models.ActivityHistory], error)
 }
 
 type activityLogService struct {
@@ -90,16 +90,25 @@ func (s *activityLogService) CreateActivityLog(req models.CreateActivityLogReque
 	return activityLog, nil
 }
 
-func (s *activityLogService) GetActivityLogs(filter models.ActivityFilter) ([]models.ActivityHistory, error) {
+// GetActivityLogs возвращает страницу истории действий, по умолчанию последние действия идут первыми
+func (s *activityLogService) GetActivityLogs(filter models.ActivityFilter, page models.PageRequest) (*models.Page[models.ActivityHistory], error) {
 	const op = "service.activity_log.get"
 
+	page, err := activityLogSorting.normalize(page)
+	if err != nil {
+		return nil, err
+	}
+
 	s.logger.Debug("retrieving activity logs",
 		slog.String("op", op),
 		slog.Uint64("user_id", uint64(filter.UserID)),
 	)
 
-	activityLogs, err := s.activityLog.Get(filter)
+	activityLogs, err := s.activityLog.Get(filter, page)
 	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, ErrInvalidCursor
+		}
 		s.logger.Error("failed to retrieve activity logs",
 			slog.String("op", op),
 			slog.Uint64("user_id", uint64(filter.UserID)),
@@ -111,7 +120,8 @@ func (s *activityLogService) GetActivityLogs(filter models.ActivityFilter) ([]mo
 	s.logger.Info("retrieved activity logs successfully",
 		slog.String("op", op),
 		slog.Uint64("user_id", uint64(filter.UserID)),
-		slog.Int("count", len(activityLogs)),
+		slog.Int("count", len(activityLogs.Items)),
+		slog.Int64("total", activityLogs.Total),
 	)
 
 	return activityLogs, nil
@@ -130,14 +140,33 @@ func (s *activityLogService) validateActivityLogCreate(req models.CreateActivity
 	case models.ActivityTypeExpenseCreated,
 		models.ActivityTypeExpenseUpdated,
 		models.ActivityTypeExpenseDeleted,
+		models.ActivityTypeExpensesImported,
 		models.ActivityTypeCategoryCreated,
 		models.ActivityTypeCategoryUpdated,
 		models.ActivityTypeCategoryDeleted,
+		models.ActivityTypeCategoriesMerged,
 		models.ActivityTypeBudgetCreated,
 		models.ActivityTypeBudgetUpdated,
+		models.ActivityTypeBudgetDeleted,
 		models.ActivityTypeRecurringCreated,
 		models.ActivityTypeRecurringUpdated,
-		models.ActivityTypeRecurringDeleted:
+		models.ActivityTypeRecurringDeleted,
+		models.ActivityTypeIncomeCreated,
+		models.ActivityTypeIncomeUpdated,
+		models.ActivityTypeIncomeDeleted,
+		models.ActivityTypeAccountCreated,
+		models.ActivityTypeAccountUpdated,
+		models.ActivityTypeAccountDeleted,
+		models.ActivityTypeTransferCreated,
+		models.ActivityTypeTransferDeleted,
+		models.ActivityTypeCategorizationRuleCreated,
+		models.ActivityTypeCategorizationRuleUpdated,
+		models.ActivityTypeCategorizationRuleDeleted,
+		models.ActivityTypeExpensesRecategorized,
+		models.ActivityTypeEntityRestored,
+		models.ActivityTypeTagCreated,
+		models.ActivityTypeTagUpdated,
+		models.ActivityTypeTagDeleted:
 	default:
 		return errors.New("invalid activity_type")
 	}
@@ -156,3 +185,32 @@ func (s *activityLogService) validateActivityLogCreate(req models.CreateActivity
 
 	return nil
 }
+
+// recordActivity сохраняет запись в историю действий.
+// Ошибка записи только логируется и не прерывает основную операцию.
+func recordActivity(activityLogs ActivityLogService, logger *slog.Logger, req models.CreateActivityLogRequest) {
+	if activityLogs == nil {
+		return
+	}
+	if _, err := activityLogs.CreateActivityLog(req); err != nil {
+		logger.Warn("failed to record activity",
+			slog.Uint64("user_id", uint64(req.UserID)),
+			slog.String("activity_type", string(req.ActivityType)),
+			slog.String("entity_type", req.EntityType),
+			slog.Uint64("entity_id", uint64(req.EntityID)),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// activityMetadata формирует метаданные записи истории со значениями сущности до и после изменения
+func activityMetadata(before, after interface{}) map[string]interface{} {
+	metadata := make(map[string]interface{}, 2)
+	if before != nil {
+		metadata["before"] = before
+	}
+	if after != nil {
+		metadata["after"] = after
+	}
+	return metadata
+}
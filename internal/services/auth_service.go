@@ -3,6 +3,10 @@ package services
 import (
 	"cashcontrol/internal/models"
 	"cashcontrol/internal/repository"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"log/slog"
 	"time"
@@ -12,21 +16,51 @@ import (
 	"gorm.io/gorm"
 )
 
-var ErrInvalidCredentials = errors.New("неверные учетные данные")
+var (
+	ErrInvalidCredentials = errors.New("неверные учетные данные")
+	ErrInvalidToken       = errors.New("недействительный токен")
+	ErrTokenReused        = errors.New("refresh токен уже был использован, все сессии этой цепочки завершены")
+)
 
 type AuthService interface {
 	Register(req models.RegisterRequest) (*models.LoginResponse, error)
 	Login(req models.LoginRequest) (*models.LoginResponse, error)
+	Refresh(refreshToken string) (*models.LoginResponse, error)
+	Logout(refreshToken string) error
+	LogoutAll(userID uint) error
+	ValidateToken(tokenString string) (uint, error)
 }
 
 type authService struct {
-	users     repository.UserRepository
-	logger    *slog.Logger
-	jwtSecret string
+	users           repository.UserRepository
+	refreshTokens   repository.RefreshTokenRepository
+	logger          *slog.Logger
+	jwtSecret       string
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+
+	defaultCategories *DefaultCategories
 }
 
-func NewAuthService(users repository.UserRepository, logger *slog.Logger, jwtSecret string) AuthService {
-	return &authService{users: users, logger: logger, jwtSecret: jwtSecret}
+func NewAuthService(
+	users repository.UserRepository,
+	refreshTokens repository.RefreshTokenRepository,
+	logger *slog.Logger,
+	jwtSecret string,
+	accessTokenTTL time.Duration,
+	refreshTokenTTL time.Duration,
+	defaultCategories *DefaultCategories,
+) AuthService {
+	return &authService{
+		users:           users,
+		refreshTokens:   refreshTokens,
+		logger:          logger,
+		jwtSecret:       jwtSecret,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+
+		defaultCategories: defaultCategories,
+	}
 }
 
 func (s *authService) Register(req models.RegisterRequest) (*models.LoginResponse, error) {
@@ -56,22 +90,23 @@ func (s *authService) Register(req models.RegisterRequest) (*models.LoginRespons
 	}
 
 	user := &models.User{
-		Email:    req.Email,
-		Username: req.Username,
-		Password: string(hashed),
+		Email:        req.Email,
+		Username:     req.Username,
+		Password:     string(hashed),
+		BaseCurrency: models.DefaultCurrency,
 	}
-
-	if err := s.users.Create(user); err != nil {
-		s.logger.Error("failed to create user", slog.String("error", err.Error()))
-		return nil, err
+	if req.BaseCurrency != "" {
+		// Код уже проверен в validateRegister
+		user.BaseCurrency, _ = normalizeCurrency(req.BaseCurrency)
 	}
 
-	token, err := s.generateToken(user.ID)
-	if err != nil {
+	// Пользователь создается вместе с категориями по умолчанию на его языке
+	if err := s.users.CreateWithCategories(user, s.defaultCategories.ForLocale(req.Locale)); err != nil {
+		s.logger.Error("failed to create user", slog.String("error", err.Error()))
 		return nil, err
 	}
 
-	return &models.LoginResponse{Token: token, User: user}, nil
+	return s.startSession(user)
 }
 
 func (s *authService) Login(req models.LoginRequest) (*models.LoginResponse, error) {
@@ -86,18 +121,173 @@ func (s *authService) Login(req models.LoginRequest) (*models.LoginResponse, err
 		return nil, ErrInvalidCredentials
 	}
 
-	token, err := s.generateToken(user.ID)
+	return s.startSession(user)
+}
+
+// Refresh обменивает refresh токен на новую пару токенов с ротацией.
+// Повторное предъявление уже ротированного токена считается утечкой и завершает всю цепочку сессии.
+func (s *authService) Refresh(refreshToken string) (*models.LoginResponse, error) {
+	stored, err := s.refreshTokens.GetByHash(hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("unknown refresh token presented")
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		s.revokeReusedFamily(stored)
+		return nil, ErrTokenReused
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		s.logger.Warn("expired refresh token presented",
+			slog.Uint64("user_id", uint64(stored.UserID)),
+			slog.String("family_id", stored.FamilyID),
+		)
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.users.GetByID(stored.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	plain, next, err := s.newRefreshToken(user.ID, stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokens.Rotate(stored.ID, next); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenRevoked) {
+			// Токен успели ротировать параллельно — это такое же повторное использование
+			s.revokeReusedFamily(stored)
+			return nil, ErrTokenReused
+		}
+		return nil, err
+	}
+
+	s.logger.Info("refresh token rotated",
+		slog.Uint64("user_id", uint64(user.ID)),
+		slog.String("family_id", stored.FamilyID),
+	)
+
+	return s.buildLoginResponse(user, plain, next)
+}
+
+// Logout завершает сессию, к которой относится refresh токен
+func (s *authService) Logout(refreshToken string) error {
+	stored, err := s.refreshTokens.GetByHash(hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+
+	if err := s.refreshTokens.RevokeFamily(stored.FamilyID); err != nil {
+		return err
+	}
+
+	s.logger.Info("session logged out",
+		slog.Uint64("user_id", uint64(stored.UserID)),
+		slog.String("family_id", stored.FamilyID),
+	)
+
+	return nil
+}
+
+// LogoutAll завершает все сессии пользователя на всех устройствах
+func (s *authService) LogoutAll(userID uint) error {
+	if err := s.refreshTokens.RevokeAllByUserID(userID); err != nil {
+		return err
+	}
+
+	s.logger.Info("all sessions logged out",
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	return nil
+}
+
+// startSession открывает новую цепочку refresh токенов для пользователя
+func (s *authService) startSession(user *models.User) (*models.LoginResponse, error) {
+	familyID, err := randomToken(16)
+	if err != nil {
+		s.logger.Error("failed to generate session id", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	plain, refresh, err := s.newRefreshToken(user.ID, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokens.Create(refresh); err != nil {
+		s.logger.Error("failed to store refresh token",
+			slog.Uint64("user_id", uint64(user.ID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	return s.buildLoginResponse(user, plain, refresh)
+}
+
+func (s *authService) buildLoginResponse(user *models.User, plainRefresh string, refresh *models.RefreshToken) (*models.LoginResponse, error) {
+	accessExpiresAt := time.Now().Add(s.accessTokenTTL)
+	token, err := s.generateToken(user.ID, refresh.FamilyID, accessExpiresAt)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.LoginResponse{Token: token, User: user}, nil
+	return &models.LoginResponse{
+		Token:            token,
+		TokenExpiresAt:   accessExpiresAt,
+		RefreshToken:     plainRefresh,
+		RefreshExpiresAt: refresh.ExpiresAt,
+		User:             user,
+	}, nil
+}
+
+func (s *authService) newRefreshToken(userID uint, familyID string) (string, *models.RefreshToken, error) {
+	plain, err := randomToken(32)
+	if err != nil {
+		s.logger.Error("failed to generate refresh token", slog.String("error", err.Error()))
+		return "", nil, err
+	}
+
+	return plain, &models.RefreshToken{
+		UserID:    userID,
+		TokenHash: hashRefreshToken(plain),
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
+	}, nil
 }
 
-func (s *authService) generateToken(userID uint) (string, error) {
+func (s *authService) revokeReusedFamily(token *models.RefreshToken) {
+	s.logger.Warn("refresh token reuse detected, revoking session family",
+		slog.Uint64("user_id", uint64(token.UserID)),
+		slog.String("family_id", token.FamilyID),
+	)
+	if err := s.refreshTokens.RevokeFamily(token.FamilyID); err != nil {
+		s.logger.Error("failed to revoke session family",
+			slog.String("family_id", token.FamilyID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func (s *authService) generateToken(userID uint, sessionID string, expiresAt time.Time) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": userID,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+		"sid":     sessionID,
+		"iat":     time.Now().Unix(),
+		"exp":     expiresAt.Unix(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signed, err := token.SignedString([]byte(s.jwtSecret))
@@ -108,6 +298,42 @@ func (s *authService) generateToken(userID uint) (string, error) {
 	return signed, nil
 }
 
+// ValidateToken проверяет подпись и срок действия токена и возвращает идентификатор пользователя
+func (s *authService) ValidateToken(tokenString string) (uint, error) {
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		return []byte(s.jwtSecret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return 0, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, ErrInvalidToken
+	}
+
+	// числовые claims после разбора JSON приходят как float64
+	rawUserID, ok := claims["user_id"].(float64)
+	if !ok || rawUserID <= 0 {
+		return 0, ErrInvalidToken
+	}
+
+	// Токен доступа действует, пока не завершена сессия, в которой он выдан, и не удален пользователь
+	sessionID, ok := claims["sid"].(string)
+	if !ok || sessionID == "" {
+		return 0, ErrInvalidToken
+	}
+	active, err := s.refreshTokens.IsFamilyActive(sessionID, uint(rawUserID))
+	if err != nil {
+		return 0, err
+	}
+	if !active {
+		return 0, ErrInvalidToken
+	}
+
+	return uint(rawUserID), nil
+}
+
 func (s *authService) validateRegister(req models.RegisterRequest) error {
 	if req.Email == "" {
 		return errors.New("email не может быть пустым")
@@ -118,5 +344,23 @@ func (s *authService) validateRegister(req models.RegisterRequest) error {
 	if len(req.Password) < 6 {
 		return errors.New("пароль должен быть не менее 6 символов")
 	}
+	if req.BaseCurrency != "" {
+		if _, err := normalizeCurrency(req.BaseCurrency); err != nil {
+			return err
+		}
+	}
 	return nil
 }
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/notifications"
+	"cashcontrol/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// notificationTimeout ограничивает время доставки одного уведомления по всем каналам
+const notificationTimeout = 30 * time.Second
+
+type BudgetAlertService interface {
+	GetSettings(userID uint) (*models.AlertSettings, error)
+	UpdateSettings(userID uint, req models.UpdateAlertSettingsRequest) (*models.AlertSettings, error)
+	ListAlerts(userID uint) ([]models.BudgetAlert, error)
+	CheckExpense(expense *models.Expense)
+}
+
+type budgetAlertService struct {
+	alerts     repository.BudgetAlertRepository
+	categories repository.CategoryRepository
+	budgets    BudgetService
+	notifier   notifications.Notifier
+	logger     *slog.Logger
+}
+
+func NewBudgetAlertService(
+	alerts repository.BudgetAlertRepository,
+	categories repository.CategoryRepository,
+	budgets BudgetService,
+	notifier notifications.Notifier,
+	logger *slog.Logger,
+) BudgetAlertService {
+	return &budgetAlertService{
+		alerts:     alerts,
+		categories: categories,
+		budgets:    budgets,
+		notifier:   notifier,
+		logger:     logger,
+	}
+}
+
+// GetSettings возвращает настройки уведомлений пользователя или настройки по умолчанию
+func (s *budgetAlertService) GetSettings(userID uint) (*models.AlertSettings, error) {
+	settings, err := s.alerts.GetSettings(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return defaultAlertSettings(userID), nil
+		}
+		s.logger.Error("failed to get alert settings",
+			slog.String("op", "get_alert_settings"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (s *budgetAlertService) UpdateSettings(userID uint, req models.UpdateAlertSettingsRequest) (*models.AlertSettings, error) {
+	thresholds, err := normalizeThresholds(req.Thresholds)
+	if err != nil {
+		s.logger.Warn("alert settings validation failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	if req.WebhookURL != "" {
+		if err := notifications.ValidateWebhookURL(req.WebhookURL); err != nil {
+			s.logger.Warn("alert settings validation failed",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("reason", err.Error()),
+			)
+			return nil, err
+		}
+	}
+
+	settings, err := s.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.Thresholds = thresholds
+	settings.WebhookURL = req.WebhookURL
+	settings.Email = req.Email
+	if req.IsEnabled != nil {
+		settings.IsEnabled = *req.IsEnabled
+	}
+
+	if err := s.alerts.SaveSettings(settings); err != nil {
+		s.logger.Error("failed to save alert settings",
+			slog.String("op", "update_alert_settings"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("alert settings updated",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Any("thresholds", settings.Thresholds),
+		slog.Bool("is_enabled", settings.IsEnabled),
+	)
+
+	return settings, nil
+}
+
+func (s *budgetAlertService) ListAlerts(userID uint) ([]models.BudgetAlert, error) {
+	alerts, err := s.alerts.ListByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to list budget alerts",
+			slog.String("op", "list_budget_alerts"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// CheckExpense проверяет общий бюджет и бюджеты категории расхода и ее родителей за месяц расхода
+// и отправляет уведомления о впервые достигнутых порогах. Бюджеты других категорий не пересчитываются.
+// Ошибки только логируются, чтобы не мешать сохранению расхода.
+func (s *budgetAlertService) CheckExpense(expense *models.Expense) {
+	settings, err := s.GetSettings(expense.UserID)
+	if err != nil || !settings.IsEnabled {
+		return
+	}
+
+	// Расход подкатегории входит и в бюджеты всех родительских категорий
+	categoryIDs := []uint{expense.CategoryID}
+	if categories, err := s.categories.GetByUserID(expense.UserID); err == nil {
+		categoryIDs = append(categoryIDs, newCategoryIndex(categories).ancestors(expense.CategoryID)...)
+	} else {
+		s.logger.Error("failed to load categories for alerts",
+			slog.Uint64("user_id", uint64(expense.UserID)),
+			slog.Uint64("expense_id", uint64(expense.ID)),
+			slog.String("error", err.Error()),
+		)
+	}
+
+	date := expense.Date.UTC()
+	statuses, err := s.budgets.GetBudgetStatusesForCategories(expense.UserID, int(date.Month()), date.Year(), categoryIDs)
+	if err != nil {
+		s.logger.Error("failed to get budget status for alerts",
+			slog.Uint64("user_id", uint64(expense.UserID)),
+			slog.Uint64("expense_id", uint64(expense.ID)),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	for i := range statuses {
+		s.checkBudgetStatus(settings, &statuses[i])
+	}
+}
+
+// checkBudgetStatus фиксирует все достигнутые пороги и уведомляет о наибольшем из впервые достигнутых
+func (s *budgetAlertService) checkBudgetStatus(settings *models.AlertSettings, status *models.BudgetStatus) {
+	var triggered *models.BudgetAlert
+	for _, threshold := range settings.Thresholds {
+		if status.Percentage < float64(threshold) {
+			break
+		}
+
+		alert := &models.BudgetAlert{
+			UserID:      status.Budget.UserID,
+			BudgetID:    status.Budget.ID,
+			Threshold:   threshold,
+			Percentage:  status.Percentage,
+			Spent:       status.Spent,
+			LimitAmount: status.EffectiveLimit,
+			Month:       status.Budget.Month,
+			Year:        status.Budget.Year,
+			TriggeredAt: time.Now(),
+		}
+		created, err := s.alerts.CreateIfAbsent(alert)
+		if err != nil {
+			return
+		}
+		if created {
+			triggered = alert
+		}
+	}
+
+	if triggered == nil {
+		return
+	}
+
+	s.logger.Info("budget alert triggered",
+		slog.Uint64("budget_id", uint64(triggered.BudgetID)),
+		slog.Uint64("user_id", uint64(triggered.UserID)),
+		slog.Int("threshold", triggered.Threshold),
+		slog.Float64("percentage", triggered.Percentage),
+	)
+
+	n := notifications.Notification{
+		Subject:    fmt.Sprintf("Бюджет использован на %d%%", triggered.Threshold),
+		Text:       s.alertText(status.Budget, triggered),
+		Email:      settings.Email,
+		WebhookURL: settings.WebhookURL,
+		Payload:    triggered,
+	}
+	go s.deliver(n, triggered)
+}
+
+func (s *budgetAlertService) deliver(n notifications.Notification, alert *models.BudgetAlert) {
+	if s.notifier == nil || (n.Email == "" && n.WebhookURL == "") {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notificationTimeout)
+	defer cancel()
+
+	if err := s.notifier.Notify(ctx, n); err != nil {
+		s.logger.Warn("failed to deliver budget alert",
+			slog.Uint64("budget_id", uint64(alert.BudgetID)),
+			slog.Int("threshold", alert.Threshold),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func (s *budgetAlertService) alertText(budget *models.Budget, alert *models.BudgetAlert) string {
+	name := "общий бюджет"
+	if budget.CategoryID != nil {
+		name = fmt.Sprintf("категория #%d", *budget.CategoryID)
+		if category, err := s.categories.GetByID(*budget.CategoryID); err == nil {
+			name = fmt.Sprintf("категория «%s»", category.Name)
+		}
+	}
+	return fmt.Sprintf("Расходы за %02d.%d (%s) достигли %.2f%% лимита: потрачено %s из %s %s.",
+		alert.Month, alert.Year, name, alert.Percentage, alert.Spent, alert.LimitAmount, budget.Currency)
+}
+
+// checkBudgetAlerts проверяет пороги бюджета после сохранения расхода, если сервис уведомлений подключен
+func checkBudgetAlerts(alerts BudgetAlertService, expense *models.Expense) {
+	if alerts == nil {
+		return
+	}
+	alerts.CheckExpense(expense)
+}
+
+func defaultAlertSettings(userID uint) *models.AlertSettings {
+	thresholds := make([]int, len(models.DefaultAlertThresholds))
+	copy(thresholds, models.DefaultAlertThresholds)
+	return &models.AlertSettings{
+		UserID:     userID,
+		Thresholds: thresholds,
+		IsEnabled:  true,
+	}
+}
+
+// normalizeThresholds сортирует пороги по возрастанию и убирает повторы
+func normalizeThresholds(thresholds []int) ([]int, error) {
+	if len(thresholds) == 0 {
+		return nil, errors.New("необходимо указать хотя бы один порог")
+	}
+
+	sorted := make([]int, len(thresholds))
+	copy(sorted, thresholds)
+	sort.Ints(sorted)
+
+	result := make([]int, 0, len(sorted))
+	for _, t := range sorted {
+		if t <= 0 || t > 1000 {
+			return nil, errors.New("порог должен быть в диапазоне от 1 до 1000 процентов")
+		}
+		if len(result) > 0 && result[len(result)-1] == t {
+			continue
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
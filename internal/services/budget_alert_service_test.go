@@ -0,0 +1,322 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/notifications"
+	"cashcontrol/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fakeBudgetAlertRepo хранит уведомления в памяти и, как уникальный индекс в БД,
+// не дает создать второе уведомление для того же бюджета и порога
+type fakeBudgetAlertRepo struct {
+	repository.BudgetAlertRepository
+	settings *models.AlertSettings
+	alerts   []models.BudgetAlert
+}
+
+func (r *fakeBudgetAlertRepo) GetSettings(userID uint) (*models.AlertSettings, error) {
+	copied := *r.settings
+	return &copied, nil
+}
+
+func (r *fakeBudgetAlertRepo) SaveSettings(settings *models.AlertSettings) error {
+	r.settings = settings
+	return nil
+}
+
+func (r *fakeBudgetAlertRepo) CreateIfAbsent(alert *models.BudgetAlert) (bool, error) {
+	for _, a := range r.alerts {
+		if a.BudgetID == alert.BudgetID && a.Threshold == alert.Threshold {
+			return false, nil
+		}
+	}
+	r.alerts = append(r.alerts, *alert)
+	return true, nil
+}
+
+// fakeCategoryRepo отдает категории по идентификатору и все категории пользователя
+type fakeCategoryRepo struct {
+	repository.CategoryRepository
+	categories []models.Category
+}
+
+func (r *fakeCategoryRepo) GetByUserID(userID uint) ([]models.Category, error) {
+	var result []models.Category
+	for _, category := range r.categories {
+		if category.UserID == userID {
+			result = append(result, category)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeCategoryRepo) GetByID(id uint) (*models.Category, error) {
+	for i := range r.categories {
+		if r.categories[i].ID == id {
+			return &r.categories[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// fakeNotifier передает отправленные уведомления в канал: доставка идет в отдельной горутине
+type fakeNotifier struct {
+	sent chan notifications.Notification
+}
+
+func (n *fakeNotifier) Notify(_ context.Context, notification notifications.Notification) error {
+	n.sent <- notification
+	return nil
+}
+
+type alertTestEnv struct {
+	service    *budgetAlertService
+	alerts     *fakeBudgetAlertRepo
+	categories *fakeCategoryRepo
+	expenses   *fakeExpenseRepo
+	notifier   *fakeNotifier
+}
+
+func newAlertTestEnv(budgets []models.Budget, spent map[time.Time]float64, settings *models.AlertSettings) *alertTestEnv {
+	budgetService := newTestBudgetService(budgets, spent)
+	env := &alertTestEnv{
+		alerts:   &fakeBudgetAlertRepo{settings: settings},
+		expenses: budgetService.expenses.(*fakeExpenseRepo),
+		notifier: &fakeNotifier{sent: make(chan notifications.Notification, 10)},
+	}
+	env.categories = &fakeCategoryRepo{}
+	env.service = &budgetAlertService{
+		alerts:     env.alerts,
+		categories: env.categories,
+		budgets:    budgetService,
+		notifier:   env.notifier,
+		logger:     budgetService.logger,
+	}
+	return env
+}
+
+// expectNotification ждет уведомление о пороге threshold
+func (env *alertTestEnv) expectNotification(t *testing.T, threshold int) {
+	t.Helper()
+	select {
+	case n := <-env.notifier.sent:
+		want := fmt.Sprintf("Бюджет использован на %d%%", threshold)
+		if n.Subject != want {
+			t.Errorf("тема уведомления = %q, want %q", n.Subject, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("нет уведомления о пороге %d%%", threshold)
+	}
+}
+
+// expectNoNotification проверяет, что уведомлений больше не отправлялось
+func (env *alertTestEnv) expectNoNotification(t *testing.T) {
+	t.Helper()
+	select {
+	case n := <-env.notifier.sent:
+		t.Errorf("лишнее уведомление: %q", n.Subject)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func testAlertSettings() *models.AlertSettings {
+	return &models.AlertSettings{
+		UserID:     1,
+		Thresholds: []int{80, 100},
+		Email:      "user@example.com",
+		IsEnabled:  true,
+	}
+}
+
+func testExpense(categoryID uint) *models.Expense {
+	return &models.Expense{UserID: 1, CategoryID: categoryID, Date: time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)}
+}
+
+func TestCheckExpenseNotifiesOncePerThreshold(t *testing.T) {
+	spent := map[time.Time]float64{monthStart(2024, 3): 850}
+	env := newAlertTestEnv(
+		[]models.Budget{testBudget(2024, 3, 1000, models.RolloverNone)},
+		spent,
+		testAlertSettings(),
+	)
+
+	env.service.CheckExpense(testExpense(7))
+	env.expectNotification(t, 80)
+
+	// Повторная проверка на том же уровне расходов не шлет уведомление заново
+	env.service.CheckExpense(testExpense(7))
+	env.expectNoNotification(t)
+
+	spent[monthStart(2024, 3)] = 1100
+	env.service.CheckExpense(testExpense(7))
+	env.expectNotification(t, 100)
+
+	env.service.CheckExpense(testExpense(7))
+	env.expectNoNotification(t)
+
+	if len(env.alerts.alerts) != 2 {
+		t.Errorf("сохранено уведомлений: %d, want 2", len(env.alerts.alerts))
+	}
+}
+
+func TestCheckExpenseReportsHighestNewThreshold(t *testing.T) {
+	env := newAlertTestEnv(
+		[]models.Budget{testBudget(2024, 3, 1000, models.RolloverNone)},
+		map[time.Time]float64{monthStart(2024, 3): 1200},
+		testAlertSettings(),
+	)
+
+	env.service.CheckExpense(testExpense(7))
+
+	// Оба порога фиксируются, но уведомление одно — о наибольшем
+	env.expectNotification(t, 100)
+	env.expectNoNotification(t)
+	if len(env.alerts.alerts) != 2 {
+		t.Errorf("сохранено уведомлений: %d, want 2", len(env.alerts.alerts))
+	}
+}
+
+func TestCheckExpenseUsesRolloverLimit(t *testing.T) {
+	// Остаток февраля увеличивает лимит марта до 1500, 850 — меньше 80% от него
+	env := newAlertTestEnv(
+		[]models.Budget{
+			testBudget(2024, 2, 1000, models.RolloverSurplus),
+			testBudget(2024, 3, 1000, models.RolloverNone),
+		},
+		map[time.Time]float64{
+			monthStart(2024, 2): 500,
+			monthStart(2024, 3): 850,
+		},
+		testAlertSettings(),
+	)
+
+	env.service.CheckExpense(testExpense(7))
+
+	env.expectNoNotification(t)
+	if len(env.alerts.alerts) != 0 {
+		t.Errorf("сохранено уведомлений: %d, want 0", len(env.alerts.alerts))
+	}
+}
+
+func TestCheckExpenseChecksOnlyAffectedBudgets(t *testing.T) {
+	food, transport := uint(7), uint(9)
+	foodBudget := testBudget(2024, 3, 1000, models.RolloverNone)
+	foodBudget.CategoryID = &food
+	transportBudget := testBudget(2024, 3, 1000, models.RolloverNone)
+	transportBudget.CategoryID = &transport
+	env := newAlertTestEnv(
+		[]models.Budget{
+			testBudget(2024, 3, 5000, models.RolloverNone),
+			foodBudget,
+			transportBudget,
+		},
+		map[time.Time]float64{monthStart(2024, 3): 900},
+		testAlertSettings(),
+	)
+
+	env.service.CheckExpense(testExpense(food))
+
+	for _, categoryID := range env.expenses.queriedCategories {
+		if categoryID != nil && *categoryID != food {
+			t.Errorf("рассчитан бюджет категории %d, не связанной с расходом", *categoryID)
+		}
+	}
+	if len(env.expenses.queriedCategories) != 2 {
+		t.Errorf("рассчитано бюджетов: %d, want 2", len(env.expenses.queriedCategories))
+	}
+	env.expectNotification(t, 80)
+	env.expectNoNotification(t)
+	if len(env.alerts.alerts) != 1 || env.alerts.alerts[0].BudgetID != 2 {
+		t.Errorf("уведомления = %+v, want одно для бюджета категории", env.alerts.alerts)
+	}
+}
+
+func TestCheckExpenseChecksParentBudgets(t *testing.T) {
+	food, cafes, transport := uint(7), uint(8), uint(9)
+	foodBudget := testBudget(2024, 3, 1000, models.RolloverNone)
+	foodBudget.CategoryID = &food
+	transportBudget := testBudget(2024, 3, 1000, models.RolloverNone)
+	transportBudget.CategoryID = &transport
+	env := newAlertTestEnv(
+		[]models.Budget{foodBudget, transportBudget},
+		map[time.Time]float64{monthStart(2024, 3): 900},
+		testAlertSettings(),
+	)
+	for _, c := range []struct {
+		id     uint
+		parent *uint
+	}{{food, nil}, {cafes, &food}, {transport, nil}} {
+		category := models.Category{UserID: 1, ParentID: c.parent, Type: models.CategoryTypeExpense}
+		category.ID = c.id
+		env.categories.categories = append(env.categories.categories, category)
+	}
+
+	// Расход в подкатегории «Кафе» проверяет бюджет родительской категории «Еда»
+	env.service.CheckExpense(testExpense(cafes))
+
+	if len(env.expenses.queriedCategories) != 1 || *env.expenses.queriedCategories[0] != food {
+		t.Errorf("рассчитаны бюджеты категорий %v, want только %d", env.expenses.queriedCategories, food)
+	}
+	env.expectNotification(t, 80)
+	env.expectNoNotification(t)
+}
+
+func TestCheckExpenseDisabled(t *testing.T) {
+	settings := testAlertSettings()
+	settings.IsEnabled = false
+	env := newAlertTestEnv(
+		[]models.Budget{testBudget(2024, 3, 1000, models.RolloverNone)},
+		map[time.Time]float64{monthStart(2024, 3): 1200},
+		settings,
+	)
+
+	env.service.CheckExpense(testExpense(7))
+
+	env.expectNoNotification(t)
+	if len(env.alerts.alerts) != 0 || len(env.expenses.queriedCategories) != 0 {
+		t.Errorf("при выключенных уведомлениях бюджеты не проверяются")
+	}
+}
+
+func TestUpdateSettingsValidatesWebhookURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr error
+	}{
+		{"https://hooks.example.com/budget", nil},
+		{"", nil},
+		{"http://hooks.example.com/budget", notifications.ErrInvalidWebhookURL},
+		{"https://127.0.0.1:8080/hook", notifications.ErrInvalidWebhookURL},
+		{"https://169.254.169.254/latest/meta-data", notifications.ErrInvalidWebhookURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			env := newAlertTestEnv(nil, nil, testAlertSettings())
+
+			settings, err := env.service.UpdateSettings(1, models.UpdateAlertSettingsRequest{
+				Thresholds: []int{100, 80, 80},
+				WebhookURL: tt.url,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateSettings: %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if env.alerts.settings.WebhookURL != "" {
+					t.Errorf("отклоненный адрес сохранен: %q", env.alerts.settings.WebhookURL)
+				}
+				return
+			}
+			if settings.WebhookURL != tt.url || len(settings.Thresholds) != 2 {
+				t.Errorf("settings = %+v", settings)
+			}
+		})
+	}
+}
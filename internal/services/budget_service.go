@@ -2,9 +2,11 @@ package services
 
 import (
 	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
 	"cashcontrol/internal/repository"
 	"errors"
 	"log/slog"
+	"slices"
 	"time"
 
 	"gorm.io/gorm"
@@ -14,37 +16,57 @@ var ErrBudgetNotFound = errors.New("бюджет не найден")
 
 const (
 	NearLimitThreshold = 0.8 // 80% использования бюджета
+
+	// maxRolloverMonths ограничивает глубину цепочки месяцев при расчете переноса
+	maxRolloverMonths = 120
 )
 
 type BudgetService interface {
 	CreateBudget(userID uint, req models.CreateBudgetRequest) (*models.Budget, error)
-	GetBudgetList(userID uint) ([]models.Budget, error)
-	GetBudgetByID(id uint) (*models.Budget, error)
-	GetBudgetByUserIDAndMonth(userID uint, month, year int) (*models.Budget, error)
-	GetBudgetStatus(userID uint, month, year int) (*models.BudgetStatus, error)
-	UpdateBudget(id uint, req models.UpdateBudgetRequest) (*models.Budget, error)
-	DeleteBudget(id uint) error
+	GetBudgetList(userID uint, page models.PageRequest) (*models.Page[models.Budget], error)
+	GetBudgetByID(userID, id uint) (*models.Budget, error)
+	GetBudgetByUserIDAndMonth(userID uint, month, year int, categoryID *uint) (*models.Budget, error)
+	GetBudgetStatus(userID uint, month, year int) (*models.MonthlyBudgetStatus, error)
+	GetBudgetStatusesForCategories(userID uint, month, year int, categoryIDs []uint) ([]models.BudgetStatus, error)
+	UpdateBudget(userID, id uint, req models.UpdateBudgetRequest) (*models.Budget, error)
+	DeleteBudget(userID, id uint) error
 }
 
 type budgetService struct {
-	budgets  repository.BudgetRepository
-	expenses repository.ExpenseRepository
-	logger   *slog.Logger
+	budgets      repository.BudgetRepository
+	expenses     repository.ExpenseRepository
+	incomes      repository.IncomeRepository
+	categories   repository.CategoryRepository
+	currencies   CurrencyService
+	activityLogs ActivityLogService
+	logger       *slog.Logger
 }
 
-func NewBudgetService(budgets repository.BudgetRepository, expenses repository.ExpenseRepository, logger *slog.Logger) BudgetService {
+func NewBudgetService(
+	budgets repository.BudgetRepository,
+	expenses repository.ExpenseRepository,
+	incomes repository.IncomeRepository,
+	categories repository.CategoryRepository,
+	currencies CurrencyService,
+	activityLogs ActivityLogService,
+	logger *slog.Logger,
+) BudgetService {
 	return &budgetService{
-		budgets:  budgets,
-		expenses: expenses,
-		logger:   logger,
+		budgets:      budgets,
+		expenses:     expenses,
+		incomes:      incomes,
+		categories:   categories,
+		currencies:   currencies,
+		activityLogs: activityLogs,
+		logger:       logger,
 	}
 }
 
 func (s *budgetService) CreateBudget(userID uint, req models.CreateBudgetRequest) (*models.Budget, error) {
-	if err := s.validateBudgetCreate(req); err != nil {
+	if err := s.validateBudgetCreate(userID, req); err != nil {
 		s.logger.Warn("budget create validation failed",
 			slog.Uint64("user_id", uint64(userID)),
-			slog.Float64("amount", req.Amount),
+			slog.String("amount", req.Amount.String()),
 			slog.Int("month", req.Month),
 			slog.Int("year", req.Year),
 			slog.String("reason", err.Error()),
@@ -52,33 +74,31 @@ func (s *budgetService) CreateBudget(userID uint, req models.CreateBudgetRequest
 		return nil, err
 	}
 
-	// Проверяем, не существует ли уже бюджет на этот месяц
-	existing, err := s.budgets.GetByUserIDAndMonth(userID, req.Month, req.Year)
+	// Проверяем, не существует ли уже бюджет на этот месяц (общий или для той же категории)
+	if err := s.ensureBudgetSlotFree(userID, req.Month, req.Year, req.CategoryID, 0); err != nil {
+		return nil, err
+	}
+
+	currency, err := s.currencies.ResolveCurrency(userID, req.Currency)
 	if err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Error("failed to check existing budget",
-				slog.Uint64("user_id", uint64(userID)),
-				slog.Int("month", req.Month),
-				slog.Int("year", req.Year),
-				slog.String("error", err.Error()),
-			)
-			return nil, err
-		}
-		// ErrRecordNotFound - это нормально, бюджета нет
-	} else if existing != nil {
-		s.logger.Warn("budget already exists for this month",
-			slog.Uint64("user_id", uint64(userID)),
-			slog.Int("month", req.Month),
-			slog.Int("year", req.Year),
-		)
-		return nil, errors.New("бюджет на этот месяц уже существует")
+		return nil, err
+	}
+	amount, err := roundToCurrency(req.Amount, currency)
+	if err != nil {
+		return nil, err
 	}
 
 	budget := &models.Budget{
-		UserID: userID,
-		Amount: req.Amount,
-		Month:  req.Month,
-		Year:   req.Year,
+		UserID:       userID,
+		CategoryID:   req.CategoryID,
+		Amount:       amount,
+		Currency:     currency,
+		Month:        req.Month,
+		Year:         req.Year,
+		RolloverMode: req.RolloverMode,
+	}
+	if budget.RolloverMode == "" {
+		budget.RolloverMode = models.RolloverNone
 	}
 
 	if err := s.budgets.Create(budget); err != nil {
@@ -94,17 +114,35 @@ func (s *budgetService) CreateBudget(userID uint, req models.CreateBudgetRequest
 	s.logger.Info("budget created",
 		slog.Uint64("budget_id", uint64(budget.ID)),
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Float64("amount", budget.Amount),
+		slog.String("amount", budget.Amount.String()),
 		slog.Int("month", budget.Month),
 		slog.Int("year", budget.Year),
 	)
 
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeBudgetCreated,
+		EntityType:   models.EntityTypeBudget,
+		EntityID:     budget.ID,
+		Description:  "создан бюджет",
+		Metadata:     activityMetadata(nil, budget),
+	})
+
 	return budget, nil
 }
 
-func (s *budgetService) GetBudgetList(userID uint) ([]models.Budget, error) {
-	budgets, err := s.budgets.GetByUserID(userID)
+// GetBudgetList возвращает страницу бюджетов; по умолчанию бюджеты последних месяцев идут первыми
+func (s *budgetService) GetBudgetList(userID uint, page models.PageRequest) (*models.Page[models.Budget], error) {
+	page, err := budgetSorting.normalize(page)
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := s.budgets.ListPage(userID, page)
 	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, ErrInvalidCursor
+		}
 		s.logger.Error("failed to list budgets",
 			slog.String("op", "list_budgets"),
 			slog.Uint64("user_id", uint64(userID)),
@@ -115,40 +153,30 @@ func (s *budgetService) GetBudgetList(userID uint) ([]models.Budget, error) {
 
 	s.logger.Info("budgets listed",
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Int("count", len(budgets)),
+		slog.Int("count", len(budgets.Items)),
+		slog.Int64("total", budgets.Total),
 	)
 
 	return budgets, nil
 }
 
-func (s *budgetService) GetBudgetByID(id uint) (*models.Budget, error) {
-	budget, err := s.budgets.GetByID(id)
+func (s *budgetService) GetBudgetByID(userID, id uint) (*models.Budget, error) {
+	budget, err := s.getOwnedBudget(userID, id, "get_budget_by_id")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Warn("budget not found",
-				slog.Uint64("budget_id", uint64(id)),
-			)
-			return nil, ErrBudgetNotFound
-		}
-		s.logger.Error("failed to get budget",
-			slog.String("op", "get_budget_by_id"),
-			slog.Uint64("budget_id", uint64(id)),
-			slog.String("error", err.Error()),
-		)
 		return nil, err
 	}
 
 	s.logger.Info("budget retrieved",
 		slog.Uint64("budget_id", uint64(budget.ID)),
 		slog.Uint64("user_id", uint64(budget.UserID)),
-		slog.Float64("amount", budget.Amount),
+		slog.String("amount", budget.Amount.String()),
 	)
 
 	return budget, nil
 }
 
-func (s *budgetService) GetBudgetByUserIDAndMonth(userID uint, month, year int) (*models.Budget, error) {
-	budget, err := s.budgets.GetByUserIDAndMonth(userID, month, year)
+func (s *budgetService) GetBudgetByUserIDAndMonth(userID uint, month, year int, categoryID *uint) (*models.Budget, error) {
+	budget, err := s.budgets.GetByUserIDAndMonth(userID, month, year, categoryID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			s.logger.Warn("budget not found",
@@ -171,20 +199,75 @@ func (s *budgetService) GetBudgetByUserIDAndMonth(userID uint, month, year int)
 	return budget, nil
 }
 
-func (s *budgetService) GetBudgetStatus(userID uint, month, year int) (*models.BudgetStatus, error) {
-	budget, err := s.budgets.GetByUserIDAndMonth(userID, month, year)
+// GetBudgetStatus возвращает статус общего бюджета и бюджетов по категориям за месяц
+func (s *budgetService) GetBudgetStatus(userID uint, month, year int) (*models.MonthlyBudgetStatus, error) {
+	budgets, err := s.budgets.ListByUserIDAndMonth(userID, month, year)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrBudgetNotFound
+		s.logger.Error("failed to list budgets for month",
+			slog.String("op", "get_budget_status"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Int("month", month),
+			slog.Int("year", year),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	if len(budgets) == 0 {
+		return nil, ErrBudgetNotFound
+	}
+
+	result := &models.MonthlyBudgetStatus{
+		Month:      month,
+		Year:       year,
+		Categories: make([]models.BudgetStatus, 0, len(budgets)),
+	}
+
+	for i := range budgets {
+		budget := &budgets[i]
+
+		status, err := s.calculateBudgetStatus(budget)
+		if err != nil {
+			s.logger.Error("failed to calculate budget status",
+				slog.String("op", "get_budget_status"),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.Uint64("budget_id", uint64(budget.ID)),
+				slog.Int("month", month),
+				slog.Int("year", year),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+
+		if budget.CategoryID == nil {
+			result.Overall = status
+		} else {
+			result.Categories = append(result.Categories, *status)
+		}
+	}
+
+	if err := s.fillAvailableFunds(result, userID); err != nil {
+		if !errors.Is(err, ErrMissingExchangeRate) {
+			s.logger.Error("failed to calculate available funds",
+				slog.String("op", "get_budget_status"),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.Int("month", month),
+				slog.Int("year", year),
+				slog.String("error", err.Error()),
+			)
 		}
 		return nil, err
 	}
 
-	// Расчет потраченной суммы за период
-	spent, err := s.calculateSpentAmount(userID, month, year)
+	return result, nil
+}
+
+// GetBudgetStatusesForCategories возвращает статусы общего бюджета месяца и бюджетов указанных категорий.
+// Бюджеты остальных категорий не рассчитываются; если бюджетов нет, возвращается пустой список.
+func (s *budgetService) GetBudgetStatusesForCategories(userID uint, month, year int, categoryIDs []uint) ([]models.BudgetStatus, error) {
+	budgets, err := s.budgets.ListByUserIDAndMonth(userID, month, year)
 	if err != nil {
-		s.logger.Error("failed to calculate spent amount",
-			slog.String("op", "get_budget_status"),
+		s.logger.Error("failed to list budgets for month",
+			slog.String("op", "get_budget_statuses_for_categories"),
 			slog.Uint64("user_id", uint64(userID)),
 			slog.Int("month", month),
 			slog.Int("year", year),
@@ -193,72 +276,171 @@ func (s *budgetService) GetBudgetStatus(userID uint, month, year int) (*models.B
 		return nil, err
 	}
 
-	// Расчет оставшегося лимита
-	remaining := budget.Amount - spent
-	if remaining < 0 {
-		remaining = 0
+	var statuses []models.BudgetStatus
+	for i := range budgets {
+		budget := &budgets[i]
+		if budget.CategoryID != nil && !slices.Contains(categoryIDs, *budget.CategoryID) {
+			continue
+		}
+
+		status, err := s.calculateBudgetStatus(budget)
+		if err != nil {
+			s.logger.Error("failed to calculate budget status",
+				slog.String("op", "get_budget_statuses_for_categories"),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.Uint64("budget_id", uint64(budget.ID)),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+		statuses = append(statuses, *status)
+	}
+
+	return statuses, nil
+}
+
+// fillAvailableFunds добавляет в статус доходы и все расходы месяца в базовой валюте пользователя
+// и их разницу — сумму, которую еще можно потратить без ухода в минус
+func (s *budgetService) fillAvailableFunds(status *models.MonthlyBudgetStatus, userID uint) error {
+	currency, err := s.currencies.BaseCurrency(userID)
+	if err != nil {
+		return err
+	}
+
+	from := time.Date(status.Year, time.Month(status.Month), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	filter := models.ExpenseFilter{UserID: userID, StartDate: &from, EndDate: &to}
+
+	flow, err := cashFlowByPeriod(s.expenses, s.incomes, s.currencies, filter, models.PeriodMonth, currency)
+	if err != nil {
+		return err
+	}
+
+	status.Currency = currency
+	if len(flow) > 0 {
+		status.Income = flow[0].Income
+		status.Expenses = flow[0].Expenses
+		status.AvailableFunds = flow[0].Net
+	}
+	return nil
+}
+
+// calculateBudgetStatus считает статус бюджета с учетом переноса по цепочке предыдущих месяцев.
+// Цепочка состоит из идущих подряд месяцев с бюджетом той же категории и валюты; режим переноса
+// каждого бюджета определяет, какая часть его итога переходит в следующий месяц.
+// Расходы пересчитываются в валюту бюджета по курсу на день расхода.
+func (s *budgetService) calculateBudgetStatus(budget *models.Budget) (*models.BudgetStatus, error) {
+	previous, err := s.budgets.ListPrevious(budget.UserID, budget.CategoryID, budget.Month, budget.Year, maxRolloverMonths)
+	if err != nil {
+		return nil, err
+	}
+
+	// Оставляем только непрерывную цепочку месяцев и разворачиваем ее от старых к новым
+	chain := []models.Budget{*budget}
+	expected := budgetMonthStart(budget).AddDate(0, -1, 0)
+	for i := range previous {
+		if !budgetMonthStart(&previous[i]).Equal(expected) || previous[i].Currency != budget.Currency {
+			break
+		}
+		chain = append(chain, previous[i])
+		expected = expected.AddDate(0, -1, 0)
+	}
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+
+	spentByMonth, err := s.calculateSpentByMonth(budget.UserID, budget.CategoryID, budget.Currency,
+		budgetMonthStart(&chain[0]), budgetMonthStart(budget).AddDate(0, 1, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	var carried money.Amount
+	for i := 0; i < len(chain)-1; i++ {
+		b := &chain[i]
+		leftover := b.Amount + carried - spentByMonth[budgetMonthStart(b)]
+		carried = rolloverAmount(b.RolloverMode, leftover)
 	}
 
-	// Определение процента использования бюджета
+	status := s.buildBudgetStatus(budget, spentByMonth[budgetMonthStart(budget)], carried)
+	return &status, nil
+}
+
+// buildBudgetStatus рассчитывает остаток и флаги превышения бюджета
+func (s *budgetService) buildBudgetStatus(budget *models.Budget, spent, carried money.Amount) models.BudgetStatus {
+	effectiveLimit := budget.Amount + carried
+
+	// Расчет оставшегося лимита, при перерасходе остаток отрицательный
+	remaining := effectiveLimit - spent
+
+	// Определение процента использования бюджета, считается в целых числах без ошибок округления
 	var percentage float64
-	if budget.Amount == 0 {
-		percentage = 0
-	} else {
-		percentage = (spent / budget.Amount) * 100
+	if effectiveLimit > 0 {
+		percentage = money.Percent(spent, effectiveLimit)
+	} else if spent > 0 {
+		percentage = 100
 	}
 
 	// Проверка превышения и приближения к лимиту
-	isExceeded := spent > budget.Amount
+	isExceeded := spent > effectiveLimit
 	isNearLimit := percentage >= (NearLimitThreshold*100) && !isExceeded
 
-	status := &models.BudgetStatus{
-		Budget:      budget,
-		Spent:       spent,
-		Remaining:   remaining,
-		Percentage:  percentage,
-		IsExceeded:  isExceeded,
-		IsNearLimit: isNearLimit,
-	}
-
 	// Логирование уведомлений
 	if isExceeded {
 		s.logger.Warn("budget exceeded",
 			slog.Uint64("budget_id", uint64(budget.ID)),
-			slog.Uint64("user_id", uint64(userID)),
-			slog.Float64("budget_amount", budget.Amount),
-			slog.Float64("spent", spent),
+			slog.Uint64("user_id", uint64(budget.UserID)),
+			slog.String("effective_limit", effectiveLimit.String()),
+			slog.String("spent", spent.String()),
 			slog.Float64("percentage", percentage),
 		)
 	} else if isNearLimit {
 		s.logger.Info("budget near limit",
 			slog.Uint64("budget_id", uint64(budget.ID)),
-			slog.Uint64("user_id", uint64(userID)),
-			slog.Float64("budget_amount", budget.Amount),
-			slog.Float64("spent", spent),
+			slog.Uint64("user_id", uint64(budget.UserID)),
+			slog.String("effective_limit", effectiveLimit.String()),
+			slog.String("spent", spent.String()),
 			slog.Float64("percentage", percentage),
 		)
 	}
 
-	return status, nil
+	return models.BudgetStatus{
+		Budget:         budget,
+		CarriedOver:    carried,
+		EffectiveLimit: effectiveLimit,
+		Spent:          spent,
+		Remaining:      remaining,
+		Percentage:     percentage,
+		IsExceeded:     isExceeded,
+		IsNearLimit:    isNearLimit,
+	}
+}
+
+// rolloverAmount возвращает часть итога месяца, переносимую в следующий месяц
+func rolloverAmount(mode models.RolloverMode, leftover money.Amount) money.Amount {
+	switch {
+	case leftover > 0 && (mode == models.RolloverSurplus || mode == models.RolloverBoth):
+		return leftover
+	case leftover < 0 && (mode == models.RolloverDeficit || mode == models.RolloverBoth):
+		return leftover
+	default:
+		return 0
+	}
+}
+
+// budgetMonthStart возвращает начало месяца бюджета в UTC
+func budgetMonthStart(budget *models.Budget) time.Time {
+	return time.Date(budget.Year, time.Month(budget.Month), 1, 0, 0, 0, 0, time.UTC)
 }
 
-func (s *budgetService) UpdateBudget(id uint, req models.UpdateBudgetRequest) (*models.Budget, error) {
-	budget, err := s.budgets.GetByID(id)
+func (s *budgetService) UpdateBudget(userID, id uint, req models.UpdateBudgetRequest) (*models.Budget, error) {
+	budget, err := s.getOwnedBudget(userID, id, "update_budget")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Warn("budget not found for update",
-				slog.Uint64("budget_id", uint64(id)),
-			)
-			return nil, ErrBudgetNotFound
-		}
-		s.logger.Error("failed to fetch budget before update",
-			slog.String("op", "update_budget"),
-			slog.Uint64("budget_id", uint64(id)),
-			slog.String("error", err.Error()),
-		)
 		return nil, err
 	}
 
+	before := *budget
+
 	if err := s.applyBudgetUpdate(budget, req); err != nil {
 		s.logger.Warn("budget update validation failed",
 			slog.Uint64("budget_id", uint64(id)),
@@ -268,6 +450,12 @@ func (s *budgetService) UpdateBudget(id uint, req models.UpdateBudgetRequest) (*
 		return nil, err
 	}
 
+	if budget.Month != before.Month || budget.Year != before.Year {
+		if err := s.ensureBudgetSlotFree(userID, budget.Month, budget.Year, budget.CategoryID, budget.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := s.budgets.Update(budget); err != nil {
 		s.logger.Error("budget update failed",
 			slog.String("op", "update_budget"),
@@ -279,28 +467,26 @@ func (s *budgetService) UpdateBudget(id uint, req models.UpdateBudgetRequest) (*
 
 	s.logger.Info("budget updated",
 		slog.Uint64("budget_id", uint64(budget.ID)),
-		slog.Float64("amount", budget.Amount),
+		slog.String("amount", budget.Amount.String()),
 		slog.Int("month", budget.Month),
 		slog.Int("year", budget.Year),
 	)
 
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeBudgetUpdated,
+		EntityType:   models.EntityTypeBudget,
+		EntityID:     budget.ID,
+		Description:  "изменен бюджет",
+		Metadata:     activityMetadata(before, budget),
+	})
+
 	return budget, nil
 }
 
-func (s *budgetService) DeleteBudget(id uint) error {
-	_, err := s.budgets.GetByID(id)
+func (s *budgetService) DeleteBudget(userID, id uint) error {
+	budget, err := s.getOwnedBudget(userID, id, "delete_budget")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Warn("budget not found for delete",
-				slog.Uint64("budget_id", uint64(id)),
-			)
-			return ErrBudgetNotFound
-		}
-		s.logger.Error("failed to fetch budget before delete",
-			slog.String("op", "delete_budget"),
-			slog.Uint64("budget_id", uint64(id)),
-			slog.String("error", err.Error()),
-		)
 		return err
 	}
 
@@ -317,10 +503,80 @@ func (s *budgetService) DeleteBudget(id uint) error {
 		slog.Uint64("budget_id", uint64(id)),
 	)
 
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeBudgetDeleted,
+		EntityType:   models.EntityTypeBudget,
+		EntityID:     id,
+		Description:  "удален бюджет",
+		Metadata:     activityMetadata(budget, nil),
+	})
+
 	return nil
 }
 
-func (s *budgetService) validateBudgetCreate(req models.CreateBudgetRequest) error {
+// getOwnedBudget загружает бюджет и проверяет, что он принадлежит пользователю
+func (s *budgetService) getOwnedBudget(userID, id uint, op string) (*models.Budget, error) {
+	budget, err := s.budgets.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("budget not found",
+				slog.String("op", op),
+				slog.Uint64("budget_id", uint64(id)),
+			)
+			return nil, ErrBudgetNotFound
+		}
+		s.logger.Error("failed to fetch budget",
+			slog.String("op", op),
+			slog.Uint64("budget_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if budget.UserID != userID {
+		s.logger.Warn("budget belongs to another user",
+			slog.String("op", op),
+			slog.Uint64("budget_id", uint64(id)),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		return nil, ErrForbidden
+	}
+
+	return budget, nil
+}
+
+// ensureBudgetSlotFree проверяет, что на месяц еще нет бюджета с той же категорией (или общего бюджета)
+func (s *budgetService) ensureBudgetSlotFree(userID uint, month, year int, categoryID *uint, excludeID uint) error {
+	existing, err := s.budgets.GetByUserIDAndMonth(userID, month, year, categoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		s.logger.Error("failed to check existing budget",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Int("month", month),
+			slog.Int("year", year),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	if existing.ID == excludeID {
+		return nil
+	}
+
+	s.logger.Warn("budget already exists for this month",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("month", month),
+		slog.Int("year", year),
+	)
+	if categoryID != nil {
+		return errors.New("бюджет для этой категории на этот месяц уже существует")
+	}
+	return errors.New("бюджет на этот месяц уже существует")
+}
+
+func (s *budgetService) validateBudgetCreate(userID uint, req models.CreateBudgetRequest) error {
 	if req.Amount <= 0 {
 		return errors.New("сумма бюджета должна быть больше нуля")
 	}
@@ -333,15 +589,21 @@ func (s *budgetService) validateBudgetCreate(req models.CreateBudgetRequest) err
 		return errors.New("год должен быть в диапазоне 2000-2100")
 	}
 
+	if req.CategoryID != nil {
+		_, err := categoryReference(s.categories, s.logger, userID, *req.CategoryID, models.CategoryTypeExpense)
+		return err
+	}
+
 	return nil
 }
 
 func (s *budgetService) applyBudgetUpdate(budget *models.Budget, req models.UpdateBudgetRequest) error {
 	if req.Amount != nil {
-		if *req.Amount <= 0 {
-			return errors.New("сумма бюджета должна быть больше нуля")
+		amount, err := roundToCurrency(*req.Amount, budget.Currency)
+		if err != nil {
+			return err
 		}
-		budget.Amount = *req.Amount
+		budget.Amount = amount
 	}
 
 	if req.Month != nil {
@@ -358,30 +620,41 @@ func (s *budgetService) applyBudgetUpdate(budget *models.Budget, req models.Upda
 		budget.Year = *req.Year
 	}
 
+	if req.RolloverMode != nil {
+		switch *req.RolloverMode {
+		case models.RolloverNone, models.RolloverSurplus, models.RolloverDeficit, models.RolloverBoth:
+			budget.RolloverMode = *req.RolloverMode
+		default:
+			return errors.New("режим переноса должен быть одним из: none, surplus, deficit, both")
+		}
+	}
+
 	return nil
 }
 
-func (s *budgetService) calculateSpentAmount(userID uint, month, year int) (float64, error) {
-	// Определяем начало и конец месяца
-	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
-	endDate := startDate.AddDate(0, 1, 0).Add(-time.Nanosecond)
-
-	// Получаем расходы пользователя за указанный период
+// calculateSpentByMonth считает расходы в валюте currency по месяцам в диапазоне [from, to);
+// при указанной категории учитываются только расходы ее и ее подкатегорий
+func (s *budgetService) calculateSpentByMonth(userID uint, categoryID *uint, currency string, from, to time.Time) (map[time.Time]money.Amount, error) {
+	endDate := to.Add(-time.Nanosecond)
 	filter := models.ExpenseFilter{
-		UserID:    userID,
-		StartDate: &startDate,
-		EndDate:   &endDate,
+		UserID:               userID,
+		CategoryID:           categoryID,
+		IncludeSubcategories: categoryID != nil,
+		StartDate:            &from,
+		EndDate:              &endDate,
 	}
-	expenses, err := s.expenses.List(filter)
+
+	// Дневные суммы считаются в БД, пересчет по курсам и сложение по месяцам — здесь
+	daily, err := convertedDailyTotals(s.expenses, s.currencies, filter, currency)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	// Суммируем расходы
-	var total float64
-	for _, expense := range expenses {
-		total += expense.Amount
+	totals := sumByPeriod(daily, models.PeriodMonth)
+	spent := make(map[time.Time]money.Amount, len(totals))
+	for _, t := range totals {
+		spent[t.PeriodStart] = t.TotalAmount
 	}
 
-	return total, nil
+	return spent, nil
 }
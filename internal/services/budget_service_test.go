@@ -0,0 +1,337 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
+	"cashcontrol/internal/repository"
+	"errors"
+	"io"
+	"log/slog"
+	"sort"
+	"testing"
+	"time"
+)
+
+// fakeBudgetRepo хранит бюджеты в памяти; методы, не нужные тестам, не реализованы
+type fakeBudgetRepo struct {
+	repository.BudgetRepository
+	budgets []models.Budget
+}
+
+func (r *fakeBudgetRepo) ListByUserIDAndMonth(userID uint, month, year int) ([]models.Budget, error) {
+	var result []models.Budget
+	for _, b := range r.budgets {
+		if b.UserID == userID && b.Month == month && b.Year == year {
+			result = append(result, b)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeBudgetRepo) ListPrevious(userID uint, categoryID *uint, month, year, limit int) ([]models.Budget, error) {
+	var result []models.Budget
+	for _, b := range r.budgets {
+		if b.UserID != userID || !sameCategory(b.CategoryID, categoryID) {
+			continue
+		}
+		if b.Year*12+b.Month < year*12+month {
+			result = append(result, b)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Year*12+result[i].Month > result[j].Year*12+result[j].Month
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// fakeExpenseRepo отдает заранее заданные суммы расходов, каждая — одним днем в начале месяца
+type fakeExpenseRepo struct {
+	repository.ExpenseRepository
+	spent map[time.Time]float64
+
+	// Категории, по которым запрашивались суммы; nil — общий бюджет
+	queriedCategories []*uint
+}
+
+func (r *fakeExpenseRepo) SumByDay(filter models.ExpenseFilter) ([]models.DailyTotal, error) {
+	if filter.CategoryID != nil && !filter.IncludeSubcategories {
+		return nil, errors.New("бюджет категории должен учитывать подкатегории")
+	}
+	r.queriedCategories = append(r.queriedCategories, filter.CategoryID)
+	var result []models.DailyTotal
+	for day, amount := range r.spent {
+		if day.Before(*filter.StartDate) || day.After(*filter.EndDate) {
+			continue
+		}
+		result = append(result, models.DailyTotal{Day: day, Currency: testCurrency, TotalAmount: money.FromFloat(amount)})
+	}
+	return result, nil
+}
+
+// fakeIncomeRepo отдает доходы месяцев, как fakeExpenseRepo — расходы
+type fakeIncomeRepo struct {
+	repository.IncomeRepository
+	earned map[time.Time]float64
+}
+
+func (r *fakeIncomeRepo) SumByDay(filter models.IncomeFilter) ([]models.DailyTotal, error) {
+	var result []models.DailyTotal
+	for day, amount := range r.earned {
+		if day.Before(*filter.StartDate) || day.After(*filter.EndDate) {
+			continue
+		}
+		result = append(result, models.DailyTotal{Day: day, Currency: testCurrency, TotalAmount: money.FromFloat(amount)})
+	}
+	return result, nil
+}
+
+// fakeUserRepo отдает пользователя с базовой валютой testCurrency
+type fakeUserRepo struct {
+	repository.UserRepository
+}
+
+func (r *fakeUserRepo) GetByID(id uint) (*models.User, error) {
+	user := &models.User{BaseCurrency: testCurrency}
+	user.ID = id
+	return user, nil
+}
+
+func sameCategory(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func monthStart(year, month int) time.Time {
+	return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// testCurrency валюта бюджетов и расходов в тестах, пересчет курсов не требуется
+const testCurrency = "RUB"
+
+func testBudget(year, month int, amount float64, mode models.RolloverMode) models.Budget {
+	return models.Budget{UserID: 1, Month: month, Year: year, Amount: money.FromFloat(amount), Currency: testCurrency, RolloverMode: mode}
+}
+
+func withCurrency(budget models.Budget, currency string) models.Budget {
+	budget.Currency = currency
+	return budget
+}
+
+func TestGetBudgetStatusRollover(t *testing.T) {
+	tests := []struct {
+		name          string
+		budgets       []models.Budget
+		spent         map[time.Time]float64
+		wantCarried   float64
+		wantLimit     float64
+		wantRemaining float64
+	}{
+		{
+			name:          "без предыдущих месяцев",
+			budgets:       []models.Budget{testBudget(2024, 3, 1000, models.RolloverBoth)},
+			spent:         map[time.Time]float64{monthStart(2024, 3): 200},
+			wantLimit:     1000,
+			wantRemaining: 800,
+		},
+		{
+			name: "остаток копится по цепочке",
+			budgets: []models.Budget{
+				testBudget(2024, 1, 1000, models.RolloverSurplus),
+				testBudget(2024, 2, 1000, models.RolloverSurplus),
+				testBudget(2024, 3, 1000, models.RolloverNone),
+			},
+			spent: map[time.Time]float64{
+				monthStart(2024, 1): 700,
+				monthStart(2024, 2): 900,
+				monthStart(2024, 3): 100,
+			},
+			wantCarried:   400,
+			wantLimit:     1400,
+			wantRemaining: 1300,
+		},
+		{
+			name: "surplus не переносит перерасход",
+			budgets: []models.Budget{
+				testBudget(2024, 2, 1000, models.RolloverSurplus),
+				testBudget(2024, 3, 1000, models.RolloverNone),
+			},
+			spent: map[time.Time]float64{
+				monthStart(2024, 2): 1300,
+			},
+			wantLimit:     1000,
+			wantRemaining: 1000,
+		},
+		{
+			name: "deficit уменьшает лимит",
+			budgets: []models.Budget{
+				testBudget(2024, 2, 1000, models.RolloverDeficit),
+				testBudget(2024, 3, 1000, models.RolloverNone),
+			},
+			spent: map[time.Time]float64{
+				monthStart(2024, 2): 1300,
+				monthStart(2024, 3): 600,
+			},
+			wantCarried:   -300,
+			wantLimit:     700,
+			wantRemaining: 100,
+		},
+		{
+			name: "both переносит и остаток, и перерасход",
+			budgets: []models.Budget{
+				testBudget(2024, 1, 1000, models.RolloverBoth),
+				testBudget(2024, 2, 1000, models.RolloverBoth),
+				testBudget(2024, 3, 1000, models.RolloverNone),
+			},
+			spent: map[time.Time]float64{
+				monthStart(2024, 1): 500,
+				monthStart(2024, 2): 1800,
+			},
+			wantCarried:   -300,
+			wantLimit:     700,
+			wantRemaining: 700,
+		},
+		{
+			name: "пропущенный месяц обрывает цепочку",
+			budgets: []models.Budget{
+				testBudget(2023, 12, 1000, models.RolloverSurplus),
+				testBudget(2024, 2, 1000, models.RolloverSurplus),
+				testBudget(2024, 3, 1000, models.RolloverNone),
+			},
+			spent: map[time.Time]float64{
+				monthStart(2024, 2): 800,
+			},
+			wantCarried:   200,
+			wantLimit:     1200,
+			wantRemaining: 1200,
+		},
+		{
+			name: "месяц без переноса сбрасывает накопленное",
+			budgets: []models.Budget{
+				testBudget(2024, 1, 1000, models.RolloverSurplus),
+				testBudget(2024, 2, 1000, models.RolloverNone),
+				testBudget(2024, 3, 1000, models.RolloverNone),
+			},
+			spent: map[time.Time]float64{
+				monthStart(2024, 1): 500,
+				monthStart(2024, 2): 100,
+			},
+			wantLimit:     1000,
+			wantRemaining: 1000,
+		},
+		{
+			name: "смена валюты обрывает цепочку",
+			budgets: []models.Budget{
+				withCurrency(testBudget(2024, 2, 100, models.RolloverSurplus), "USD"),
+				testBudget(2024, 3, 1000, models.RolloverNone),
+			},
+			spent:         map[time.Time]float64{},
+			wantLimit:     1000,
+			wantRemaining: 1000,
+		},
+		{
+			name: "переходит через границу года",
+			budgets: []models.Budget{
+				testBudget(2023, 12, 500, models.RolloverSurplus),
+				testBudget(2024, 1, 500, models.RolloverNone),
+			},
+			spent: map[time.Time]float64{
+				monthStart(2023, 12): 200,
+				monthStart(2024, 1):  100,
+			},
+			wantCarried:   300,
+			wantLimit:     800,
+			wantRemaining: 700,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			last := tt.budgets[len(tt.budgets)-1]
+			svc := newTestBudgetService(tt.budgets, tt.spent)
+
+			status, err := svc.GetBudgetStatus(1, last.Month, last.Year)
+			if err != nil {
+				t.Fatalf("GetBudgetStatus: %v", err)
+			}
+			got := status.Overall
+			if got == nil {
+				t.Fatal("нет статуса общего бюджета")
+			}
+			if got.CarriedOver != money.FromFloat(tt.wantCarried) {
+				t.Errorf("CarriedOver = %v, want %v", got.CarriedOver, tt.wantCarried)
+			}
+			if got.EffectiveLimit != money.FromFloat(tt.wantLimit) {
+				t.Errorf("EffectiveLimit = %v, want %v", got.EffectiveLimit, tt.wantLimit)
+			}
+			if got.Remaining != money.FromFloat(tt.wantRemaining) {
+				t.Errorf("Remaining = %v, want %v", got.Remaining, tt.wantRemaining)
+			}
+		})
+	}
+}
+
+func TestGetBudgetStatusRolloverPerCategory(t *testing.T) {
+	food := uint(7)
+	previous := testBudget(2024, 2, 300, models.RolloverSurplus)
+	previous.CategoryID = &food
+	current := testBudget(2024, 3, 300, models.RolloverNone)
+	current.CategoryID = &food
+	budgets := []models.Budget{
+		testBudget(2024, 2, 1000, models.RolloverSurplus),
+		testBudget(2024, 3, 1000, models.RolloverNone),
+		previous,
+		current,
+	}
+	// Фейк не различает категории, поэтому расход февраля одинаков для обоих бюджетов
+	spent := map[time.Time]float64{monthStart(2024, 2): 100}
+
+	status, err := newTestBudgetService(budgets, spent).GetBudgetStatus(1, 3, 2024)
+	if err != nil {
+		t.Fatalf("GetBudgetStatus: %v", err)
+	}
+	if status.Overall == nil || status.Overall.CarriedOver != money.FromFloat(900) {
+		t.Errorf("общий бюджет: перенос = %+v, want 900", status.Overall)
+	}
+	if len(status.Categories) != 1 || status.Categories[0].CarriedOver != money.FromFloat(200) {
+		t.Errorf("бюджет категории: %+v, want перенос 200", status.Categories)
+	}
+}
+
+func TestGetBudgetStatusAvailableFunds(t *testing.T) {
+	svc := newTestBudgetService(
+		[]models.Budget{testBudget(2024, 3, 1000, models.RolloverNone)},
+		map[time.Time]float64{monthStart(2024, 2): 300, monthStart(2024, 3): 1200},
+	)
+	svc.incomes = &fakeIncomeRepo{earned: map[time.Time]float64{monthStart(2024, 3): 5000}}
+
+	status, err := svc.GetBudgetStatus(1, 3, 2024)
+	if err != nil {
+		t.Fatalf("GetBudgetStatus: %v", err)
+	}
+	if status.Currency != testCurrency ||
+		status.Income != money.FromFloat(5000) ||
+		status.Expenses != money.FromFloat(1200) ||
+		status.AvailableFunds != money.FromFloat(3800) {
+		t.Errorf("status = %s: доход %s, расходы %s, доступно %s; want 5000, 1200, 3800",
+			status.Currency, status.Income, status.Expenses, status.AvailableFunds)
+	}
+}
+
+func newTestBudgetService(budgets []models.Budget, spent map[time.Time]float64) *budgetService {
+	for i := range budgets {
+		budgets[i].ID = uint(i + 1)
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return &budgetService{
+		budgets:    &fakeBudgetRepo{budgets: budgets},
+		expenses:   &fakeExpenseRepo{spent: spent},
+		incomes:    &fakeIncomeRepo{},
+		currencies: &currencyService{users: &fakeUserRepo{}, logger: logger},
+		logger:     logger,
+	}
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+type CashFlowService interface {
+	GetCashFlow(userID uint, period models.StatisticsPeriod, startDate, endDate *time.Time) ([]models.CashFlowPeriod, error)
+}
+
+type cashFlowService struct {
+	expenses   repository.ExpenseRepository
+	incomes    repository.IncomeRepository
+	currencies CurrencyService
+	logger     *slog.Logger
+}
+
+func NewCashFlowService(
+	expenses repository.ExpenseRepository,
+	incomes repository.IncomeRepository,
+	currencies CurrencyService,
+	logger *slog.Logger,
+) CashFlowService {
+	return &cashFlowService{
+		expenses:   expenses,
+		incomes:    incomes,
+		currencies: currencies,
+		logger:     logger,
+	}
+}
+
+// GetCashFlow возвращает доходы, расходы и их разницу по каждому периоду диапазона,
+// в котором было движение денег; суммы пересчитываются в базовую валюту пользователя
+func (s *cashFlowService) GetCashFlow(userID uint, period models.StatisticsPeriod, startDate, endDate *time.Time) ([]models.CashFlowPeriod, error) {
+	if !isValidStatisticsPeriod(period) {
+		return nil, ErrInvalidStatisticsPeriod
+	}
+	filter, err := statisticsFilter(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, err := s.currencies.BaseCurrency(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := cashFlowByPeriod(s.expenses, s.incomes, s.currencies, filter, period, currency)
+	if err != nil {
+		if !errors.Is(err, ErrMissingExchangeRate) {
+			s.logger.Error("failed to calculate cash flow",
+				slog.String("op", "get_cash_flow"),
+				slog.Uint64("user_id", uint64(userID)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+
+	s.logger.Info("cash flow calculated",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("period", string(period)),
+		slog.Int("periods", len(result)),
+	)
+
+	return result, nil
+}
+
+// cashFlowByPeriod складывает доходы и расходы диапазона фильтра по периодам в валюте currency.
+// Фильтр по категории не применяется к доходам, так как у них свои категории.
+func cashFlowByPeriod(
+	expenses repository.ExpenseRepository,
+	incomes repository.IncomeRepository,
+	currencies CurrencyService,
+	filter models.ExpenseFilter,
+	period models.StatisticsPeriod,
+	currency string,
+) ([]models.CashFlowPeriod, error) {
+	spent, err := convertedDailyTotals(expenses, currencies, filter, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	earned, err := incomes.SumByDay(models.IncomeFilter{
+		UserID:    filter.UserID,
+		StartDate: filter.StartDate,
+		EndDate:   filter.EndDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := currencies.ConvertDailyTotals(filter.UserID, currency, earned); err != nil {
+		return nil, err
+	}
+
+	index := make(map[time.Time]int)
+	result := make([]models.CashFlowPeriod, 0)
+	entry := func(start time.Time) *models.CashFlowPeriod {
+		i, ok := index[start]
+		if !ok {
+			i = len(result)
+			index[start] = i
+			result = append(result, models.CashFlowPeriod{
+				Period:    period,
+				StartDate: start,
+				EndDate:   nextPeriodStart(period, start).AddDate(0, 0, -1),
+				Currency:  currency,
+			})
+		}
+		return &result[i]
+	}
+
+	for _, t := range sumByPeriod(earned, period) {
+		entry(t.PeriodStart).Income = t.TotalAmount
+	}
+	for _, t := range sumByPeriod(spent, period) {
+		entry(t.PeriodStart).Expenses = t.TotalAmount
+	}
+	for i := range result {
+		result[i].Net = result[i].Income - result[i].Expenses
+	}
+
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].StartDate.Before(result[b].StartDate)
+	})
+	return result, nil
+}
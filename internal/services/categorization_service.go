@@ -0,0 +1,504 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
+	"cashcontrol/internal/repository"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCategorizationRuleNotFound = errors.New("правило категоризации не найдено")
+	ErrEmptyCategorizationRule    = errors.New("правило должно содержать хотя бы одно условие: описание, сумму или счет")
+	ErrInvalidRuleRegex           = errors.New("некорректное регулярное выражение")
+	ErrNoMatchingRule             = errors.New("категория не указана и ни одно правило категоризации не подошло")
+)
+
+const (
+	// defaultRulePriority приоритет правила, если он не указан
+	defaultRulePriority = 100
+	// maxRuleRegexLength ограничивает длину регулярного выражения правила
+	maxRuleRegexLength = 500
+	// maxCategorizationChanges ограничивает число изменений, подробно перечисляемых в ответе apply
+	maxCategorizationChanges = 500
+)
+
+type CategorizationService interface {
+	CreateRule(userID uint, req models.CreateCategorizationRuleRequest) (*models.CategorizationRule, error)
+	GetRuleList(userID uint) ([]models.CategorizationRule, error)
+	GetRuleByID(userID, id uint) (*models.CategorizationRule, error)
+	UpdateRule(userID, id uint, req models.UpdateCategorizationRuleRequest) (*models.CategorizationRule, error)
+	DeleteRule(userID, id uint) error
+	ApplyRules(userID uint, req models.ApplyCategorizationRulesRequest) (*models.CategorizationResult, error)
+	Matcher(userID uint) (*CategorizationMatcher, error)
+}
+
+// CategorizationMatcher проверяет операции по заранее загруженным и скомпилированным правилам пользователя
+type CategorizationMatcher struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	rule     models.CategorizationRule
+	contains string
+	regex    *regexp.Regexp
+}
+
+// Match возвращает первое по приоритету правило, все условия которого выполнены, или nil
+func (m *CategorizationMatcher) Match(description string, amount money.Amount, accountID *uint) *models.CategorizationRule {
+	if m == nil {
+		return nil
+	}
+	lowered := strings.ToLower(description)
+	for i := range m.rules {
+		r := &m.rules[i]
+		if r.contains != "" && !strings.Contains(lowered, r.contains) {
+			continue
+		}
+		if r.regex != nil && !r.regex.MatchString(description) {
+			continue
+		}
+		if r.rule.MinAmount != nil && amount < *r.rule.MinAmount {
+			continue
+		}
+		if r.rule.MaxAmount != nil && amount > *r.rule.MaxAmount {
+			continue
+		}
+		if r.rule.AccountID != nil && (accountID == nil || *accountID != *r.rule.AccountID) {
+			continue
+		}
+		return &r.rule
+	}
+	return nil
+}
+
+type categorizationService struct {
+	rules        repository.CategorizationRuleRepository
+	expenses     repository.ExpenseRepository
+	categories   repository.CategoryRepository
+	accounts     repository.AccountRepository
+	activityLogs ActivityLogService
+	logger       *slog.Logger
+}
+
+func NewCategorizationService(
+	rules repository.CategorizationRuleRepository,
+	expenses repository.ExpenseRepository,
+	categories repository.CategoryRepository,
+	accounts repository.AccountRepository,
+	activityLogs ActivityLogService,
+	logger *slog.Logger,
+) CategorizationService {
+	return &categorizationService{
+		rules:        rules,
+		expenses:     expenses,
+		categories:   categories,
+		accounts:     accounts,
+		activityLogs: activityLogs,
+		logger:       logger,
+	}
+}
+
+func (s *categorizationService) CreateRule(userID uint, req models.CreateCategorizationRuleRequest) (*models.CategorizationRule, error) {
+	rule := &models.CategorizationRule{
+		UserID:              userID,
+		CategoryID:          req.CategoryID,
+		Name:                strings.TrimSpace(req.Name),
+		Priority:            defaultRulePriority,
+		DescriptionContains: strings.TrimSpace(req.DescriptionContains),
+		DescriptionRegex:    strings.TrimSpace(req.DescriptionRegex),
+		MinAmount:           req.MinAmount,
+		MaxAmount:           req.MaxAmount,
+		AccountID:           req.AccountID,
+		IsActive:            true,
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := s.validateRule(userID, rule); err != nil {
+		s.logger.Warn("categorization rule create validation failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Uint64("category_id", uint64(req.CategoryID)),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := s.rules.Create(rule); err != nil {
+		s.logger.Error("categorization rule create failed",
+			slog.String("op", "create_categorization_rule"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	s.logger.Info("categorization rule created",
+		slog.Uint64("rule_id", uint64(rule.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("category_id", uint64(rule.CategoryID)),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeCategorizationRuleCreated,
+		EntityType:   models.EntityTypeCategorizationRule,
+		EntityID:     rule.ID,
+		Description:  "создано правило категоризации",
+		Metadata:     activityMetadata(nil, rule),
+	})
+
+	return rule, nil
+}
+
+func (s *categorizationService) GetRuleList(userID uint) ([]models.CategorizationRule, error) {
+	rules, err := s.rules.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to list categorization rules",
+			slog.String("op", "list_categorization_rules"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("categorization rules listed",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(rules)),
+	)
+
+	return rules, nil
+}
+
+func (s *categorizationService) GetRuleByID(userID, id uint) (*models.CategorizationRule, error) {
+	rule, err := s.getOwnedRule(userID, id, "get_categorization_rule_by_id")
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("categorization rule retrieved",
+		slog.Uint64("rule_id", uint64(rule.ID)),
+	)
+
+	return rule, nil
+}
+
+func (s *categorizationService) UpdateRule(userID, id uint, req models.UpdateCategorizationRuleRequest) (*models.CategorizationRule, error) {
+	rule, err := s.getOwnedRule(userID, id, "update_categorization_rule")
+	if err != nil {
+		return nil, err
+	}
+
+	before := *rule
+
+	if req.CategoryID != nil {
+		rule.CategoryID = *req.CategoryID
+	}
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.DescriptionContains != nil {
+		rule.DescriptionContains = strings.TrimSpace(*req.DescriptionContains)
+	}
+	if req.DescriptionRegex != nil {
+		rule.DescriptionRegex = strings.TrimSpace(*req.DescriptionRegex)
+	}
+	if req.MinAmount != nil {
+		rule.MinAmount = amountCondition(*req.MinAmount)
+	}
+	if req.MaxAmount != nil {
+		rule.MaxAmount = amountCondition(*req.MaxAmount)
+	}
+	if req.AccountID != nil {
+		rule.AccountID = accountIDUpdate(*req.AccountID)
+	}
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	if err := s.validateRule(userID, rule); err != nil {
+		s.logger.Warn("categorization rule update validation failed",
+			slog.Uint64("rule_id", uint64(id)),
+			slog.Any("request", req),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := s.rules.Update(rule); err != nil {
+		s.logger.Error("categorization rule update failed",
+			slog.String("op", "update_categorization_rule"),
+			slog.Uint64("rule_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	s.logger.Info("categorization rule updated",
+		slog.Uint64("rule_id", uint64(rule.ID)),
+		slog.Uint64("category_id", uint64(rule.CategoryID)),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeCategorizationRuleUpdated,
+		EntityType:   models.EntityTypeCategorizationRule,
+		EntityID:     rule.ID,
+		Description:  "изменено правило категоризации",
+		Metadata:     activityMetadata(before, rule),
+	})
+
+	return rule, nil
+}
+
+func (s *categorizationService) DeleteRule(userID, id uint) error {
+	rule, err := s.getOwnedRule(userID, id, "delete_categorization_rule")
+	if err != nil {
+		return err
+	}
+
+	if err := s.rules.Delete(id); err != nil {
+		s.logger.Error("categorization rule delete failed",
+			slog.String("op", "delete_categorization_rule"),
+			slog.Uint64("rule_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("categorization rule deleted",
+		slog.Uint64("rule_id", uint64(id)),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeCategorizationRuleDeleted,
+		EntityType:   models.EntityTypeCategorizationRule,
+		EntityID:     id,
+		Description:  "удалено правило категоризации",
+		Metadata:     activityMetadata(rule, nil),
+	})
+
+	return nil
+}
+
+// ApplyRules заново применяет правила к расходам из выборки и возвращает список изменений.
+// При dry_run изменения только вычисляются; иначе все переносы сохраняются одной транзакцией.
+func (s *categorizationService) ApplyRules(userID uint, req models.ApplyCategorizationRulesRequest) (*models.CategorizationResult, error) {
+	if req.StartDate != nil && req.EndDate != nil && req.StartDate.After(*req.EndDate) {
+		return nil, ErrInvalidDateRange
+	}
+
+	matcher, err := s.Matcher(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CategorizationResult{
+		DryRun:  req.DryRun,
+		Changes: []models.CategorizationChange{},
+	}
+	filter := models.ExpenseFilter{
+		UserID:     userID,
+		CategoryID: req.CategoryID,
+		AccountID:  req.AccountID,
+		StartDate:  req.StartDate,
+		EndDate:    req.EndDate,
+	}
+	// Для переноса хранятся только идентификаторы, подробности — не больше maxCategorizationChanges
+	assignments := make(map[uint][]uint)
+	var expenseIDs []uint
+	err = s.expenses.Stream(filter, func(row models.ExpenseExportRow) error {
+		result.Checked++
+		rule := matcher.Match(row.Description, row.Amount, row.AccountID)
+		if rule == nil {
+			return nil
+		}
+		result.Matched++
+		if rule.CategoryID == row.CategoryID {
+			return nil
+		}
+		assignments[rule.CategoryID] = append(assignments[rule.CategoryID], row.ID)
+		expenseIDs = append(expenseIDs, row.ID)
+		if len(result.Changes) == maxCategorizationChanges {
+			result.Truncated = true
+			return nil
+		}
+		result.Changes = append(result.Changes, models.CategorizationChange{
+			ExpenseID:      row.ID,
+			Description:    row.Description,
+			Amount:         row.Amount,
+			Currency:       row.Currency,
+			FromCategoryID: row.CategoryID,
+			ToCategoryID:   rule.CategoryID,
+			RuleID:         rule.ID,
+		})
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("failed to read expenses for categorization",
+			slog.String("op", "apply_categorization_rules"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	result.Changed = len(expenseIDs)
+
+	if req.DryRun || result.Changed == 0 {
+		s.logger.Info("categorization rules evaluated",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Int("checked", result.Checked),
+			slog.Int("changed", result.Changed),
+			slog.Bool("dry_run", req.DryRun),
+		)
+		return result, nil
+	}
+
+	if err := s.expenses.ReassignCategories(assignments); err != nil {
+		s.logger.Error("failed to reassign expense categories",
+			slog.String("op", "apply_categorization_rules"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Int("changed", result.Changed),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	s.logger.Info("categorization rules applied",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("checked", result.Checked),
+		slog.Int("changed", result.Changed),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeExpensesRecategorized,
+		EntityType:   models.EntityTypeExpense,
+		EntityID:     expenseIDs[0],
+		Description:  fmt.Sprintf("изменена категория расходов по правилам: %d", result.Changed),
+		Metadata: map[string]interface{}{
+			"checked":     result.Checked,
+			"changed":     result.Changed,
+			"expense_ids": expenseIDs,
+		},
+	})
+
+	return result, nil
+}
+
+// Matcher загружает активные правила пользователя и компилирует их для проверки операций
+func (s *categorizationService) Matcher(userID uint) (*CategorizationMatcher, error) {
+	rules, err := s.rules.GetActiveByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to load categorization rules",
+			slog.String("op", "categorization_matcher"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	matcher := &CategorizationMatcher{rules: make([]compiledRule, 0, len(rules))}
+	for _, rule := range rules {
+		compiled := compiledRule{
+			rule:     rule,
+			contains: strings.ToLower(rule.DescriptionContains),
+		}
+		if rule.DescriptionRegex != "" {
+			// Выражение проверено при сохранении; правило с испорченным выражением пропускается
+			re, err := regexp.Compile(rule.DescriptionRegex)
+			if err != nil {
+				s.logger.Warn("categorization rule regex does not compile",
+					slog.Uint64("rule_id", uint64(rule.ID)),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+			compiled.regex = re
+		}
+		matcher.rules = append(matcher.rules, compiled)
+	}
+	return matcher, nil
+}
+
+// getOwnedRule загружает правило и проверяет, что оно принадлежит пользователю
+func (s *categorizationService) getOwnedRule(userID, id uint, op string) (*models.CategorizationRule, error) {
+	rule, err := s.rules.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("categorization rule not found",
+				slog.String("op", op),
+				slog.Uint64("rule_id", uint64(id)),
+			)
+			return nil, ErrCategorizationRuleNotFound
+		}
+		s.logger.Error("failed to fetch categorization rule",
+			slog.String("op", op),
+			slog.Uint64("rule_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if rule.UserID != userID {
+		s.logger.Warn("categorization rule belongs to another user",
+			slog.String("op", op),
+			slog.Uint64("rule_id", uint64(id)),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		return nil, ErrForbidden
+	}
+
+	return rule, nil
+}
+
+func (s *categorizationService) validateRule(userID uint, rule *models.CategorizationRule) error {
+	if rule.DescriptionContains == "" && rule.DescriptionRegex == "" &&
+		rule.MinAmount == nil && rule.MaxAmount == nil && rule.AccountID == nil {
+		return ErrEmptyCategorizationRule
+	}
+
+	if rule.DescriptionRegex != "" {
+		if len(rule.DescriptionRegex) > maxRuleRegexLength {
+			return fmt.Errorf("%w: длина не должна превышать %d символов", ErrInvalidRuleRegex, maxRuleRegexLength)
+		}
+		if _, err := regexp.Compile(rule.DescriptionRegex); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidRuleRegex, err.Error())
+		}
+	}
+
+	if rule.MinAmount != nil && *rule.MinAmount <= 0 || rule.MaxAmount != nil && *rule.MaxAmount <= 0 {
+		return errors.New("границы суммы должны быть больше нуля")
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return errors.New("минимальная сумма не может быть больше максимальной")
+	}
+
+	if rule.AccountID != nil {
+		if _, err := accountReference(s.accounts, s.logger, userID, *rule.AccountID); err != nil {
+			return err
+		}
+	}
+
+	_, err := categoryReference(s.categories, s.logger, userID, rule.CategoryID, models.CategoryTypeExpense)
+	return err
+}
+
+// amountCondition возвращает границу суммы из запроса на изменение: 0 снимает условие
+func amountCondition(amount money.Amount) *money.Amount {
+	if amount == 0 {
+		return nil
+	}
+	return &amount
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
+	"cashcontrol/internal/repository"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+// fakeCategorizationRuleRepo отдает активные правила пользователя
+type fakeCategorizationRuleRepo struct {
+	repository.CategorizationRuleRepository
+	rules []models.CategorizationRule
+}
+
+func (r *fakeCategorizationRuleRepo) GetActiveByUserID(userID uint) ([]models.CategorizationRule, error) {
+	return r.rules, nil
+}
+
+// fakeStreamExpenseRepo построчно отдает расходы и запоминает переносы категорий
+type fakeStreamExpenseRepo struct {
+	repository.ExpenseRepository
+	rows        []models.ExpenseExportRow
+	assignments map[uint][]uint
+}
+
+func (r *fakeStreamExpenseRepo) Stream(filter models.ExpenseFilter, fn func(models.ExpenseExportRow) error) error {
+	for _, row := range r.rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fakeStreamExpenseRepo) ReassignCategories(assignments map[uint][]uint) error {
+	r.assignments = assignments
+	return nil
+}
+
+func TestApplyRulesCapsChanges(t *testing.T) {
+	const total = maxCategorizationChanges + 100
+
+	taxi := models.CategorizationRule{CategoryID: 9, DescriptionContains: "такси"}
+	taxi.ID = 1
+	expenses := &fakeStreamExpenseRepo{}
+	for i := 1; i <= total; i++ {
+		expenses.rows = append(expenses.rows, models.ExpenseExportRow{
+			ID:          uint(i),
+			CategoryID:  7,
+			Amount:      money.FromFloat(300),
+			Currency:    testCurrency,
+			Description: "Такси до офиса",
+		})
+	}
+	// Расход уже в нужной категории подходит под правило, но не меняется
+	expenses.rows = append(expenses.rows, models.ExpenseExportRow{ID: total + 1, CategoryID: 9, Description: "такси"})
+
+	activityLogs := &fakeActivityLogService{}
+	svc := &categorizationService{
+		rules:        &fakeCategorizationRuleRepo{rules: []models.CategorizationRule{taxi}},
+		expenses:     expenses,
+		activityLogs: activityLogs,
+		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	result, err := svc.ApplyRules(1, models.ApplyCategorizationRulesRequest{})
+	if err != nil {
+		t.Fatalf("ApplyRules: %v", err)
+	}
+	if result.Checked != total+1 || result.Matched != total+1 || result.Changed != total {
+		t.Errorf("checked %d, matched %d, changed %d", result.Checked, result.Matched, result.Changed)
+	}
+	if len(result.Changes) != maxCategorizationChanges || !result.Truncated {
+		t.Errorf("changes: %d, truncated %v; want %d, true", len(result.Changes), result.Truncated, maxCategorizationChanges)
+	}
+
+	// Переносятся все подходящие расходы, а не только перечисленные в ответе
+	if len(expenses.assignments) != 1 || len(expenses.assignments[9]) != total {
+		t.Errorf("перенесено в категорию 9: %d, want %d", len(expenses.assignments[9]), total)
+	}
+
+	if len(activityLogs.logged) != 1 {
+		t.Fatalf("записей истории: %d, want 1", len(activityLogs.logged))
+	}
+	metadata := activityLogs.logged[0].Metadata
+	if _, ok := metadata["changes"]; ok {
+		t.Error("история содержит подробный список изменений")
+	}
+	if ids, ok := metadata["expense_ids"].([]uint); !ok || len(ids) != total {
+		t.Errorf("expense_ids в истории: %v", metadata["expense_ids"])
+	}
+}
+
+func TestApplyRulesDryRunKeepsCategories(t *testing.T) {
+	taxi := models.CategorizationRule{CategoryID: 9, DescriptionContains: "такси"}
+	expenses := &fakeStreamExpenseRepo{rows: []models.ExpenseExportRow{
+		{ID: 1, CategoryID: 7, Description: "Такси"},
+		{ID: 2, CategoryID: 7, Description: "Кофе"},
+	}}
+	activityLogs := &fakeActivityLogService{}
+	svc := &categorizationService{
+		rules:        &fakeCategorizationRuleRepo{rules: []models.CategorizationRule{taxi}},
+		expenses:     expenses,
+		activityLogs: activityLogs,
+		logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	result, err := svc.ApplyRules(1, models.ApplyCategorizationRulesRequest{DryRun: true})
+	if err != nil {
+		t.Fatalf("ApplyRules: %v", err)
+	}
+	if result.Changed != 1 || len(result.Changes) != 1 || result.Truncated {
+		t.Errorf("result = %+v", result)
+	}
+	if expenses.assignments != nil || len(activityLogs.logged) != 0 {
+		t.Error("dry_run сохранил изменения")
+	}
+}
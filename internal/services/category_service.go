@@ -4,28 +4,50 @@ import (
 	"cashcontrol/internal/models"
 	"cashcontrol/internal/repository"
 	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
+	"sort"
 
 	"gorm.io/gorm"
 )
 
-var ErrCategoryNotFound = errors.New("категория не найдена")
+var (
+	ErrCategoryNotFound     = errors.New("категория не найдена")
+	ErrCategoryTypeMismatch = errors.New("тип категории не соответствует операции")
+	ErrInvalidCategoryType  = errors.New("тип категории должен быть expense или income")
+
+	ErrParentCategoryNotFound     = errors.New("родительская категория не найдена")
+	ErrParentCategoryTypeMismatch = errors.New("тип родительской категории должен совпадать с типом подкатегории")
+	ErrCategoryCycle              = errors.New("категорию нельзя вложить в саму себя или в ее подкатегорию")
+
+	ErrCategoryInUse        = errors.New("категория используется: удалите ее с переносом записей (strategy=reassign) или объедините с другой категорией")
+	ErrMergeTargetRequired  = errors.New("для переноса записей укажите целевую категорию target_id")
+	ErrMergeTargetNotFound  = errors.New("целевая категория не найдена")
+	ErrInvalidMergeTarget   = errors.New("целевая категория не может быть среди удаляемых")
+	ErrMergeTypeMismatch    = errors.New("нельзя объединить категории разных типов")
+	ErrMergeIntoSubcategory = errors.New("нельзя перенести записи в подкатегорию удаляемой категории")
+)
 
 type CategoryService interface {
 	CreateCategory(userID uint, req models.CreateCategoryRequest) (*models.Category, error)
-	GetCategoryList(userID uint) ([]models.Category, error)
-	GetCategoryByID(id uint) (*models.Category, error)
-	UpdateCategory(id uint, req models.UpdateCategoryRequest) (*models.Category, error)
-	DeleteCategory(id uint) error
+	GetCategoryList(userID uint, categoryType *models.CategoryType, page models.PageRequest) (*models.Page[models.Category], error)
+	GetCategoryTree(userID uint, categoryType *models.CategoryType) ([]models.CategoryTreeNode, error)
+	GetCategoryByID(userID, id uint) (*models.Category, error)
+	UpdateCategory(userID, id uint, req models.UpdateCategoryRequest) (*models.Category, error)
+	DeleteCategory(userID, id uint, req models.DeleteCategoryRequest) (*models.CategoryMergeResult, error)
+	MergeCategories(userID uint, req models.MergeCategoriesRequest) (*models.CategoryMergeResult, error)
+	GetCategoryUsage(userID, id uint) (*models.CategoryUsage, error)
 }
 
 type categoryService struct {
-	categories repository.CategoryRepository
-	logger     *slog.Logger
+	categories   repository.CategoryRepository
+	activityLogs ActivityLogService
+	logger       *slog.Logger
 }
 
-func NewCategoryService(categories repository.CategoryRepository, logger *slog.Logger) CategoryService {
-	return &categoryService{categories: categories, logger: logger}
+func NewCategoryService(categories repository.CategoryRepository, activityLogs ActivityLogService, logger *slog.Logger) CategoryService {
+	return &categoryService{categories: categories, activityLogs: activityLogs, logger: logger}
 }
 
 func (s *categoryService) CreateCategory(userID uint, req models.CreateCategoryRequest) (*models.Category, error) {
@@ -38,11 +60,31 @@ func (s *categoryService) CreateCategory(userID uint, req models.CreateCategoryR
 		return nil, err
 	}
 
+	categoryType := req.Type
+	if categoryType == "" {
+		categoryType = models.CategoryTypeExpense
+	}
+
+	var parentID *uint
+	if req.ParentID != nil && *req.ParentID != 0 {
+		if err := s.validateParent(userID, 0, *req.ParentID, categoryType); err != nil {
+			s.logger.Warn("category parent validation failed",
+				slog.Uint64("user_id", uint64(userID)),
+				slog.Uint64("parent_id", uint64(*req.ParentID)),
+				slog.String("reason", err.Error()),
+			)
+			return nil, err
+		}
+		parentID = req.ParentID
+	}
+
 	category := &models.Category{
-		UserID: userID,
-		Name:   req.Name,
-		Color:  req.Color,
-		Icon:   req.Icon,
+		UserID:   userID,
+		ParentID: parentID,
+		Name:     req.Name,
+		Type:     categoryType,
+		Color:    req.Color,
+		Icon:     req.Icon,
 	}
 
 	if err := s.categories.Create(category); err != nil {
@@ -61,12 +103,33 @@ func (s *categoryService) CreateCategory(userID uint, req models.CreateCategoryR
 		slog.String("name", category.Name),
 	)
 
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeCategoryCreated,
+		EntityType:   models.EntityTypeCategory,
+		EntityID:     category.ID,
+		Description:  "создана категория",
+		Metadata:     activityMetadata(nil, category),
+	})
+
 	return category, nil
 }
 
-func (s *categoryService) GetCategoryList(userID uint) ([]models.Category, error) {
-	categories, err := s.categories.GetByUserID(userID)
+// GetCategoryList возвращает страницу категорий пользователя; при указанном типе только категории расходов или доходов
+func (s *categoryService) GetCategoryList(userID uint, categoryType *models.CategoryType, page models.PageRequest) (*models.Page[models.Category], error) {
+	if categoryType != nil && !isValidCategoryType(*categoryType) {
+		return nil, ErrInvalidCategoryType
+	}
+	page, err := categorySorting.normalize(page)
 	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.categories.ListPage(userID, categoryType, page)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, ErrInvalidCursor
+		}
 		s.logger.Error("failed to list categories",
 			slog.String("op", "list_categories"),
 			slog.Uint64("user_id", uint64(userID)),
@@ -77,29 +140,48 @@ func (s *categoryService) GetCategoryList(userID uint) ([]models.Category, error
 
 	s.logger.Info("categories listed",
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Int("count", len(categories)),
+		slog.Int("count", len(categories.Items)),
+		slog.Int64("total", categories.Total),
 	)
 
 	return categories, nil
 }
 
-func (s *categoryService) GetCategoryByID(id uint) (*models.Category, error) {
-	category, err := s.categories.GetByID(id)
+// GetCategoryTree возвращает категории пользователя деревом: корневые категории с вложенными подкатегориями
+func (s *categoryService) GetCategoryTree(userID uint, categoryType *models.CategoryType) ([]models.CategoryTreeNode, error) {
+	if categoryType != nil && !isValidCategoryType(*categoryType) {
+		return nil, ErrInvalidCategoryType
+	}
+
+	categories, err := s.categories.GetByUserID(userID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Warn("category not found",
-				slog.Uint64("category_id", uint64(id)),
-			)
-			return nil, ErrCategoryNotFound
-		}
-		s.logger.Error("failed to get category",
-			slog.String("op", "get_category_by_id"),
-			slog.Uint64("category_id", uint64(id)),
+		s.logger.Error("failed to build category tree",
+			slog.String("op", "get_category_tree"),
+			slog.Uint64("user_id", uint64(userID)),
 			slog.String("error", err.Error()),
 		)
 		return nil, err
 	}
 
+	if categoryType != nil {
+		filtered := make([]models.Category, 0, len(categories))
+		for _, category := range categories {
+			if category.Type == *categoryType {
+				filtered = append(filtered, category)
+			}
+		}
+		categories = filtered
+	}
+
+	return buildCategoryTree(categories), nil
+}
+
+func (s *categoryService) GetCategoryByID(userID, id uint) (*models.Category, error) {
+	category, err := s.getOwnedCategory(userID, id, "get_category_by_id")
+	if err != nil {
+		return nil, err
+	}
+
 	s.logger.Info("category retrieved",
 		slog.Uint64("category_id", uint64(id)),
 		slog.String("name", category.Name),
@@ -108,26 +190,30 @@ func (s *categoryService) GetCategoryByID(id uint) (*models.Category, error) {
 	return category, nil
 }
 
-func (s *categoryService) UpdateCategory(id uint, req models.UpdateCategoryRequest) (*models.Category, error) {
-	category, err := s.categories.GetByID(id)
+func (s *categoryService) UpdateCategory(userID, id uint, req models.UpdateCategoryRequest) (*models.Category, error) {
+	category, err := s.getOwnedCategory(userID, id, "update_category")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Warn("category not found for update",
-				slog.Uint64("category_id", uint64(id)),
-			)
-			return nil, ErrCategoryNotFound
-		}
-		s.logger.Error("failed to fetch category before update",
-			slog.String("op", "update_category"),
-			slog.Uint64("category_id", uint64(id)),
-			slog.String("error", err.Error()),
-		)
 		return nil, err
 	}
 
+	before := *category
+
 	if req.Name != nil {
 		category.Name = *req.Name
 	}
+	if req.ParentID != nil && *req.ParentID == 0 {
+		category.ParentID = nil
+	} else if req.ParentID != nil {
+		if err := s.validateParent(userID, id, *req.ParentID, category.Type); err != nil {
+			s.logger.Warn("category parent validation failed",
+				slog.Uint64("category_id", uint64(id)),
+				slog.Uint64("parent_id", uint64(*req.ParentID)),
+				slog.String("reason", err.Error()),
+			)
+			return nil, err
+		}
+		category.ParentID = req.ParentID
+	}
 	if req.Color != nil {
 		category.Color = *req.Color
 	}
@@ -149,39 +235,254 @@ func (s *categoryService) UpdateCategory(id uint, req models.UpdateCategoryReque
 		slog.String("name", category.Name),
 	)
 
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeCategoryUpdated,
+		EntityType:   models.EntityTypeCategory,
+		EntityID:     category.ID,
+		Description:  "изменена категория",
+		Metadata:     activityMetadata(before, category),
+	})
+
 	return category, nil
 }
 
-func (s *categoryService) DeleteCategory(id uint) error {
-	_, err := s.categories.GetByID(id)
+// DeleteCategory удаляет категорию. Со стратегией refuse (по умолчанию) категорию, на которую ссылаются
+// записи или подкатегории, удалить нельзя; с reassign все записи сначала переносятся в req.TargetID.
+func (s *categoryService) DeleteCategory(userID, id uint, req models.DeleteCategoryRequest) (*models.CategoryMergeResult, error) {
+	category, err := s.getOwnedCategory(userID, id, "delete_category")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Warn("category not found for delete",
+		return nil, err
+	}
+
+	var result *models.CategoryMergeResult
+	if req.Strategy == models.CategoryDeleteReassign {
+		result, err = s.mergeInto(userID, []models.Category{*category}, req.TargetID, "delete_category")
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		usage, err := s.categories.CountUsage(id)
+		if err != nil {
+			s.logger.Error("failed to count category usage",
+				slog.String("op", "delete_category"),
+				slog.Uint64("category_id", uint64(id)),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+		if !isCategoryUnused(usage) {
+			s.logger.Warn("category in use",
 				slog.Uint64("category_id", uint64(id)),
+				slog.Int64("expenses", usage.Expenses),
+				slog.Int64("subcategories", usage.Subcategories),
 			)
-			return ErrCategoryNotFound
+			return nil, ErrCategoryInUse
+		}
+
+		if err := s.categories.Delete(id); err != nil {
+			s.logger.Error("category delete failed",
+				slog.String("op", "delete_category"),
+				slog.Uint64("category_id", uint64(id)),
+				slog.String("error", err.Error()),
+			)
+			return nil, err
+		}
+		result = &models.CategoryMergeResult{DeletedCategoryIDs: []uint{id}}
+	}
+
+	s.logger.Info("category deleted",
+		slog.Uint64("category_id", uint64(id)),
+		slog.String("strategy", string(req.Strategy)),
+	)
+
+	metadata := activityMetadata(category, nil)
+	if result.TargetID != 0 {
+		metadata["merge"] = result
+	}
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeCategoryDeleted,
+		EntityType:   models.EntityTypeCategory,
+		EntityID:     id,
+		Description:  "удалена категория",
+		Metadata:     metadata,
+	})
+
+	return result, nil
+}
+
+// MergeCategories переносит все записи категорий req.SourceIDs в req.TargetID и удаляет исходные категории
+func (s *categoryService) MergeCategories(userID uint, req models.MergeCategoriesRequest) (*models.CategoryMergeResult, error) {
+	sources := make([]models.Category, 0, len(req.SourceIDs))
+	seen := make(map[uint]bool, len(req.SourceIDs))
+	for _, id := range req.SourceIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		category, err := s.getOwnedCategory(userID, id, "merge_categories")
+		if err != nil {
+			return nil, err
 		}
-		s.logger.Error("failed to fetch category before delete",
-			slog.String("op", "delete_category"),
+		sources = append(sources, *category)
+	}
+
+	result, err := s.mergeInto(userID, sources, req.TargetID, "merge_categories")
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("categories merged",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("target_id", uint64(req.TargetID)),
+		slog.Int("sources", len(sources)),
+		slog.Int64("expenses", result.Moved.Expenses),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeCategoriesMerged,
+		EntityType:   models.EntityTypeCategory,
+		EntityID:     req.TargetID,
+		Description:  fmt.Sprintf("объединено категорий: %d", len(sources)),
+		Metadata: map[string]interface{}{
+			"sources": sources,
+			"result":  result,
+		},
+	})
+
+	return result, nil
+}
+
+// GetCategoryUsage возвращает количество записей, которые ссылаются на категорию
+func (s *categoryService) GetCategoryUsage(userID, id uint) (*models.CategoryUsage, error) {
+	if _, err := s.getOwnedCategory(userID, id, "get_category_usage"); err != nil {
+		return nil, err
+	}
+
+	usage, err := s.categories.CountUsage(id)
+	if err != nil {
+		s.logger.Error("failed to count category usage",
+			slog.String("op", "get_category_usage"),
 			slog.Uint64("category_id", uint64(id)),
 			slog.String("error", err.Error()),
 		)
-		return err
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// mergeInto проверяет целевую категорию и переносит в нее записи категорий sources.
+// Цель должна быть того же типа и не может быть подкатегорией переносимых категорий.
+func (s *categoryService) mergeInto(userID uint, sources []models.Category, targetID uint, op string) (*models.CategoryMergeResult, error) {
+	if targetID == 0 {
+		return nil, ErrMergeTargetRequired
+	}
+	target, err := s.getOwnedCategory(userID, targetID, op)
+	if err != nil {
+		if errors.Is(err, ErrCategoryNotFound) || errors.Is(err, ErrForbidden) {
+			return nil, ErrMergeTargetNotFound
+		}
+		return nil, err
 	}
 
-	if err := s.categories.Delete(id); err != nil {
-		s.logger.Error("category delete failed",
-			slog.String("op", "delete_category"),
+	categories, err := s.categories.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to list categories",
+			slog.String("op", op),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	ancestors := newCategoryIndex(categories).ancestors(targetID)
+
+	ids := make([]uint, 0, len(sources))
+	for _, source := range sources {
+		switch {
+		case source.ID == targetID:
+			return nil, ErrInvalidMergeTarget
+		case source.Type != target.Type:
+			return nil, ErrMergeTypeMismatch
+		case slices.Contains(ancestors, source.ID):
+			return nil, ErrMergeIntoSubcategory
+		}
+		ids = append(ids, source.ID)
+	}
+
+	result, err := s.categories.Merge(ids, targetID)
+	if err != nil {
+		s.logger.Error("category merge failed",
+			slog.String("op", op),
+			slog.Uint64("target_id", uint64(targetID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return result, nil
+}
+
+// getOwnedCategory загружает категорию и проверяет, что она принадлежит пользователю
+func (s *categoryService) getOwnedCategory(userID, id uint, op string) (*models.Category, error) {
+	category, err := s.categories.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("category not found",
+				slog.String("op", op),
+				slog.Uint64("category_id", uint64(id)),
+			)
+			return nil, ErrCategoryNotFound
+		}
+		s.logger.Error("failed to fetch category",
+			slog.String("op", op),
 			slog.Uint64("category_id", uint64(id)),
 			slog.String("error", err.Error()),
 		)
-		return err
+		return nil, err
 	}
 
-	s.logger.Info("category deleted",
-		slog.Uint64("category_id", uint64(id)),
-	)
+	if category.UserID != userID {
+		s.logger.Warn("category belongs to another user",
+			slog.String("op", op),
+			slog.Uint64("category_id", uint64(id)),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		return nil, ErrForbidden
+	}
+
+	return category, nil
+}
+
+// validateParent проверяет, что родитель принадлежит пользователю, совпадает по типу
+// и не является самой категорией id или ее подкатегорией; id равен 0 для новой категории
+func (s *categoryService) validateParent(userID, id, parentID uint, categoryType models.CategoryType) error {
+	parent, err := s.categories.GetByID(parentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrParentCategoryNotFound
+		}
+		return err
+	}
+	if parent.UserID != userID {
+		return ErrParentCategoryNotFound
+	}
+	if parent.Type != categoryType {
+		return ErrParentCategoryTypeMismatch
+	}
+	if id == 0 {
+		return nil
+	}
 
+	categories, err := s.categories.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if parentID == id || slices.Contains(newCategoryIndex(categories).ancestors(parentID), id) {
+		return ErrCategoryCycle
+	}
 	return nil
 }
 
@@ -189,5 +490,117 @@ func (s *categoryService) validateCategoryCreate(req models.CreateCategoryReques
 	if req.Name == "" {
 		return errors.New("название категории не может быть пустым")
 	}
+	if req.Type != "" && !isValidCategoryType(req.Type) {
+		return ErrInvalidCategoryType
+	}
 	return nil
 }
+
+// categoryReference проверяет категорию, указанную в записи: чужая категория выглядит как несуществующая,
+// категория другого типа отклоняется
+func categoryReference(categories repository.CategoryRepository, logger *slog.Logger, userID, categoryID uint, categoryType models.CategoryType) (*models.Category, error) {
+	category, err := categories.GetByID(categoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		logger.Error("failed to check category existence",
+			slog.Uint64("category_id", uint64(categoryID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, errors.New("ошибка при проверке категории")
+	}
+
+	if category.UserID != userID {
+		logger.Warn("category belongs to another user",
+			slog.Uint64("category_id", uint64(categoryID)),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		return nil, ErrCategoryNotFound
+	}
+
+	if category.Type != categoryType {
+		return nil, ErrCategoryTypeMismatch
+	}
+
+	return category, nil
+}
+
+// isCategoryUnused сообщает, что на категорию не ссылается ни одна запись
+func isCategoryUnused(usage *models.CategoryUsage) bool {
+	return *usage == models.CategoryUsage{}
+}
+
+func isValidCategoryType(categoryType models.CategoryType) bool {
+	switch categoryType {
+	case models.CategoryTypeExpense, models.CategoryTypeIncome:
+		return true
+	}
+	return false
+}
+
+// categoryIndex категории пользователя по идентификатору для обхода иерархии
+type categoryIndex map[uint]models.Category
+
+func newCategoryIndex(categories []models.Category) categoryIndex {
+	index := make(categoryIndex, len(categories))
+	for _, category := range categories {
+		index[category.ID] = category
+	}
+	return index
+}
+
+// parent возвращает родителя категории, если он есть среди неудаленных категорий пользователя
+func (idx categoryIndex) parent(id uint) (uint, bool) {
+	category, ok := idx[id]
+	if !ok || category.ParentID == nil {
+		return 0, false
+	}
+	if _, ok := idx[*category.ParentID]; !ok {
+		return 0, false
+	}
+	return *category.ParentID, true
+}
+
+// ancestors возвращает родителей категории от ближайшего до корневой
+func (idx categoryIndex) ancestors(id uint) []uint {
+	var result []uint
+	seen := map[uint]bool{id: true}
+	for {
+		parentID, ok := idx.parent(id)
+		if !ok || seen[parentID] {
+			return result
+		}
+		seen[parentID] = true
+		result = append(result, parentID)
+		id = parentID
+	}
+}
+
+// buildCategoryTree раскладывает категории по родителям; категории внутри уровня идут в порядке создания
+func buildCategoryTree(categories []models.Category) []models.CategoryTreeNode {
+	index := newCategoryIndex(categories)
+	children := make(map[uint][]models.Category)
+	roots := make([]models.Category, 0)
+	for _, category := range categories {
+		if parentID, ok := index.parent(category.ID); ok {
+			children[parentID] = append(children[parentID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+
+	var build func(level []models.Category) []models.CategoryTreeNode
+	build = func(level []models.Category) []models.CategoryTreeNode {
+		sort.Slice(level, func(a, b int) bool { return level[a].ID < level[b].ID })
+		nodes := make([]models.CategoryTreeNode, 0, len(level))
+		for _, category := range level {
+			nodes = append(nodes, models.CategoryTreeNode{
+				Category: category,
+				Children: build(children[category.ID]),
+			})
+		}
+		return nodes
+	}
+	return build(roots)
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
+	"cashcontrol/internal/rates"
+	"cashcontrol/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExchangeRateNotFound = errors.New("курс валюты не найден")
+	ErrMissingExchangeRate  = errors.New("нет курса для пересчета в базовую валюту")
+	ErrInvalidCurrency      = errors.New("код валюты должен состоять из трех латинских букв, например RUB")
+	ErrInvalidRatesSource   = errors.New("некорректный источник курсов")
+)
+
+type CurrencyService interface {
+	BaseCurrency(userID uint) (string, error)
+	ResolveCurrency(userID uint, code string) (string, error)
+	ListRates(filter models.ExchangeRateFilter) ([]models.ExchangeRate, error)
+	CreateRate(userID uint, req models.CreateExchangeRateRequest) (*models.ExchangeRate, error)
+	DeleteRate(userID, id uint) error
+	ImportRates(ctx context.Context, userID *uint, provider rates.Provider) (int, error)
+	Rate(userID uint, from, to string, date time.Time) (*big.Rat, error)
+	ConvertDailyTotals(userID uint, target string, totals []models.DailyTotal) error
+}
+
+type currencyService struct {
+	rates  repository.ExchangeRateRepository
+	users  repository.UserRepository
+	logger *slog.Logger
+}
+
+func NewCurrencyService(rates repository.ExchangeRateRepository, users repository.UserRepository, logger *slog.Logger) CurrencyService {
+	return &currencyService{rates: rates, users: users, logger: logger}
+}
+
+// BaseCurrency возвращает валюту, в которую пересчитываются бюджеты и статистика пользователя
+func (s *currencyService) BaseCurrency(userID uint) (string, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrUserNotFound
+		}
+		s.logger.Error("failed to get user base currency",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return "", err
+	}
+	if user.BaseCurrency == "" {
+		return models.DefaultCurrency, nil
+	}
+	return user.BaseCurrency, nil
+}
+
+// ResolveCurrency нормализует код валюты; пустой код заменяется базовой валютой пользователя
+func (s *currencyService) ResolveCurrency(userID uint, code string) (string, error) {
+	if strings.TrimSpace(code) == "" {
+		return s.BaseCurrency(userID)
+	}
+	return normalizeCurrency(code)
+}
+
+func (s *currencyService) ListRates(filter models.ExchangeRateFilter) ([]models.ExchangeRate, error) {
+	var err error
+	if filter.FromCurrency != "" {
+		if filter.FromCurrency, err = normalizeCurrency(filter.FromCurrency); err != nil {
+			return nil, err
+		}
+	}
+	if filter.ToCurrency != "" {
+		if filter.ToCurrency, err = normalizeCurrency(filter.ToCurrency); err != nil {
+			return nil, err
+		}
+	}
+
+	list, err := s.rates.List(filter)
+	if err != nil {
+		s.logger.Error("failed to list exchange rates",
+			slog.String("op", "list_exchange_rates"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("exchange rates listed",
+		slog.Uint64("user_id", uint64(filter.UserID)),
+		slog.Int("count", len(list)),
+	)
+
+	return list, nil
+}
+
+// CreateRate сохраняет курс пользователя; курс той же пары на ту же дату перезаписывается
+func (s *currencyService) CreateRate(userID uint, req models.CreateExchangeRateRequest) (*models.ExchangeRate, error) {
+	rate, err := buildExchangeRate(&userID, rates.Rate{
+		Date:  req.Date,
+		From:  req.FromCurrency,
+		To:    req.ToCurrency,
+		Value: req.Rate,
+	}, models.RateSourceManual)
+	if err != nil {
+		s.logger.Warn("exchange rate validation failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Any("request", req),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	list := []models.ExchangeRate{*rate}
+	if err := s.rates.Upsert(list); err != nil {
+		s.logger.Error("exchange rate create failed",
+			slog.String("op", "create_exchange_rate"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("exchange rate saved",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("from", rate.FromCurrency),
+		slog.String("to", rate.ToCurrency),
+		slog.String("rate", rate.Rate.String()),
+		slog.Time("date", rate.Date),
+	)
+
+	return &list[0], nil
+}
+
+func (s *currencyService) DeleteRate(userID, id uint) error {
+	rate, err := s.rates.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("exchange rate not found",
+				slog.Uint64("rate_id", uint64(id)),
+			)
+			return ErrExchangeRateNotFound
+		}
+		s.logger.Error("failed to fetch exchange rate",
+			slog.String("op", "delete_exchange_rate"),
+			slog.Uint64("rate_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	// Общие курсы из файла удалить нельзя, они принадлежат всем пользователям
+	if rate.UserID == nil || *rate.UserID != userID {
+		s.logger.Warn("exchange rate belongs to another user",
+			slog.Uint64("rate_id", uint64(id)),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		return ErrForbidden
+	}
+
+	if err := s.rates.Delete(id); err != nil {
+		s.logger.Error("exchange rate delete failed",
+			slog.String("op", "delete_exchange_rate"),
+			slog.Uint64("rate_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("exchange rate deleted",
+		slog.Uint64("rate_id", uint64(id)),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	return nil
+}
+
+// ImportRates загружает курсы из источника. Без userID курсы сохраняются как общие для всех пользователей.
+// Повторы пары и даты внутри источника схлопываются, побеждает последняя строка.
+func (s *currencyService) ImportRates(ctx context.Context, userID *uint, provider rates.Provider) (int, error) {
+	fetched, err := provider.Fetch(ctx)
+	if err != nil {
+		s.logger.Warn("failed to fetch exchange rates",
+			slog.String("provider", provider.Name()),
+			slog.String("error", err.Error()),
+		)
+		return 0, fmt.Errorf("%w: %v", ErrInvalidRatesSource, err)
+	}
+
+	type rateKey struct {
+		from, to string
+		date     time.Time
+	}
+	index := make(map[rateKey]int, len(fetched))
+	list := make([]models.ExchangeRate, 0, len(fetched))
+	for _, r := range fetched {
+		rate, err := buildExchangeRate(userID, r, models.RateSourceFile)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s: %v", ErrInvalidRatesSource, provider.Name(), err)
+		}
+		key := rateKey{from: rate.FromCurrency, to: rate.ToCurrency, date: rate.Date}
+		if i, ok := index[key]; ok {
+			list[i] = *rate
+			continue
+		}
+		index[key] = len(list)
+		list = append(list, *rate)
+	}
+
+	if err := s.rates.Upsert(list); err != nil {
+		s.logger.Error("exchange rates import failed",
+			slog.String("op", "import_exchange_rates"),
+			slog.String("provider", provider.Name()),
+			slog.String("error", err.Error()),
+		)
+		return 0, err
+	}
+
+	s.logger.Info("exchange rates imported",
+		slog.String("provider", provider.Name()),
+		slog.Int("count", len(list)),
+	)
+
+	return len(list), nil
+}
+
+// Rate возвращает курс from→to на дату точной дробью. Если прямого курса нет, используется обратный,
+// который тоже считается точно, без округления до восьми знаков.
+func (s *currencyService) Rate(userID uint, from, to string, date time.Time) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+
+	rate, err := s.rates.FindLatest(userID, from, to, date)
+	if err == nil && rate.Rate > 0 {
+		return rate.Rate.Rat(), nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	inverse, err := s.rates.FindLatest(userID, to, from, date)
+	if err == nil && inverse.Rate > 0 {
+		return inverse.Rate.Inverse(), nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	s.logger.Warn("exchange rate missing",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("from", from),
+		slog.String("to", to),
+		slog.Time("date", date),
+	)
+	return nil, fmt.Errorf("%w: %s → %s на %s", ErrMissingExchangeRate, from, to, date.Format(time.DateOnly))
+}
+
+// ConvertDailyTotals пересчитывает дневные суммы в валюту target по курсу на день расхода
+// с округлением до минимальной единицы target
+func (s *currencyService) ConvertDailyTotals(userID uint, target string, totals []models.DailyTotal) error {
+	type rateKey struct {
+		currency string
+		day      time.Time
+	}
+	cache := make(map[rateKey]*big.Rat)
+
+	for i := range totals {
+		t := &totals[i]
+		if t.Currency == target {
+			continue
+		}
+
+		key := rateKey{currency: t.Currency, day: t.Day}
+		rate, ok := cache[key]
+		if !ok {
+			var err error
+			if rate, err = s.Rate(userID, t.Currency, target, t.Day); err != nil {
+				return err
+			}
+			cache[key] = rate
+		}
+
+		t.TotalAmount = t.TotalAmount.MulRate(rate, target)
+		t.Currency = target
+	}
+	return nil
+}
+
+// buildExchangeRate проверяет курс источника и превращает его в запись таблицы курсов
+func buildExchangeRate(userID *uint, r rates.Rate, source models.ExchangeRateSource) (*models.ExchangeRate, error) {
+	from, err := normalizeCurrency(r.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := normalizeCurrency(r.To)
+	if err != nil {
+		return nil, err
+	}
+	if from == to {
+		return nil, errors.New("исходная и целевая валюты должны различаться")
+	}
+	if r.Value <= 0 {
+		return nil, errors.New("курс должен быть больше нуля")
+	}
+
+	return &models.ExchangeRate{
+		UserID:       userID,
+		FromCurrency: from,
+		ToCurrency:   to,
+		Rate:         r.Value,
+		Date:         occurrenceDateOf(r.Date),
+		Source:       source,
+	}, nil
+}
+
+// roundToCurrency округляет сумму до минимальной единицы валюты и проверяет, что она осталась положительной
+func roundToCurrency(amount money.Amount, currency string) (money.Amount, error) {
+	rounded := amount.Round(currency)
+	if rounded <= 0 {
+		return 0, fmt.Errorf("сумма должна быть не меньше минимальной единицы валюты %s", currency)
+	}
+	return rounded, nil
+}
+
+// normalizeCurrency приводит код валюты ISO 4217 к верхнему регистру и проверяет формат
+func normalizeCurrency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return "", ErrInvalidCurrency
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return "", ErrInvalidCurrency
+		}
+	}
+	return code, nil
+}
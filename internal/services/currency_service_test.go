@@ -0,0 +1,115 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
+	"cashcontrol/internal/repository"
+	"errors"
+	"io"
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// fakeExchangeRateRepo ищет последний курс пары не позже даты среди заданных
+type fakeExchangeRateRepo struct {
+	repository.ExchangeRateRepository
+	rates []models.ExchangeRate
+}
+
+func (r *fakeExchangeRateRepo) FindLatest(userID uint, from, to string, date time.Time) (*models.ExchangeRate, error) {
+	var found *models.ExchangeRate
+	for i := range r.rates {
+		rate := &r.rates[i]
+		if rate.FromCurrency != from || rate.ToCurrency != to || rate.Date.After(date) {
+			continue
+		}
+		if found == nil || rate.Date.After(found.Date) {
+			found = rate
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return found, nil
+}
+
+func testRate(from, to, value string, date time.Time) models.ExchangeRate {
+	rate, err := money.ParseRate(value)
+	if err != nil {
+		panic(err)
+	}
+	return models.ExchangeRate{FromCurrency: from, ToCurrency: to, Rate: rate, Date: date}
+}
+
+func newTestCurrencyService(rates ...models.ExchangeRate) *currencyService {
+	return &currencyService{
+		rates:  &fakeExchangeRateRepo{rates: rates},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+func TestCurrencyServiceRate(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	svc := newTestCurrencyService(
+		testRate("EUR", "RUB", "98.5", jan),
+		testRate("EUR", "RUB", "99.25", feb),
+		testRate("USD", "KZT", "3", jan),
+	)
+
+	tests := []struct {
+		name     string
+		from, to string
+		date     time.Time
+		want     *big.Rat
+		wantErr  error
+	}{
+		{name: "та же валюта", from: "RUB", to: "RUB", date: jan, want: big.NewRat(1, 1)},
+		{name: "прямой курс на дату", from: "EUR", to: "RUB", date: jan.AddDate(0, 0, 20), want: big.NewRat(197, 2)},
+		{name: "последний курс не позже даты", from: "EUR", to: "RUB", date: feb, want: big.NewRat(397, 4)},
+		{name: "обратный курс точной дробью", from: "KZT", to: "USD", date: jan, want: big.NewRat(1, 3)},
+		{name: "курс до начала действия", from: "EUR", to: "RUB", date: jan.AddDate(0, 0, -1), wantErr: ErrMissingExchangeRate},
+		{name: "нет курса", from: "GBP", to: "RUB", date: jan, wantErr: ErrMissingExchangeRate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.Rate(1, tt.from, tt.to, tt.date)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Rate: %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && got.Cmp(tt.want) != 0 {
+				t.Errorf("Rate = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertDailyTotals(t *testing.T) {
+	day := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	svc := newTestCurrencyService(
+		testRate("EUR", "RUB", "98.5", day),
+		testRate("RUB", "USD", "0.03", day),
+	)
+
+	totals := []models.DailyTotal{
+		{Day: day, Currency: "RUB", TotalAmount: money.FromFloat(150)},
+		{Day: day, Currency: "EUR", TotalAmount: money.FromFloat(10.01)},
+		// Пересчет USD→RUB идет по обратному курсу 1/0.03 без округления самого курса
+		{Day: day, Currency: "USD", TotalAmount: money.FromFloat(1)},
+	}
+	if err := svc.ConvertDailyTotals(1, "RUB", totals); err != nil {
+		t.Fatalf("ConvertDailyTotals: %v", err)
+	}
+
+	want := []money.Amount{money.FromFloat(150), money.FromFloat(985.99), money.FromFloat(33.33)}
+	for i, total := range totals {
+		if total.Currency != "RUB" || total.TotalAmount != want[i] {
+			t.Errorf("totals[%d] = %s %s, want %s RUB", i, total.TotalAmount, total.Currency, want[i])
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var ErrInvalidDefaultCategories = errors.New("некорректный набор категорий по умолчанию")
+
+// DefaultCategories наборы категорий по языкам, которые создаются новому пользователю при регистрации
+type DefaultCategories struct {
+	locale string
+	sets   map[string][]models.DefaultCategory
+}
+
+// LoadDefaultCategories читает наборы категорий из JSON файла вида {"ru": [...], "en": [...]};
+// пустой path означает встроенные наборы. Набор для языка locale обязателен.
+func LoadDefaultCategories(path, locale string) (*DefaultCategories, error) {
+	sets := builtinDefaultCategories
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("чтение набора категорий: %w", err)
+		}
+		sets = nil
+		if err := json.Unmarshal(data, &sets); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidDefaultCategories, path, err)
+		}
+	}
+
+	normalized := make(map[string][]models.DefaultCategory, len(sets))
+	for l, set := range sets {
+		if err := validateDefaultCategories(set, ""); err != nil {
+			return nil, fmt.Errorf("%w: язык %s: %v", ErrInvalidDefaultCategories, l, err)
+		}
+		normalized[normalizeLocale(l)] = set
+	}
+
+	locale = normalizeLocale(locale)
+	if _, ok := normalized[locale]; !ok {
+		return nil, fmt.Errorf("%w: нет набора для языка по умолчанию %s", ErrInvalidDefaultCategories, locale)
+	}
+
+	return &DefaultCategories{locale: locale, sets: normalized}, nil
+}
+
+// ForLocale возвращает набор для языка пользователя: для en-US подходит набор en,
+// для неизвестного языка возвращается набор языка по умолчанию
+func (d *DefaultCategories) ForLocale(locale string) []models.DefaultCategory {
+	if d == nil {
+		return nil
+	}
+	locale = normalizeLocale(locale)
+	if set, ok := d.sets[locale]; ok {
+		return set
+	}
+	if i := strings.IndexAny(locale, "-_"); i > 0 {
+		if set, ok := d.sets[locale[:i]]; ok {
+			return set
+		}
+	}
+	return d.sets[d.locale]
+}
+
+// validateDefaultCategories проверяет названия и типы категорий набора; подкатегория без типа
+// получает тип родителя, корневая — expense
+func validateDefaultCategories(set []models.DefaultCategory, parentType models.CategoryType) error {
+	for i := range set {
+		category := &set[i]
+		if strings.TrimSpace(category.Name) == "" {
+			return errors.New("название категории не может быть пустым")
+		}
+		if category.Type == "" {
+			category.Type = parentType
+			if category.Type == "" {
+				category.Type = models.CategoryTypeExpense
+			}
+		}
+		if !isValidCategoryType(category.Type) {
+			return fmt.Errorf("категория %q: %w", category.Name, ErrInvalidCategoryType)
+		}
+		if parentType != "" && category.Type != parentType {
+			return fmt.Errorf("категория %q: %w", category.Name, ErrParentCategoryTypeMismatch)
+		}
+		if err := validateDefaultCategories(category.Children, category.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.TrimSpace(locale))
+}
+
+// builtinDefaultCategories встроенные наборы категорий на случай, когда файл с наборами не задан
+var builtinDefaultCategories = map[string][]models.DefaultCategory{
+	"ru": {
+		{Name: "Продукты", Type: models.CategoryTypeExpense, Color: "#22C55E", Icon: "shopping-cart"},
+		{Name: "Кафе и рестораны", Type: models.CategoryTypeExpense, Color: "#F97316", Icon: "utensils"},
+		{Name: "Транспорт", Type: models.CategoryTypeExpense, Color: "#3B82F6", Icon: "bus", Children: []models.DefaultCategory{
+			{Name: "Общественный транспорт", Type: models.CategoryTypeExpense, Color: "#60A5FA", Icon: "train"},
+			{Name: "Такси", Type: models.CategoryTypeExpense, Color: "#FACC15", Icon: "car"},
+			{Name: "Топливо", Type: models.CategoryTypeExpense, Color: "#1D4ED8", Icon: "fuel"},
+		}},
+		{Name: "Жилье и коммунальные услуги", Type: models.CategoryTypeExpense, Color: "#8B5CF6", Icon: "home"},
+		{Name: "Связь и интернет", Type: models.CategoryTypeExpense, Color: "#06B6D4", Icon: "wifi"},
+		{Name: "Здоровье", Type: models.CategoryTypeExpense, Color: "#EF4444", Icon: "heart"},
+		{Name: "Одежда и обувь", Type: models.CategoryTypeExpense, Color: "#14B8A6", Icon: "shirt"},
+		{Name: "Развлечения", Type: models.CategoryTypeExpense, Color: "#EC4899", Icon: "film"},
+		{Name: "Прочие расходы", Type: models.CategoryTypeExpense, Color: "#6B7280", Icon: "tag"},
+		{Name: "Зарплата", Type: models.CategoryTypeIncome, Color: "#16A34A", Icon: "briefcase"},
+		{Name: "Подарки", Type: models.CategoryTypeIncome, Color: "#F59E0B", Icon: "gift"},
+		{Name: "Прочие доходы", Type: models.CategoryTypeIncome, Color: "#6B7280", Icon: "plus"},
+	},
+	"en": {
+		{Name: "Groceries", Type: models.CategoryTypeExpense, Color: "#22C55E", Icon: "shopping-cart"},
+		{Name: "Restaurants", Type: models.CategoryTypeExpense, Color: "#F97316", Icon: "utensils"},
+		{Name: "Transport", Type: models.CategoryTypeExpense, Color: "#3B82F6", Icon: "bus", Children: []models.DefaultCategory{
+			{Name: "Public transport", Type: models.CategoryTypeExpense, Color: "#60A5FA", Icon: "train"},
+			{Name: "Taxi", Type: models.CategoryTypeExpense, Color: "#FACC15", Icon: "car"},
+			{Name: "Fuel", Type: models.CategoryTypeExpense, Color: "#1D4ED8", Icon: "fuel"},
+		}},
+		{Name: "Housing and utilities", Type: models.CategoryTypeExpense, Color: "#8B5CF6", Icon: "home"},
+		{Name: "Phone and internet", Type: models.CategoryTypeExpense, Color: "#06B6D4", Icon: "wifi"},
+		{Name: "Health", Type: models.CategoryTypeExpense, Color: "#EF4444", Icon: "heart"},
+		{Name: "Clothing", Type: models.CategoryTypeExpense, Color: "#14B8A6", Icon: "shirt"},
+		{Name: "Entertainment", Type: models.CategoryTypeExpense, Color: "#EC4899", Icon: "film"},
+		{Name: "Other expenses", Type: models.CategoryTypeExpense, Color: "#6B7280", Icon: "tag"},
+		{Name: "Salary", Type: models.CategoryTypeIncome, Color: "#16A34A", Icon: "briefcase"},
+		{Name: "Gifts", Type: models.CategoryTypeIncome, Color: "#F59E0B", Icon: "gift"},
+		{Name: "Other income", Type: models.CategoryTypeIncome, Color: "#6B7280", Icon: "plus"},
+	},
+}
@@ -0,0 +1,6 @@
+package services
+
+import "errors"
+
+// ErrForbidden возвращается, когда сущность существует, но принадлежит другому пользователю
+var ErrForbidden = errors.New("доступ запрещен")
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"cashcontrol/internal/importer"
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
+	"cashcontrol/internal/repository"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidImportFile       = errors.New("некорректный файл выписки")
+	ErrImportHasInvalidRows    = errors.New("выписка содержит некорректные строки, исправьте их по результатам предпросмотра")
+	ErrInvalidDecimalSeparator = errors.New(`десятичный разделитель должен быть "." или ","`)
+	ErrTooManyImportRows       = fmt.Errorf("выписка содержит больше %d операций, разделите ее на части", maxImportRows)
+)
+
+// maxImportRows ограничивает число операций в одной выписке
+const maxImportRows = 5000
+
+type ExpenseImportService interface {
+	PreviewImport(userID uint, req models.ExpenseImportRequest, file io.Reader) (*models.ExpenseImportPreview, error)
+	ImportExpenses(userID uint, req models.ExpenseImportRequest, file io.Reader) (*models.ExpenseImportResult, error)
+}
+
+type expenseImportService struct {
+	expenses     repository.ExpenseRepository
+	categories   repository.CategoryRepository
+	accounts     repository.AccountRepository
+	currencies   CurrencyService
+	rules        CategorizationService
+	activityLogs ActivityLogService
+	alerts       BudgetAlertService
+	logger       *slog.Logger
+}
+
+func NewExpenseImportService(
+	expenses repository.ExpenseRepository,
+	categories repository.CategoryRepository,
+	accounts repository.AccountRepository,
+	currencies CurrencyService,
+	rules CategorizationService,
+	activityLogs ActivityLogService,
+	alerts BudgetAlertService,
+	logger *slog.Logger,
+) ExpenseImportService {
+	return &expenseImportService{
+		expenses:     expenses,
+		categories:   categories,
+		accounts:     accounts,
+		currencies:   currencies,
+		rules:        rules,
+		activityLogs: activityLogs,
+		alerts:       alerts,
+		logger:       logger,
+	}
+}
+
+// PreviewImport разбирает выписку и помечает каждую строку как новую, дубликат, пропущенную или некорректную, ничего не сохраняя
+func (s *expenseImportService) PreviewImport(userID uint, req models.ExpenseImportRequest, file io.Reader) (*models.ExpenseImportPreview, error) {
+	preview, _, err := s.analyze(userID, req, file)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("expense import previewed",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("format", preview.Format),
+		slog.Int("new", preview.New),
+		slog.Int("duplicates", preview.Duplicates),
+		slog.Int("skipped", preview.Skipped),
+		slog.Int("invalid", preview.Invalid),
+	)
+
+	return preview, nil
+}
+
+// ImportExpenses разбирает выписку и сохраняет новые расходы одной транзакцией.
+// Выписка с некорректными строками отклоняется целиком, чтобы импорт не оказался частичным.
+func (s *expenseImportService) ImportExpenses(userID uint, req models.ExpenseImportRequest, file io.Reader) (*models.ExpenseImportResult, error) {
+	preview, expenses, err := s.analyze(userID, req, file)
+	if err != nil {
+		return nil, err
+	}
+	if preview.Invalid > 0 {
+		s.logger.Warn("expense import rejected",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Int("invalid", preview.Invalid),
+		)
+		return nil, ErrImportHasInvalidRows
+	}
+
+	result := &models.ExpenseImportResult{
+		Imported: len(expenses),
+		Skipped:  preview.Skipped,
+	}
+	if req.SkipDuplicates {
+		result.Duplicates = preview.Duplicates
+	}
+	if len(expenses) == 0 {
+		return result, nil
+	}
+
+	if err := s.expenses.CreateBatch(expenses); err != nil {
+		s.logger.Error("expense import failed",
+			slog.String("op", "import_expenses"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Int("count", len(expenses)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	s.logger.Info("expenses imported",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("format", preview.Format),
+		slog.Int("imported", result.Imported),
+		slog.Int("duplicates", result.Duplicates),
+		slog.Int("skipped", result.Skipped),
+	)
+
+	ids := make([]uint, len(expenses))
+	for i := range expenses {
+		ids[i] = expenses[i].ID
+	}
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeExpensesImported,
+		EntityType:   models.EntityTypeExpense,
+		EntityID:     ids[0],
+		Description:  fmt.Sprintf("импортировано расходов: %d", len(expenses)),
+		Metadata: map[string]interface{}{
+			"format":      preview.Format,
+			"expense_ids": ids,
+			"duplicates":  result.Duplicates,
+			"skipped":     result.Skipped,
+		},
+	})
+
+	// Пороги бюджета проверяются один раз на каждую категорию и месяц выписки, а не на каждую строку
+	lastInMonth := make(map[string]int)
+	for i := range expenses {
+		lastInMonth[fmt.Sprintf("%d/%s", expenses[i].CategoryID, expenses[i].Date.Format("2006-01"))] = i
+	}
+	for _, i := range lastInMonth {
+		checkBudgetAlerts(s.alerts, &expenses[i])
+	}
+
+	return result, nil
+}
+
+// analyze разбирает выписку, проверяет строки и возвращает предпросмотр вместе с расходами, готовыми к сохранению
+func (s *expenseImportService) analyze(userID uint, req models.ExpenseImportRequest, file io.Reader) (*models.ExpenseImportPreview, []models.Expense, error) {
+	if err := s.validateImportRequest(userID, req); err != nil {
+		s.logger.Warn("expense import validation failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("format", req.Format),
+			slog.String("reason", err.Error()),
+		)
+		return nil, nil, err
+	}
+
+	rows, err := importer.Parse(importer.Format(req.Format), file, importer.Options{
+		DateFormat:       req.DateFormat,
+		DecimalSeparator: req.DecimalSeparator,
+		CSV: importer.CSVOptions{
+			Delimiter:         req.Delimiter,
+			HasHeader:         req.HasHeader,
+			DateColumn:        req.DateColumn,
+			AmountColumn:      req.AmountColumn,
+			DescriptionColumn: req.DescriptionColumn,
+			CurrencyColumn:    req.CurrencyColumn,
+		},
+	})
+	if err != nil {
+		s.logger.Warn("statement parse failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("format", req.Format),
+			slog.String("reason", err.Error()),
+		)
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidImportFile, err.Error())
+	}
+	if len(rows) > maxImportRows {
+		return nil, nil, ErrTooManyImportRows
+	}
+
+	// Валюта из запроса важнее валюты в файле; без обеих используется валюта счета или базовая валюта
+	code := req.Currency
+	for i := 0; code == "" && i < len(rows); i++ {
+		code = rows[i].Currency
+	}
+	currency, err := operationCurrency(s.accounts, s.currencies, s.logger, userID, req.AccountID, code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	preview := &models.ExpenseImportPreview{
+		Format:   req.Format,
+		Currency: currency,
+		Rows:     make([]models.ExpenseImportRow, len(rows)),
+	}
+	matcher, err := s.rules.Matcher(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	sign := importAmountSign(req)
+	for i, row := range rows {
+		preview.Rows[i] = importRow(row, currency, sign)
+		categorizeImportRow(&preview.Rows[i], matcher, req)
+	}
+
+	if err := s.markDuplicates(userID, preview.Rows); err != nil {
+		return nil, nil, err
+	}
+
+	var expenses []models.Expense
+	for _, row := range preview.Rows {
+		switch row.Status {
+		case models.ImportRowStatusNew:
+			preview.New++
+		case models.ImportRowStatusDuplicate:
+			preview.Duplicates++
+			if req.SkipDuplicates {
+				continue
+			}
+		case models.ImportRowStatusSkipped:
+			preview.Skipped++
+			continue
+		case models.ImportRowStatusInvalid:
+			preview.Invalid++
+			continue
+		}
+
+		expenses = append(expenses, models.Expense{
+			UserID:      userID,
+			CategoryID:  row.CategoryID,
+			AccountID:   req.AccountID,
+			Amount:      row.Amount,
+			Currency:    row.Currency,
+			Description: row.Description,
+			Date:        *row.Date,
+		})
+	}
+
+	return preview, expenses, nil
+}
+
+// markDuplicates помечает дубликатами строки, для которых уже есть расход с той же датой, суммой, валютой и описанием.
+// Каждый существующий расход покрывает одну строку, поэтому одинаковые покупки за день повторно не теряются.
+func (s *expenseImportService) markDuplicates(userID uint, rows []models.ExpenseImportRow) error {
+	var start, end time.Time
+	for _, row := range rows {
+		if row.Status != models.ImportRowStatusNew {
+			continue
+		}
+		if start.IsZero() || row.Date.Before(start) {
+			start = *row.Date
+		}
+		if row.Date.After(end) {
+			end = *row.Date
+		}
+	}
+	if start.IsZero() {
+		return nil
+	}
+
+	end = end.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	existing, err := s.expenses.List(models.ExpenseFilter{
+		UserID:    userID,
+		StartDate: &start,
+		EndDate:   &end,
+	})
+	if err != nil {
+		s.logger.Error("failed to load expenses for duplicate check",
+			slog.String("op", "import_expenses"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	remaining := make(map[string]int, len(existing))
+	for _, expense := range existing {
+		remaining[duplicateKey(expense.Date, expense.Amount, expense.Currency, expense.Description)]++
+	}
+	for i := range rows {
+		if rows[i].Status != models.ImportRowStatusNew {
+			continue
+		}
+		key := duplicateKey(*rows[i].Date, rows[i].Amount, rows[i].Currency, rows[i].Description)
+		if remaining[key] > 0 {
+			remaining[key]--
+			rows[i].Status = models.ImportRowStatusDuplicate
+		}
+	}
+	return nil
+}
+
+func (s *expenseImportService) validateImportRequest(userID uint, req models.ExpenseImportRequest) error {
+	switch importer.Format(req.Format) {
+	case importer.FormatCSV, importer.FormatOFX, importer.FormatQIF:
+	default:
+		return importer.ErrUnsupportedFormat
+	}
+
+	switch req.DecimalSeparator {
+	case "", ".", ",":
+	default:
+		return ErrInvalidDecimalSeparator
+	}
+
+	if req.CategoryID != 0 {
+		_, err := categoryReference(s.categories, s.logger, userID, req.CategoryID, models.CategoryTypeExpense)
+		return err
+	}
+	return nil
+}
+
+// importAmountSign возвращает знак расходов: из запроса или по умолчанию для формата
+func importAmountSign(req models.ExpenseImportRequest) models.ImportAmountSign {
+	if req.AmountSign != "" {
+		return req.AmountSign
+	}
+	// В OFX и QIF списания всегда отрицательные, в выгрузках CSV расходы чаще положительные
+	if importer.Format(req.Format) == importer.FormatCSV {
+		return models.ImportAmountSignPositive
+	}
+	return models.ImportAmountSignNegative
+}
+
+// importRow переводит строку выписки в строку предпросмотра: сумма становится положительной суммой расхода
+func importRow(row importer.Row, currency string, sign models.ImportAmountSign) models.ExpenseImportRow {
+	result := models.ExpenseImportRow{
+		Line:        row.Line,
+		Currency:    currency,
+		Description: strings.TrimSpace(row.Description),
+		Status:      models.ImportRowStatusNew,
+	}
+	invalid := func(err error) models.ExpenseImportRow {
+		result.Status = models.ImportRowStatusInvalid
+		result.Error = err.Error()
+		return result
+	}
+
+	if row.Err != nil {
+		return invalid(row.Err)
+	}
+	result.Date = ptrTime(row.Date)
+
+	if row.Currency != "" && row.Currency != currency {
+		return invalid(fmt.Errorf("валюта операции %s не совпадает с валютой импорта %s", row.Currency, currency))
+	}
+
+	amount := row.Amount
+	if sign == models.ImportAmountSignNegative {
+		amount = -amount
+	}
+	if amount <= 0 {
+		result.Amount = row.Amount
+		result.Status = models.ImportRowStatusSkipped
+		return result
+	}
+
+	rounded, err := roundToCurrency(amount, currency)
+	if err != nil {
+		return invalid(err)
+	}
+	result.Amount = rounded
+
+	return result
+}
+
+// categorizeImportRow назначает строке категорию по правилам, а если ни одно не подошло — категорию из запроса.
+// Строка, для которой категорию подобрать не удалось, становится некорректной.
+func categorizeImportRow(row *models.ExpenseImportRow, matcher *CategorizationMatcher, req models.ExpenseImportRequest) {
+	if row.Status != models.ImportRowStatusNew {
+		return
+	}
+	if rule := matcher.Match(row.Description, row.Amount, req.AccountID); rule != nil {
+		row.CategoryID = rule.CategoryID
+		row.RuleID = &rule.ID
+		return
+	}
+	if req.CategoryID != 0 {
+		row.CategoryID = req.CategoryID
+		return
+	}
+	row.Status = models.ImportRowStatusInvalid
+	row.Error = ErrNoMatchingRule.Error()
+}
+
+// duplicateKey ключ сравнения расходов: день в UTC, сумма, валюта и описание без учета регистра и лишних пробелов
+func duplicateKey(date time.Time, amount money.Amount, currency, description string) string {
+	return strings.Join([]string{
+		date.UTC().Format("2006-01-02"),
+		amount.String(),
+		currency,
+		strings.ToLower(strings.Join(strings.Fields(description), " ")),
+	}, "|")
+}
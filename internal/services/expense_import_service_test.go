@@ -0,0 +1,354 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
+	"cashcontrol/internal/repository"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeImportExpenseRepo отдает существующие расходы для поиска дубликатов и сохраняет пакет целиком или не сохраняет ничего
+type fakeImportExpenseRepo struct {
+	repository.ExpenseRepository
+	existing []models.Expense
+	batchErr error
+	created  []models.Expense
+	batches  int
+}
+
+func (r *fakeImportExpenseRepo) List(filter models.ExpenseFilter) ([]models.Expense, error) {
+	var result []models.Expense
+	for _, expense := range r.existing {
+		if expense.Date.Before(*filter.StartDate) || expense.Date.After(*filter.EndDate) {
+			continue
+		}
+		result = append(result, expense)
+	}
+	return result, nil
+}
+
+func (r *fakeImportExpenseRepo) CreateBatch(expenses []models.Expense) error {
+	r.batches++
+	if r.batchErr != nil {
+		return r.batchErr
+	}
+	for i := range expenses {
+		expenses[i].ID = uint(len(r.created) + 1)
+		r.created = append(r.created, expenses[i])
+	}
+	return nil
+}
+
+// fakeActivityLogService запоминает записи истории
+type fakeActivityLogService struct {
+	ActivityLogService
+	logged []models.CreateActivityLogRequest
+}
+
+func (s *fakeActivityLogService) CreateActivityLog(req models.CreateActivityLogRequest) (*models.ActivityHistory, error) {
+	s.logged = append(s.logged, req)
+	return &models.ActivityHistory{}, nil
+}
+
+// fakeBudgetAlertService запоминает расходы, по которым проверялись пороги бюджета
+type fakeBudgetAlertService struct {
+	BudgetAlertService
+	checked []models.Expense
+}
+
+func (s *fakeBudgetAlertService) CheckExpense(expense *models.Expense) {
+	s.checked = append(s.checked, *expense)
+}
+
+// fakeCategorizationService отдает заранее заданные правила категоризации
+type fakeCategorizationService struct {
+	CategorizationService
+	rules []models.CategorizationRule
+}
+
+func (s *fakeCategorizationService) Matcher(userID uint) (*CategorizationMatcher, error) {
+	matcher := &CategorizationMatcher{}
+	for _, rule := range s.rules {
+		matcher.rules = append(matcher.rules, compiledRule{rule: rule, contains: strings.ToLower(rule.DescriptionContains)})
+	}
+	return matcher, nil
+}
+
+type importTestEnv struct {
+	service      *expenseImportService
+	expenses     *fakeImportExpenseRepo
+	rules        *fakeCategorizationService
+	activityLogs *fakeActivityLogService
+	alerts       *fakeBudgetAlertService
+}
+
+func newImportTestEnv(existing ...models.Expense) *importTestEnv {
+	currencies := newTestCurrencyService()
+	currencies.users = &fakeUserRepo{}
+
+	groceries := models.Category{UserID: 1, Name: "Продукты", Type: models.CategoryTypeExpense}
+	groceries.ID = 7
+
+	env := &importTestEnv{
+		expenses:     &fakeImportExpenseRepo{existing: existing},
+		rules:        &fakeCategorizationService{},
+		activityLogs: &fakeActivityLogService{},
+		alerts:       &fakeBudgetAlertService{},
+	}
+	env.service = &expenseImportService{
+		expenses:     env.expenses,
+		categories:   &fakeCategoryRepo{categories: []models.Category{groceries}},
+		currencies:   currencies,
+		rules:        env.rules,
+		activityLogs: env.activityLogs,
+		alerts:       env.alerts,
+		logger:       currencies.logger,
+	}
+	return env
+}
+
+func testImportRequest() models.ExpenseImportRequest {
+	return models.ExpenseImportRequest{
+		Format:         "csv",
+		CategoryID:     7,
+		HasHeader:      true,
+		DateColumn:     "date",
+		AmountColumn:   "amount",
+		SkipDuplicates: true,
+	}
+}
+
+func existingExpense(day int, amount float64, description string) models.Expense {
+	return models.Expense{
+		UserID:      1,
+		CategoryID:  7,
+		Amount:      money.FromFloat(amount),
+		Currency:    testCurrency,
+		Description: description,
+		Date:        time.Date(2024, 3, day, 14, 30, 0, 0, time.UTC),
+	}
+}
+
+const importStatement = "date,amount,description\n" +
+	"2024-03-05,150,Кофе\n" +
+	"2024-03-05,150,  КОФЕ \n" +
+	"2024-03-06,-500,Возврат\n" +
+	"2024-03-07,1200,Продукты\n"
+
+func TestPreviewImportDuplicates(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []models.Expense
+		want     []models.ImportRowStatus
+	}{
+		{
+			name: "нет совпадений",
+			want: []models.ImportRowStatus{
+				models.ImportRowStatusNew, models.ImportRowStatusNew, models.ImportRowStatusSkipped, models.ImportRowStatusNew,
+			},
+		},
+		{
+			name:     "один расход покрывает одну одинаковую строку",
+			existing: []models.Expense{existingExpense(5, 150, "кофе")},
+			want: []models.ImportRowStatus{
+				models.ImportRowStatusDuplicate, models.ImportRowStatusNew, models.ImportRowStatusSkipped, models.ImportRowStatusNew,
+			},
+		},
+		{
+			name:     "два расхода покрывают обе строки",
+			existing: []models.Expense{existingExpense(5, 150, "Кофе"), existingExpense(5, 150, "Кофе")},
+			want: []models.ImportRowStatus{
+				models.ImportRowStatusDuplicate, models.ImportRowStatusDuplicate, models.ImportRowStatusSkipped, models.ImportRowStatusNew,
+			},
+		},
+		{
+			name: "другая сумма, день или описание не дубликат",
+			existing: []models.Expense{
+				existingExpense(5, 151, "Кофе"),
+				existingExpense(6, 150, "Кофе"),
+				existingExpense(7, 1200, "Рынок"),
+			},
+			want: []models.ImportRowStatus{
+				models.ImportRowStatusNew, models.ImportRowStatusNew, models.ImportRowStatusSkipped, models.ImportRowStatusNew,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newImportTestEnv(tt.existing...)
+
+			preview, err := env.service.PreviewImport(1, testImportRequest(), strings.NewReader(importStatement))
+			if err != nil {
+				t.Fatalf("PreviewImport: %v", err)
+			}
+			if len(preview.Rows) != len(tt.want) {
+				t.Fatalf("строк: %d, want %d", len(preview.Rows), len(tt.want))
+			}
+			for i, row := range preview.Rows {
+				if row.Status != tt.want[i] {
+					t.Errorf("строка %d: статус %s, want %s", row.Line, row.Status, tt.want[i])
+				}
+			}
+			if preview.Currency != testCurrency || preview.Skipped != 1 {
+				t.Errorf("preview = %+v", preview)
+			}
+			if env.expenses.batches != 0 {
+				t.Error("предпросмотр сохранил расходы")
+			}
+		})
+	}
+}
+
+func TestImportExpenses(t *testing.T) {
+	tests := []struct {
+		name           string
+		skipDuplicates bool
+		wantImported   int
+		wantDuplicates int
+	}{
+		{name: "дубликаты пропускаются", skipDuplicates: true, wantImported: 2, wantDuplicates: 1},
+		{name: "дубликаты импортируются", skipDuplicates: false, wantImported: 3, wantDuplicates: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newImportTestEnv(existingExpense(5, 150, "Кофе"))
+			req := testImportRequest()
+			req.SkipDuplicates = tt.skipDuplicates
+
+			result, err := env.service.ImportExpenses(1, req, strings.NewReader(importStatement))
+			if err != nil {
+				t.Fatalf("ImportExpenses: %v", err)
+			}
+			if result.Imported != tt.wantImported || result.Duplicates != tt.wantDuplicates || result.Skipped != 1 {
+				t.Errorf("result = %+v", result)
+			}
+			if env.expenses.batches != 1 || len(env.expenses.created) != tt.wantImported {
+				t.Errorf("сохранено %d расходов за %d пакетов", len(env.expenses.created), env.expenses.batches)
+			}
+			for _, expense := range env.expenses.created {
+				if expense.UserID != 1 || expense.CategoryID != 7 || expense.Currency != testCurrency {
+					t.Errorf("expense = %+v", expense)
+				}
+			}
+			if len(env.activityLogs.logged) != 1 {
+				t.Errorf("записей истории: %d, want 1", len(env.activityLogs.logged))
+			}
+			// Все расходы выписки в одном месяце, пороги проверяются один раз
+			if len(env.alerts.checked) != 1 {
+				t.Errorf("проверок бюджета: %d, want 1", len(env.alerts.checked))
+			}
+		})
+	}
+}
+
+func TestImportExpensesRejected(t *testing.T) {
+	errBatch := errors.New("batch failed")
+
+	tests := []struct {
+		name        string
+		req         func() models.ExpenseImportRequest
+		statement   string
+		batchErr    error
+		wantErr     error
+		wantBatches int
+	}{
+		{
+			name:        "ошибка сохранения пакета",
+			req:         testImportRequest,
+			statement:   importStatement,
+			batchErr:    errBatch,
+			wantErr:     errBatch,
+			wantBatches: 1,
+		},
+		{
+			name:      "некорректная строка отклоняет всю выписку",
+			req:       testImportRequest,
+			statement: importStatement + "2024-03-08,сто,Обед\n",
+			wantErr:   ErrImportHasInvalidRows,
+		},
+		{
+			name: "явно заданной колонки нет в заголовке",
+			req: func() models.ExpenseImportRequest {
+				req := testImportRequest()
+				req.CurrencyColumn = "ccy"
+				return req
+			},
+			statement: importStatement,
+			wantErr:   ErrInvalidImportFile,
+		},
+		{
+			name: "без категории и подходящего правила",
+			req: func() models.ExpenseImportRequest {
+				req := testImportRequest()
+				req.CategoryID = 0
+				return req
+			},
+			statement: importStatement,
+			wantErr:   ErrImportHasInvalidRows,
+		},
+		{
+			name: "чужая категория",
+			req: func() models.ExpenseImportRequest {
+				req := testImportRequest()
+				req.CategoryID = 8
+				return req
+			},
+			statement: importStatement,
+			wantErr:   ErrCategoryNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newImportTestEnv()
+			env.expenses.batchErr = tt.batchErr
+
+			result, err := env.service.ImportExpenses(1, tt.req(), strings.NewReader(tt.statement))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ImportExpenses: %v, want %v", err, tt.wantErr)
+			}
+			if result != nil {
+				t.Errorf("result = %+v, want nil", result)
+			}
+			// Выписка сохраняется одним пакетом: при ошибке не остается ни расходов, ни истории, ни проверок бюджета
+			if env.expenses.batches != tt.wantBatches || len(env.expenses.created) != 0 {
+				t.Errorf("сохранено %d расходов за %d пакетов", len(env.expenses.created), env.expenses.batches)
+			}
+			if len(env.activityLogs.logged) != 0 || len(env.alerts.checked) != 0 {
+				t.Errorf("история: %d, проверки бюджета: %d, want 0", len(env.activityLogs.logged), len(env.alerts.checked))
+			}
+		})
+	}
+}
+
+func TestImportExpensesCategorizesByRules(t *testing.T) {
+	env := newImportTestEnv()
+	cafe := models.CategorizationRule{CategoryID: 9, DescriptionContains: "кофе"}
+	cafe.ID = 3
+	env.rules.rules = []models.CategorizationRule{cafe}
+
+	result, err := env.service.ImportExpenses(1, testImportRequest(), strings.NewReader(importStatement))
+	if err != nil {
+		t.Fatalf("ImportExpenses: %v", err)
+	}
+	if result.Imported != 3 {
+		t.Fatalf("result = %+v", result)
+	}
+
+	// Правило важнее категории из запроса; она достается строкам, к которым правило не подошло
+	wantCategories := []uint{9, 9, 7}
+	for i, expense := range env.expenses.created {
+		if expense.CategoryID != wantCategories[i] {
+			t.Errorf("расход %q: категория %d, want %d", expense.Description, expense.CategoryID, wantCategories[i])
+		}
+	}
+	// Пороги проверяются один раз на категорию и месяц
+	if len(env.alerts.checked) != 2 {
+		t.Errorf("проверок бюджета: %d, want 2", len(env.alerts.checked))
+	}
+}
@@ -2,56 +2,121 @@ package services
 
 import (
 	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
 	"cashcontrol/internal/repository"
 	"errors"
+	"fmt"
 	"log/slog"
+	"sort"
+	"time"
 
 	"gorm.io/gorm"
 )
 
-var ErrExpenseNotFound = errors.New("расход не найден")
+var (
+	ErrExpenseNotFound      = errors.New("расход не найден")
+	ErrInvalidGroupPeriod   = errors.New("группировка возможна только по day, week или month")
+	ErrTooManyExpenseGroups = errors.New("слишком большой диапазон для группировки, сузьте период")
+	ErrTooManyGroupExpenses = fmt.Errorf("include_expenses доступен для диапазона не больше %d расходов, сузьте период", maxGroupExpenses)
+)
+
+const (
+	// maxExpenseGroups ограничивает число групп при заполнении пустых периодов
+	maxExpenseGroups = 1000
+	// maxGroupExpenses ограничивает число расходов, встраиваемых в группы при include_expenses
+	maxGroupExpenses = 200
+)
 
 type ExpenseService interface {
 	CreateExpense(userID uint, req models.CreateExpenseRequest) (*models.Expense, error)
-	GetExpenseList(filter models.ExpenseFilter) ([]models.Expense, error)
-	GetExpenseByID(id uint) (*models.Expense, error)
-	UpdateExpense(id uint, req models.UpdateExpenseRequest) (*models.Expense, error)
-	DeleteExpense(id uint) error
+	GetExpenseList(filter models.ExpenseFilter, page models.PageRequest) (*models.Page[models.Expense], error)
+	GetExpenseByID(userID, id uint) (*models.Expense, error)
+	UpdateExpense(userID, id uint, req models.UpdateExpenseRequest) (*models.Expense, error)
+	DeleteExpense(userID, id uint) error
+	GetGroupedExpenses(filter models.ExpenseGroupFilter) ([]models.ExpenseGroup, error)
+	ExportExpenses(filter models.ExpenseFilter, fn func(models.ExpenseExportRow) error) error
 }
 
 type expenseService struct {
-	expenses   repository.ExpenseRepository
-	categories repository.CategoryRepository
-	logger     *slog.Logger
+	expenses     repository.ExpenseRepository
+	categories   repository.CategoryRepository
+	accounts     repository.AccountRepository
+	tags         repository.TagRepository
+	currencies   CurrencyService
+	rules        CategorizationService
+	activityLogs ActivityLogService
+	alerts       BudgetAlertService
+	logger       *slog.Logger
 }
 
-func NewExpenseService(expenses repository.ExpenseRepository, categories repository.CategoryRepository, logger *slog.Logger) ExpenseService {
+func NewExpenseService(
+	expenses repository.ExpenseRepository,
+	categories repository.CategoryRepository,
+	accounts repository.AccountRepository,
+	tags repository.TagRepository,
+	currencies CurrencyService,
+	rules CategorizationService,
+	activityLogs ActivityLogService,
+	alerts BudgetAlertService,
+	logger *slog.Logger,
+) ExpenseService {
 	return &expenseService{
-		expenses:   expenses,
-		categories: categories,
-		logger:     logger,
+		expenses:     expenses,
+		categories:   categories,
+		accounts:     accounts,
+		tags:         tags,
+		currencies:   currencies,
+		rules:        rules,
+		activityLogs: activityLogs,
+		alerts:       alerts,
+		logger:       logger,
 	}
 }
 
 func (s *expenseService) CreateExpense(userID uint, req models.CreateExpenseRequest) (*models.Expense, error) {
 
-	if err := s.validateExpenseCreate(req); err != nil {
+	if err := s.validateExpenseCreate(userID, req); err != nil {
 		s.logger.Warn("expense create validation failed",
 			slog.Uint64("user_id", uint64(userID)),
 			slog.Uint64("category_id", uint64(req.CategoryID)),
-			slog.Float64("amount", req.Amount),
+			slog.String("amount", req.Amount.String()),
 			slog.Time("date", req.Date),
 			slog.String("reason", err.Error()),
 		)
 		return nil, err
 	}
 
+	currency, err := operationCurrency(s.accounts, s.currencies, s.logger, userID, req.AccountID, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := roundToCurrency(req.Amount, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CategoryID == 0 {
+		categoryID, err := s.categorize(userID, req.Description, amount, req.AccountID)
+		if err != nil {
+			return nil, err
+		}
+		req.CategoryID = categoryID
+	}
+
+	tags, err := resolveTags(s.tags, userID, req.TagIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	expense := &models.Expense{
 		UserID:      userID,
 		CategoryID:  req.CategoryID,
+		AccountID:   req.AccountID,
 		Description: req.Description,
 		Date:        req.Date,
-		Amount:      req.Amount,
+		Amount:      amount,
+		Currency:    currency,
+		Tags:        tags,
 	}
 	if err := s.expenses.Create(expense); err != nil {
 		s.logger.Error("expense create failed",
@@ -65,16 +130,42 @@ func (s *expenseService) CreateExpense(userID uint, req models.CreateExpenseRequ
 		slog.Uint64("expense_id", uint64(expense.ID)),
 		slog.Uint64("user_id", uint64(userID)),
 		slog.Uint64("category_id", uint64(expense.CategoryID)),
-		slog.Float64("amount", expense.Amount),
+		slog.String("amount", expense.Amount.String()),
+		slog.String("currency", expense.Currency),
 		slog.Time("date", expense.Date),
 	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeExpenseCreated,
+		EntityType:   models.EntityTypeExpense,
+		EntityID:     expense.ID,
+		Description:  "создан расход",
+		Metadata:     activityMetadata(nil, expense),
+	})
+
+	checkBudgetAlerts(s.alerts, expense)
+
 	return expense, nil
 }
 
-func (s *expenseService) GetExpenseList(filter models.ExpenseFilter) ([]models.Expense, error) {
-	expenses, err := s.expenses.List(filter)
+// GetExpenseList возвращает страницу расходов по фильтру; по умолчанию новые расходы идут первыми,
+// а при поиске — самые релевантные
+func (s *expenseService) GetExpenseList(filter models.ExpenseFilter, page models.PageRequest) (*models.Page[models.Expense], error) {
+	sorting := expenseSorting
+	if filter.Search != "" {
+		sorting = expenseSearchSorting
+	}
+	page, err := sorting.normalize(page)
+	if err != nil {
+		return nil, err
+	}
 
+	expenses, err := s.expenses.ListPage(filter, page)
 	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, ErrInvalidCursor
+		}
 		s.logger.Error("failed to list expenses",
 			slog.String("op", "list_expenses"),
 			slog.String("error", err.Error()),
@@ -83,58 +174,37 @@ func (s *expenseService) GetExpenseList(filter models.ExpenseFilter) ([]models.E
 	}
 
 	s.logger.Info("expenses listed",
-		slog.Int("count", len(expenses)),
+		slog.Int("count", len(expenses.Items)),
+		slog.Int64("total", expenses.Total),
 	)
 
 	return expenses, nil
 }
 
-func (s *expenseService) GetExpenseByID(id uint) (*models.Expense, error) {
-	expense, err := s.expenses.GetByID(id)
-
+func (s *expenseService) GetExpenseByID(userID, id uint) (*models.Expense, error) {
+	expense, err := s.getOwnedExpense(userID, id, "get_expense_by_id")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Warn("expense not found",
-				slog.Uint64("expense_id", uint64(id)),
-			)
-			return nil, ErrExpenseNotFound
-		}
-		s.logger.Error("failed to get expense",
-			slog.String("op", "get_expense_by_id"),
-			slog.Uint64("expense_id", uint64(id)),
-			slog.String("error", err.Error()),
-		)
 		return nil, err
 	}
 
 	s.logger.Info("expense retrieved",
 		slog.Uint64("expense_id", uint64(expense.ID)),
 		slog.Uint64("category_id", uint64(expense.CategoryID)),
-		slog.Float64("amount", expense.Amount),
+		slog.String("amount", expense.Amount.String()),
 	)
 
 	return expense, nil
 }
 
-func (s *expenseService) UpdateExpense(id uint, req models.UpdateExpenseRequest) (*models.Expense, error) {
-	expense, err := s.expenses.GetByID(id)
-
+func (s *expenseService) UpdateExpense(userID, id uint, req models.UpdateExpenseRequest) (*models.Expense, error) {
+	expense, err := s.getOwnedExpense(userID, id, "update_expense")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Warn("expense not found for update",
-				slog.Uint64("expense_id", uint64(id)),
-			)
-			return nil, ErrExpenseNotFound
-		}
-		s.logger.Error("failed to fetch expense before update",
-			slog.String("op", "update_expense"),
-			slog.Uint64("expense_id", uint64(id)),
-			slog.String("error", err.Error()),
-		)
 		return nil, err
 	}
 
-	if err := s.applyExpenseUpdate(expense, req); err != nil {
+	before := *expense
+
+	if err := s.applyExpenseUpdate(userID, expense, req); err != nil {
 		s.logger.Warn("expense update validation failed",
 			slog.Uint64("expense_id", uint64(id)),
 			slog.Any("request", req),
@@ -154,27 +224,27 @@ func (s *expenseService) UpdateExpense(id uint, req models.UpdateExpenseRequest)
 	s.logger.Info("expense updated",
 		slog.Uint64("expense_id", uint64(expense.ID)),
 		slog.Uint64("category_id", uint64(expense.CategoryID)),
-		slog.Float64("amount", expense.Amount),
+		slog.String("amount", expense.Amount.String()),
 		slog.Time("date", expense.Date),
 	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeExpenseUpdated,
+		EntityType:   models.EntityTypeExpense,
+		EntityID:     expense.ID,
+		Description:  "изменен расход",
+		Metadata:     activityMetadata(before, expense),
+	})
+
+	checkBudgetAlerts(s.alerts, expense)
+
 	return expense, nil
 }
 
-func (s *expenseService) DeleteExpense(id uint) error {
-	_, err := s.expenses.GetByID(id)
-
+func (s *expenseService) DeleteExpense(userID, id uint) error {
+	expense, err := s.getOwnedExpense(userID, id, "delete_expense")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Warn("expense not found for delete",
-				slog.Uint64("expense_id", uint64(id)),
-			)
-			return ErrExpenseNotFound
-		}
-		s.logger.Error("failed to fetch expense before delete",
-			slog.String("op", "delete_expense"),
-			slog.Uint64("expense_id", uint64(id)),
-			slog.String("error", err.Error()),
-		)
 		return err
 	}
 
@@ -191,34 +261,263 @@ func (s *expenseService) DeleteExpense(id uint) error {
 		slog.Uint64("expense_id", uint64(id)),
 	)
 
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeExpenseDeleted,
+		EntityType:   models.EntityTypeExpense,
+		EntityID:     id,
+		Description:  "удален расход",
+		Metadata:     activityMetadata(expense, nil),
+	})
+
 	return nil
 }
 
-func (s *expenseService) validateExpenseCreate(req models.CreateExpenseRequest) error {
-	if req.Amount <= 0 {
-		return errors.New("сумма должна быть больше нуля")
+// GetGroupedExpenses возвращает суммы расходов, сгруппированные по периодам, в базовой валюте пользователя.
+// Дневные суммы считаются в БД и пересчитываются по курсу на день расхода;
+// при FillEmpty периоды без расходов добавляются с нулевыми значениями.
+func (s *expenseService) GetGroupedExpenses(filter models.ExpenseGroupFilter) ([]models.ExpenseGroup, error) {
+	switch filter.By {
+	case models.PeriodDay, models.PeriodWeek, models.PeriodMonth:
+	default:
+		return nil, ErrInvalidGroupPeriod
 	}
 
-	// Проверка существования категории
-	_, err := s.categories.GetByID(req.CategoryID)
+	expenseFilter, err := statisticsFilter(filter.UserID, filter.StartDate, filter.EndDate)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("категория не найдена")
+		return nil, err
+	}
+
+	currency, err := s.currencies.BaseCurrency(filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	daily, err := convertedDailyTotals(s.expenses, s.currencies, expenseFilter, currency)
+	if err != nil {
+		if !errors.Is(err, ErrMissingExchangeRate) {
+			s.logger.Error("failed to aggregate expenses by period",
+				slog.String("op", "get_grouped_expenses"),
+				slog.Uint64("user_id", uint64(filter.UserID)),
+				slog.String("error", err.Error()),
+			)
 		}
-		s.logger.Error("failed to check category existence",
-			slog.Uint64("category_id", uint64(req.CategoryID)),
+		return nil, err
+	}
+	totals := sumByPeriod(daily, filter.By)
+
+	groups := make([]models.ExpenseGroup, 0, len(totals))
+	for _, t := range totals {
+		groups = append(groups, models.ExpenseGroup{
+			Period: string(filter.By),
+			Date:   t.PeriodStart,
+			Total:  t.TotalAmount,
+			Count:  t.Count,
+		})
+	}
+
+	if filter.FillEmpty {
+		if groups, err = fillEmptyGroups(groups, filter); err != nil {
+			return nil, err
+		}
+	}
+	for i := range groups {
+		groups[i].Currency = currency
+	}
+
+	if filter.IncludeExpenses {
+		// Расходы встраиваются в ответ целиком, поэтому их число ограничено
+		count := 0
+		for _, g := range groups {
+			count += g.Count
+		}
+		if count > maxGroupExpenses {
+			return nil, ErrTooManyGroupExpenses
+		}
+		if err := s.attachGroupExpenses(groups, filter.By, expenseFilter); err != nil {
+			return nil, err
+		}
+	}
+
+	s.logger.Info("expenses grouped",
+		slog.Uint64("user_id", uint64(filter.UserID)),
+		slog.String("by", string(filter.By)),
+		slog.Int("groups", len(groups)),
+	)
+
+	return groups, nil
+}
+
+// ExportExpenses построчно передает в fn расходы по фильтру списка вместе с названием и цветом категории
+func (s *expenseService) ExportExpenses(filter models.ExpenseFilter, fn func(models.ExpenseExportRow) error) error {
+	count := 0
+	err := s.expenses.Stream(filter, func(row models.ExpenseExportRow) error {
+		count++
+		return fn(row)
+	})
+	if err != nil {
+		s.logger.Error("failed to export expenses",
+			slog.String("op", "export_expenses"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.Int("exported", count),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("expenses exported",
+		slog.Uint64("user_id", uint64(filter.UserID)),
+		slog.Int("count", count),
+	)
+
+	return nil
+}
+
+// attachGroupExpenses раскладывает расходы диапазона по группам
+func (s *expenseService) attachGroupExpenses(groups []models.ExpenseGroup, by models.StatisticsPeriod, filter models.ExpenseFilter) error {
+	expenses, err := s.expenses.List(filter)
+	if err != nil {
+		s.logger.Error("failed to list expenses for groups",
+			slog.String("op", "get_grouped_expenses"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
 			slog.String("error", err.Error()),
 		)
-		return errors.New("ошибка при проверке категории")
+		return err
 	}
 
+	index := make(map[time.Time]int, len(groups))
+	for i := range groups {
+		groups[i].Expenses = make([]models.Expense, 0, groups[i].Count)
+		index[groups[i].Date] = i
+	}
+	for _, expense := range expenses {
+		if i, ok := index[periodStart(by, expense.Date)]; ok {
+			groups[i].Expenses = append(groups[i].Expenses, expense)
+		}
+	}
+	for i := range groups {
+		sort.SliceStable(groups[i].Expenses, func(a, b int) bool {
+			return groups[i].Expenses[a].Date.Before(groups[i].Expenses[b].Date)
+		})
+	}
 	return nil
 }
 
-func (s *expenseService) applyExpenseUpdate(expense *models.Expense, req models.UpdateExpenseRequest) error {
+// fillEmptyGroups дополняет отсортированные группы нулевыми периодами от начала до конца диапазона.
+// Если границы диапазона не заданы, используются первая и последняя найденные группы.
+func fillEmptyGroups(groups []models.ExpenseGroup, filter models.ExpenseGroupFilter) ([]models.ExpenseGroup, error) {
+	var from, to time.Time
+	switch {
+	case filter.StartDate != nil:
+		from = periodStart(filter.By, *filter.StartDate)
+	case len(groups) > 0:
+		from = groups[0].Date
+	default:
+		return groups, nil
+	}
+	switch {
+	case filter.EndDate != nil:
+		to = periodStart(filter.By, *filter.EndDate)
+	case len(groups) > 0:
+		to = groups[len(groups)-1].Date
+	default:
+		to = periodStart(filter.By, time.Now())
+	}
+
+	filled := make([]models.ExpenseGroup, 0, len(groups))
+	next := 0
+	for date := from; !date.After(to); date = nextPeriodStart(filter.By, date) {
+		if len(filled) >= maxExpenseGroups {
+			return nil, ErrTooManyExpenseGroups
+		}
+		if next < len(groups) && groups[next].Date.Equal(date) {
+			filled = append(filled, groups[next])
+			next++
+			continue
+		}
+		filled = append(filled, models.ExpenseGroup{Period: string(filter.By), Date: date})
+	}
+	return filled, nil
+}
+
+// getOwnedExpense загружает расход и проверяет, что он принадлежит пользователю
+func (s *expenseService) getOwnedExpense(userID, id uint, op string) (*models.Expense, error) {
+	expense, err := s.expenses.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("expense not found",
+				slog.String("op", op),
+				slog.Uint64("expense_id", uint64(id)),
+			)
+			return nil, ErrExpenseNotFound
+		}
+		s.logger.Error("failed to fetch expense",
+			slog.String("op", op),
+			slog.Uint64("expense_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if expense.UserID != userID {
+		s.logger.Warn("expense belongs to another user",
+			slog.String("op", op),
+			slog.Uint64("expense_id", uint64(id)),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		return nil, ErrForbidden
+	}
+
+	return expense, nil
+}
+
+func (s *expenseService) validateExpenseCreate(userID uint, req models.CreateExpenseRequest) error {
+	if req.Amount <= 0 {
+		return errors.New("сумма должна быть больше нуля")
+	}
+
+	// Без категории она будет подобрана правилами категоризации
+	if req.CategoryID == 0 {
+		return nil
+	}
+	_, err := categoryReference(s.categories, s.logger, userID, req.CategoryID, models.CategoryTypeExpense)
+	return err
+}
+
+// categorize подбирает категорию расхода по правилам пользователя
+func (s *expenseService) categorize(userID uint, description string, amount money.Amount, accountID *uint) (uint, error) {
+	matcher, err := s.rules.Matcher(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	rule := matcher.Match(description, amount, accountID)
+	if rule == nil {
+		s.logger.Warn("no categorization rule matched",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("amount", amount.String()),
+		)
+		return 0, ErrNoMatchingRule
+	}
+
+	s.logger.Info("expense categorized by rule",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("rule_id", uint64(rule.ID)),
+		slog.Uint64("category_id", uint64(rule.CategoryID)),
+	)
+	return rule.CategoryID, nil
+}
+
+func (s *expenseService) applyExpenseUpdate(userID uint, expense *models.Expense, req models.UpdateExpenseRequest) error {
 	if req.CategoryID != nil {
+		if _, err := categoryReference(s.categories, s.logger, userID, *req.CategoryID, models.CategoryTypeExpense); err != nil {
+			return err
+		}
 		expense.CategoryID = *req.CategoryID
-		// TODO: добавить валидацию существования категории при обновлении
+	}
+
+	if req.AccountID != nil {
+		expense.AccountID = accountIDUpdate(*req.AccountID)
 	}
 
 	if req.Description != nil {
@@ -232,9 +531,39 @@ func (s *expenseService) applyExpenseUpdate(expense *models.Expense, req models.
 		expense.Amount = *req.Amount
 	}
 
+	if req.Currency != nil {
+		currency, err := normalizeCurrency(*req.Currency)
+		if err != nil {
+			return err
+		}
+		expense.Currency = currency
+	}
+
 	if req.Date != nil {
 		expense.Date = *req.Date
 	}
 
+	if req.TagIDs != nil {
+		tags, err := resolveTags(s.tags, userID, *req.TagIDs)
+		if err != nil {
+			return err
+		}
+		expense.Tags = tags
+	}
+
+	// Операция по счету должна остаться в валюте счета
+	if req.AccountID != nil || req.Currency != nil {
+		if _, err := operationCurrency(s.accounts, s.currencies, s.logger, userID, expense.AccountID, expense.Currency); err != nil {
+			return err
+		}
+	}
+
+	// Сумма округляется после смены валюты, чтобы учесть точность новой валюты
+	amount, err := roundToCurrency(expense.Amount, expense.Currency)
+	if err != nil {
+		return err
+	}
+	expense.Amount = amount
+
 	return nil
 }
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"errors"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+var ErrIncomeNotFound = errors.New("доход не найден")
+
+type IncomeService interface {
+	CreateIncome(userID uint, req models.CreateIncomeRequest) (*models.Income, error)
+	GetIncomeList(filter models.IncomeFilter) ([]models.Income, error)
+	GetIncomeByID(userID, id uint) (*models.Income, error)
+	UpdateIncome(userID, id uint, req models.UpdateIncomeRequest) (*models.Income, error)
+	DeleteIncome(userID, id uint) error
+}
+
+type incomeService struct {
+	incomes      repository.IncomeRepository
+	categories   repository.CategoryRepository
+	accounts     repository.AccountRepository
+	currencies   CurrencyService
+	activityLogs ActivityLogService
+	logger       *slog.Logger
+}
+
+func NewIncomeService(
+	incomes repository.IncomeRepository,
+	categories repository.CategoryRepository,
+	accounts repository.AccountRepository,
+	currencies CurrencyService,
+	activityLogs ActivityLogService,
+	logger *slog.Logger,
+) IncomeService {
+	return &incomeService{
+		incomes:      incomes,
+		categories:   categories,
+		accounts:     accounts,
+		currencies:   currencies,
+		activityLogs: activityLogs,
+		logger:       logger,
+	}
+}
+
+func (s *incomeService) CreateIncome(userID uint, req models.CreateIncomeRequest) (*models.Income, error) {
+	if err := s.validateIncomeCreate(userID, req); err != nil {
+		s.logger.Warn("income create validation failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Uint64("category_id", uint64(req.CategoryID)),
+			slog.String("amount", req.Amount.String()),
+			slog.Time("date", req.Date),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	currency, err := operationCurrency(s.accounts, s.currencies, s.logger, userID, req.AccountID, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := roundToCurrency(req.Amount, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	income := &models.Income{
+		UserID:      userID,
+		CategoryID:  req.CategoryID,
+		AccountID:   req.AccountID,
+		Description: req.Description,
+		Date:        req.Date,
+		Amount:      amount,
+		Currency:    currency,
+	}
+	if err := s.incomes.Create(income); err != nil {
+		s.logger.Error("income create failed",
+			slog.String("op", "create_income"),
+			slog.Any("request", req),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	s.logger.Info("income created",
+		slog.Uint64("income_id", uint64(income.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Uint64("category_id", uint64(income.CategoryID)),
+		slog.String("amount", income.Amount.String()),
+		slog.String("currency", income.Currency),
+		slog.Time("date", income.Date),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeIncomeCreated,
+		EntityType:   models.EntityTypeIncome,
+		EntityID:     income.ID,
+		Description:  "создан доход",
+		Metadata:     activityMetadata(nil, income),
+	})
+
+	return income, nil
+}
+
+func (s *incomeService) GetIncomeList(filter models.IncomeFilter) ([]models.Income, error) {
+	incomes, err := s.incomes.List(filter)
+	if err != nil {
+		s.logger.Error("failed to list incomes",
+			slog.String("op", "list_incomes"),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("incomes listed",
+		slog.Int("count", len(incomes)),
+	)
+
+	return incomes, nil
+}
+
+func (s *incomeService) GetIncomeByID(userID, id uint) (*models.Income, error) {
+	income, err := s.getOwnedIncome(userID, id, "get_income_by_id")
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("income retrieved",
+		slog.Uint64("income_id", uint64(income.ID)),
+		slog.Uint64("category_id", uint64(income.CategoryID)),
+		slog.String("amount", income.Amount.String()),
+	)
+
+	return income, nil
+}
+
+func (s *incomeService) UpdateIncome(userID, id uint, req models.UpdateIncomeRequest) (*models.Income, error) {
+	income, err := s.getOwnedIncome(userID, id, "update_income")
+	if err != nil {
+		return nil, err
+	}
+
+	before := *income
+
+	if err := s.applyIncomeUpdate(userID, income, req); err != nil {
+		s.logger.Warn("income update validation failed",
+			slog.Uint64("income_id", uint64(id)),
+			slog.Any("request", req),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := s.incomes.Update(income); err != nil {
+		s.logger.Error("income update failed",
+			slog.String("op", "update_income"),
+			slog.Uint64("income_id", uint64(income.ID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	s.logger.Info("income updated",
+		slog.Uint64("income_id", uint64(income.ID)),
+		slog.Uint64("category_id", uint64(income.CategoryID)),
+		slog.String("amount", income.Amount.String()),
+		slog.Time("date", income.Date),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeIncomeUpdated,
+		EntityType:   models.EntityTypeIncome,
+		EntityID:     income.ID,
+		Description:  "изменен доход",
+		Metadata:     activityMetadata(before, income),
+	})
+
+	return income, nil
+}
+
+func (s *incomeService) DeleteIncome(userID, id uint) error {
+	income, err := s.getOwnedIncome(userID, id, "delete_income")
+	if err != nil {
+		return err
+	}
+
+	if err := s.incomes.Delete(id); err != nil {
+		s.logger.Error("income delete failed",
+			slog.String("op", "delete_income"),
+			slog.Uint64("income_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("income deleted",
+		slog.Uint64("income_id", uint64(id)),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeIncomeDeleted,
+		EntityType:   models.EntityTypeIncome,
+		EntityID:     id,
+		Description:  "удален доход",
+		Metadata:     activityMetadata(income, nil),
+	})
+
+	return nil
+}
+
+// getOwnedIncome загружает доход и проверяет, что он принадлежит пользователю
+func (s *incomeService) getOwnedIncome(userID, id uint, op string) (*models.Income, error) {
+	income, err := s.incomes.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("income not found",
+				slog.String("op", op),
+				slog.Uint64("income_id", uint64(id)),
+			)
+			return nil, ErrIncomeNotFound
+		}
+		s.logger.Error("failed to fetch income",
+			slog.String("op", op),
+			slog.Uint64("income_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if income.UserID != userID {
+		s.logger.Warn("income belongs to another user",
+			slog.String("op", op),
+			slog.Uint64("income_id", uint64(id)),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		return nil, ErrForbidden
+	}
+
+	return income, nil
+}
+
+func (s *incomeService) validateIncomeCreate(userID uint, req models.CreateIncomeRequest) error {
+	if req.Amount <= 0 {
+		return errors.New("сумма должна быть больше нуля")
+	}
+
+	_, err := categoryReference(s.categories, s.logger, userID, req.CategoryID, models.CategoryTypeIncome)
+	return err
+}
+
+func (s *incomeService) applyIncomeUpdate(userID uint, income *models.Income, req models.UpdateIncomeRequest) error {
+	if req.CategoryID != nil {
+		if _, err := categoryReference(s.categories, s.logger, userID, *req.CategoryID, models.CategoryTypeIncome); err != nil {
+			return err
+		}
+		income.CategoryID = *req.CategoryID
+	}
+
+	if req.AccountID != nil {
+		income.AccountID = accountIDUpdate(*req.AccountID)
+	}
+
+	if req.Description != nil {
+		income.Description = *req.Description
+	}
+
+	if req.Amount != nil {
+		if *req.Amount <= 0 {
+			return errors.New("сумма должна быть больше нуля")
+		}
+		income.Amount = *req.Amount
+	}
+
+	if req.Currency != nil {
+		currency, err := normalizeCurrency(*req.Currency)
+		if err != nil {
+			return err
+		}
+		income.Currency = currency
+	}
+
+	if req.Date != nil {
+		income.Date = *req.Date
+	}
+
+	// Операция по счету должна остаться в валюте счета
+	if req.AccountID != nil || req.Currency != nil {
+		if _, err := operationCurrency(s.accounts, s.currencies, s.logger, userID, income.AccountID, income.Currency); err != nil {
+			return err
+		}
+	}
+
+	// Сумма округляется после смены валюты, чтобы учесть точность новой валюты
+	amount, err := roundToCurrency(income.Amount, income.Currency)
+	if err != nil {
+		return err
+	}
+	income.Amount = amount
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidPageRequest общая причина ошибок размера страницы, сортировки и курсора
+var ErrInvalidPageRequest = errors.New("некорректные параметры страницы")
+
+var (
+	ErrInvalidPageSize  = fmt.Errorf("%w: limit должен быть от 1 до %d", ErrInvalidPageRequest, models.MaxPageSize)
+	ErrInvalidSortField = fmt.Errorf("%w: сортировка по этому полю недоступна", ErrInvalidPageRequest)
+	ErrInvalidSortOrder = fmt.Errorf("%w: order должен быть asc или desc", ErrInvalidPageRequest)
+	ErrInvalidCursor    = fmt.Errorf("%w: курсор поврежден или выдан для другой сортировки", ErrInvalidPageRequest)
+)
+
+// listSorting допустимые поля сортировки списка; первое поле и order используются по умолчанию
+type listSorting struct {
+	fields []string
+	order  models.SortOrder
+}
+
+var (
+	expenseSorting = listSorting{
+		fields: []string{models.SortByDate, models.SortByAmount, models.SortByCreatedAt},
+		order:  models.SortDesc,
+	}
+	// expenseSearchSorting сортировка расходов при полнотекстовом поиске: сначала самые релевантные
+	expenseSearchSorting = listSorting{
+		fields: []string{models.SortByRelevance, models.SortByDate, models.SortByAmount, models.SortByCreatedAt},
+		order:  models.SortDesc,
+	}
+	budgetSorting = listSorting{
+		fields: []string{models.SortByPeriod, models.SortByAmount, models.SortByCreatedAt},
+		order:  models.SortDesc,
+	}
+	categorySorting = listSorting{
+		fields: []string{models.SortByName, models.SortByCreatedAt},
+		order:  models.SortAsc,
+	}
+	recurringExpenseSorting = listSorting{
+		fields: []string{models.SortByNextDate, models.SortByAmount, models.SortByCreatedAt},
+		order:  models.SortAsc,
+	}
+	activityLogSorting = listSorting{
+		fields: []string{models.SortByCreatedAt},
+		order:  models.SortDesc,
+	}
+)
+
+// normalize подставляет значения по умолчанию и проверяет размер страницы и сортировку
+func (l listSorting) normalize(page models.PageRequest) (models.PageRequest, error) {
+	if page.Limit == 0 {
+		page.Limit = models.DefaultPageSize
+	}
+	if page.Limit < 0 || page.Limit > models.MaxPageSize {
+		return page, ErrInvalidPageSize
+	}
+
+	if page.SortBy == "" {
+		page.SortBy = l.fields[0]
+	} else if !slices.Contains(l.fields, page.SortBy) {
+		return page, fmt.Errorf("%w: %s, доступны %s", ErrInvalidSortField, page.SortBy, strings.Join(l.fields, ", "))
+	}
+
+	switch page.Order {
+	case "":
+		page.Order = l.order
+	case models.SortAsc, models.SortDesc:
+	default:
+		return page, ErrInvalidSortOrder
+	}
+	return page, nil
+}
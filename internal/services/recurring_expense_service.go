@@ -3,6 +3,7 @@ package services
 import (
 	"cashcontrol/internal/models"
 	"cashcontrol/internal/repository"
+	"context"
 	"errors"
 	"log/slog"
 	"time"
@@ -12,33 +13,49 @@ import (
 
 var ErrRecurringExpenseNotFound = errors.New("регулярный расход не найден")
 
+// maxCatchUpOccurrences ограничивает число пропущенных повторений, создаваемых за один проход,
+// чтобы ошибочная дата в далеком прошлом не породила бесконечный цикл
+const maxCatchUpOccurrences = 1000
+
 type RecurringExpenseService interface {
 	CreateRecurringExpense(userID uint, req models.CreateRecurringExpenseRequest) (*models.RecurringExpense, error)
-	GetRecurringExpenseList(userID uint) ([]models.RecurringExpense, error)
-	GetRecurringExpenseByID(id uint) (*models.RecurringExpense, error)
+	GetRecurringExpenseList(userID uint, page models.PageRequest) (*models.Page[models.RecurringExpense], error)
+	GetRecurringExpenseByID(userID, id uint) (*models.RecurringExpense, error)
 	GetActiveRecurringExpenses(userID uint) ([]models.RecurringExpense, error)
-	UpdateRecurringExpense(id uint, req models.UpdateRecurringExpenseRequest) (*models.RecurringExpense, error)
-	DeleteRecurringExpense(id uint) error
-	ActivateRecurringExpense(id uint) (*models.RecurringExpense, error)
-	DeactivateRecurringExpense(id uint) (*models.RecurringExpense, error)
-	ProcessRecurringExpenses() error
+	UpdateRecurringExpense(userID, id uint, req models.UpdateRecurringExpenseRequest) (*models.RecurringExpense, error)
+	DeleteRecurringExpense(userID, id uint) error
+	ActivateRecurringExpense(userID, id uint) (*models.RecurringExpense, error)
+	DeactivateRecurringExpense(userID, id uint) (*models.RecurringExpense, error)
+	ProcessRecurringExpenses(ctx context.Context) error
 	CalculateNextDate(recurringExpense *models.RecurringExpense) time.Time
 }
 
 type recurringExpenseService struct {
 	recurringExpenses repository.RecurringExpenseRepository
-	expenses          repository.ExpenseRepository
+	categories        repository.CategoryRepository
+	accounts          repository.AccountRepository
+	currencies        CurrencyService
+	activityLogs      ActivityLogService
+	alerts            BudgetAlertService
 	logger            *slog.Logger
 }
 
 func NewRecurringExpenseService(
 	recurringExpenses repository.RecurringExpenseRepository,
-	expenses repository.ExpenseRepository,
+	categories repository.CategoryRepository,
+	accounts repository.AccountRepository,
+	currencies CurrencyService,
+	activityLogs ActivityLogService,
+	alerts BudgetAlertService,
 	logger *slog.Logger,
 ) RecurringExpenseService {
 	return &recurringExpenseService{
 		recurringExpenses: recurringExpenses,
-		expenses:          expenses,
+		categories:        categories,
+		accounts:          accounts,
+		currencies:        currencies,
+		activityLogs:      activityLogs,
+		alerts:            alerts,
 		logger:            logger,
 	}
 }
@@ -53,13 +70,33 @@ func (s *recurringExpenseService) CreateRecurringExpense(userID uint, req models
 		return nil, err
 	}
 
+	if _, err := categoryReference(s.categories, s.logger, userID, req.CategoryID, models.CategoryTypeExpense); err != nil {
+		s.logger.Warn("recurring expense create category check failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.Uint64("category_id", uint64(req.CategoryID)),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	currency, err := operationCurrency(s.accounts, s.currencies, s.logger, userID, req.AccountID, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := roundToCurrency(req.Amount, currency)
+	if err != nil {
+		return nil, err
+	}
+
 	// Вычисляем следующую дату создания расхода
 	nextDate := s.calculateInitialNextDate(req.Type, req.DayOfWeek, req.DayOfMonth)
 
 	recurringExpense := &models.RecurringExpense{
 		UserID:      userID,
 		CategoryID:  req.CategoryID,
-		Amount:      req.Amount,
+		AccountID:   req.AccountID,
+		Amount:      amount,
+		Currency:    currency,
 		Description: req.Description,
 		Type:        req.Type,
 		DayOfWeek:   req.DayOfWeek,
@@ -85,12 +122,30 @@ func (s *recurringExpenseService) CreateRecurringExpense(userID uint, req models
 		slog.Time("next_date", nextDate),
 	)
 
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeRecurringCreated,
+		EntityType:   models.EntityTypeRecurringExpense,
+		EntityID:     recurringExpense.ID,
+		Description:  "создан регулярный расход",
+		Metadata:     activityMetadata(nil, recurringExpense),
+	})
+
 	return recurringExpense, nil
 }
 
-func (s *recurringExpenseService) GetRecurringExpenseList(userID uint) ([]models.RecurringExpense, error) {
-	recurringExpenses, err := s.recurringExpenses.GetByUserID(userID)
+// GetRecurringExpenseList возвращает страницу регулярных расходов; по умолчанию ближайшие платежи идут первыми
+func (s *recurringExpenseService) GetRecurringExpenseList(userID uint, page models.PageRequest) (*models.Page[models.RecurringExpense], error) {
+	page, err := recurringExpenseSorting.normalize(page)
 	if err != nil {
+		return nil, err
+	}
+
+	recurringExpenses, err := s.recurringExpenses.ListPage(userID, page)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			return nil, ErrInvalidCursor
+		}
 		s.logger.Error("failed to list recurring expenses",
 			slog.String("op", "list_recurring_expenses"),
 			slog.Uint64("user_id", uint64(userID)),
@@ -101,26 +156,16 @@ func (s *recurringExpenseService) GetRecurringExpenseList(userID uint) ([]models
 
 	s.logger.Info("recurring expenses listed",
 		slog.Uint64("user_id", uint64(userID)),
-		slog.Int("count", len(recurringExpenses)),
+		slog.Int("count", len(recurringExpenses.Items)),
+		slog.Int64("total", recurringExpenses.Total),
 	)
 
 	return recurringExpenses, nil
 }
 
-func (s *recurringExpenseService) GetRecurringExpenseByID(id uint) (*models.RecurringExpense, error) {
-	recurringExpense, err := s.recurringExpenses.GetByID(id)
+func (s *recurringExpenseService) GetRecurringExpenseByID(userID, id uint) (*models.RecurringExpense, error) {
+	recurringExpense, err := s.getOwnedRecurringExpense(userID, id, "get_recurring_expense_by_id")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Warn("recurring expense not found",
-				slog.Uint64("recurring_expense_id", uint64(id)),
-			)
-			return nil, ErrRecurringExpenseNotFound
-		}
-		s.logger.Error("failed to get recurring expense",
-			slog.String("op", "get_recurring_expense_by_id"),
-			slog.Uint64("recurring_expense_id", uint64(id)),
-			slog.String("error", err.Error()),
-		)
 		return nil, err
 	}
 
@@ -157,21 +202,23 @@ func (s *recurringExpenseService) GetActiveRecurringExpenses(userID uint) ([]mod
 	return activeRecurringExpenses, nil
 }
 
-func (s *recurringExpenseService) UpdateRecurringExpense(id uint, req models.UpdateRecurringExpenseRequest) (*models.RecurringExpense, error) {
-	recurringExpense, err := s.recurringExpenses.GetByID(id)
+func (s *recurringExpenseService) UpdateRecurringExpense(userID, id uint, req models.UpdateRecurringExpenseRequest) (*models.RecurringExpense, error) {
+	recurringExpense, err := s.getOwnedRecurringExpense(userID, id, "update_recurring_expense")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Warn("recurring expense not found for update",
+		return nil, err
+	}
+
+	before := *recurringExpense
+
+	if req.CategoryID != nil {
+		if _, err := categoryReference(s.categories, s.logger, userID, *req.CategoryID, models.CategoryTypeExpense); err != nil {
+			s.logger.Warn("recurring expense update category check failed",
 				slog.Uint64("recurring_expense_id", uint64(id)),
+				slog.Uint64("category_id", uint64(*req.CategoryID)),
+				slog.String("reason", err.Error()),
 			)
-			return nil, ErrRecurringExpenseNotFound
+			return nil, err
 		}
-		s.logger.Error("failed to fetch recurring expense before update",
-			slog.String("op", "update_recurring_expense"),
-			slog.Uint64("recurring_expense_id", uint64(id)),
-			slog.String("error", err.Error()),
-		)
-		return nil, err
 	}
 
 	if err := s.applyRecurringExpenseUpdate(recurringExpense, req); err != nil {
@@ -183,6 +230,17 @@ func (s *recurringExpenseService) UpdateRecurringExpense(id uint, req models.Upd
 		return nil, err
 	}
 
+	// Расходы по счету должны оставаться в валюте счета
+	if req.AccountID != nil || req.Currency != nil {
+		if _, err := operationCurrency(s.accounts, s.currencies, s.logger, userID, recurringExpense.AccountID, recurringExpense.Currency); err != nil {
+			s.logger.Warn("recurring expense update account check failed",
+				slog.Uint64("recurring_expense_id", uint64(id)),
+				slog.String("reason", err.Error()),
+			)
+			return nil, err
+		}
+	}
+
 	// Пересчитываем следующую дату, если изменился тип или параметры
 	if req.Type != nil || req.DayOfWeek != nil || req.DayOfMonth != nil {
 		recurringExpense.NextDate = s.CalculateNextDate(recurringExpense)
@@ -201,23 +259,14 @@ func (s *recurringExpenseService) UpdateRecurringExpense(id uint, req models.Upd
 		slog.Uint64("recurring_expense_id", uint64(recurringExpense.ID)),
 	)
 
+	s.recordUpdate(userID, before, recurringExpense, "изменен регулярный расход")
+
 	return recurringExpense, nil
 }
 
-func (s *recurringExpenseService) DeleteRecurringExpense(id uint) error {
-	_, err := s.recurringExpenses.GetByID(id)
+func (s *recurringExpenseService) DeleteRecurringExpense(userID, id uint) error {
+	recurringExpense, err := s.getOwnedRecurringExpense(userID, id, "delete_recurring_expense")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			s.logger.Warn("recurring expense not found for delete",
-				slog.Uint64("recurring_expense_id", uint64(id)),
-			)
-			return ErrRecurringExpenseNotFound
-		}
-		s.logger.Error("failed to fetch recurring expense before delete",
-			slog.String("op", "delete_recurring_expense"),
-			slog.Uint64("recurring_expense_id", uint64(id)),
-			slog.String("error", err.Error()),
-		)
 		return err
 	}
 
@@ -234,18 +283,25 @@ func (s *recurringExpenseService) DeleteRecurringExpense(id uint) error {
 		slog.Uint64("recurring_expense_id", uint64(id)),
 	)
 
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeRecurringDeleted,
+		EntityType:   models.EntityTypeRecurringExpense,
+		EntityID:     id,
+		Description:  "удален регулярный расход",
+		Metadata:     activityMetadata(recurringExpense, nil),
+	})
+
 	return nil
 }
 
-func (s *recurringExpenseService) ActivateRecurringExpense(id uint) (*models.RecurringExpense, error) {
-	recurringExpense, err := s.recurringExpenses.GetByID(id)
+func (s *recurringExpenseService) ActivateRecurringExpense(userID, id uint) (*models.RecurringExpense, error) {
+	recurringExpense, err := s.getOwnedRecurringExpense(userID, id, "activate_recurring_expense")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrRecurringExpenseNotFound
-		}
 		return nil, err
 	}
 
+	before := *recurringExpense
 	recurringExpense.IsActive = true
 	if err := s.recurringExpenses.Update(recurringExpense); err != nil {
 		s.logger.Error("failed to activate recurring expense",
@@ -259,18 +315,18 @@ func (s *recurringExpenseService) ActivateRecurringExpense(id uint) (*models.Rec
 		slog.Uint64("recurring_expense_id", uint64(id)),
 	)
 
+	s.recordUpdate(userID, before, recurringExpense, "активирован регулярный расход")
+
 	return recurringExpense, nil
 }
 
-func (s *recurringExpenseService) DeactivateRecurringExpense(id uint) (*models.RecurringExpense, error) {
-	recurringExpense, err := s.recurringExpenses.GetByID(id)
+func (s *recurringExpenseService) DeactivateRecurringExpense(userID, id uint) (*models.RecurringExpense, error) {
+	recurringExpense, err := s.getOwnedRecurringExpense(userID, id, "deactivate_recurring_expense")
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrRecurringExpenseNotFound
-		}
 		return nil, err
 	}
 
+	before := *recurringExpense
 	recurringExpense.IsActive = false
 	if err := s.recurringExpenses.Update(recurringExpense); err != nil {
 		s.logger.Error("failed to deactivate recurring expense",
@@ -284,10 +340,14 @@ func (s *recurringExpenseService) DeactivateRecurringExpense(id uint) (*models.R
 		slog.Uint64("recurring_expense_id", uint64(id)),
 	)
 
+	s.recordUpdate(userID, before, recurringExpense, "деактивирован регулярный расход")
+
 	return recurringExpense, nil
 }
 
-func (s *recurringExpenseService) ProcessRecurringExpenses() error {
+// ProcessRecurringExpenses создает расходы по всем наступившим регулярным платежам.
+// Если сервер простаивал несколько периодов, создается расход на каждое пропущенное повторение.
+func (s *recurringExpenseService) ProcessRecurringExpenses(ctx context.Context) error {
 	now := time.Now()
 	dueRecurringExpenses, err := s.recurringExpenses.GetActiveByNextDate(now)
 	if err != nil {
@@ -298,70 +358,129 @@ func (s *recurringExpenseService) ProcessRecurringExpenses() error {
 		return err
 	}
 
-	for _, recurringExpense := range dueRecurringExpenses {
-		// Создаем расход
-		expense := &models.Expense{
-			UserID:      recurringExpense.UserID,
-			CategoryID:  recurringExpense.CategoryID,
-			Amount:      recurringExpense.Amount,
-			Description: recurringExpense.Description,
-			Date:        recurringExpense.NextDate,
+	for i := range dueRecurringExpenses {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		recurringExpense := &dueRecurringExpenses[i]
+		processed := 0
+		for !recurringExpense.NextDate.After(now) && processed < maxCatchUpOccurrences {
+			if err := s.processOccurrence(recurringExpense); err != nil {
+				break
+			}
+			processed++
 		}
 
-		if err := s.expenses.Create(expense); err != nil {
-			s.logger.Error("failed to create expense from recurring expense",
+		if processed == maxCatchUpOccurrences {
+			s.logger.Warn("recurring expense catch-up limit reached",
 				slog.Uint64("recurring_expense_id", uint64(recurringExpense.ID)),
-				slog.String("error", err.Error()),
+				slog.Time("next_date", recurringExpense.NextDate),
 			)
-			continue
 		}
+	}
+
+	return nil
+}
 
-		// Обновляем следующую дату
-		recurringExpense.NextDate = s.CalculateNextDate(&recurringExpense)
-		if err := s.recurringExpenses.Update(&recurringExpense); err != nil {
-			s.logger.Error("failed to update next date for recurring expense",
+// processOccurrence создает расход на дату NextDate и сдвигает NextDate на следующий период.
+// Обе операции выполняются в одной транзакции, повторный запуск не создает дубликат.
+func (s *recurringExpenseService) processOccurrence(recurringExpense *models.RecurringExpense) error {
+	occurrenceDate := occurrenceDateOf(recurringExpense.NextDate)
+	expense := &models.Expense{
+		UserID:             recurringExpense.UserID,
+		CategoryID:         recurringExpense.CategoryID,
+		AccountID:          recurringExpense.AccountID,
+		Amount:             recurringExpense.Amount,
+		Currency:           recurringExpense.Currency,
+		Description:        recurringExpense.Description,
+		Date:               recurringExpense.NextDate,
+		RecurringExpenseID: &recurringExpense.ID,
+		OccurrenceDate:     &occurrenceDate,
+	}
+
+	created, err := s.recurringExpenses.MaterializeOccurrence(recurringExpense, expense, nextOccurrence(recurringExpense, recurringExpense.NextDate))
+	if err != nil {
+		if errors.Is(err, repository.ErrRecurringExpenseChanged) {
+			s.logger.Warn("recurring expense changed during processing",
 				slog.Uint64("recurring_expense_id", uint64(recurringExpense.ID)),
-				slog.String("error", err.Error()),
 			)
-			continue
+			return err
 		}
+		s.logger.Error("failed to materialize recurring expense occurrence",
+			slog.Uint64("recurring_expense_id", uint64(recurringExpense.ID)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
 
-		s.logger.Info("processed recurring expense",
+	if !created {
+		s.logger.Info("recurring expense occurrence already materialized",
 			slog.Uint64("recurring_expense_id", uint64(recurringExpense.ID)),
-			slog.Uint64("expense_id", uint64(expense.ID)),
+			slog.Time("occurrence_date", occurrenceDate),
 			slog.Time("next_date", recurringExpense.NextDate),
 		)
+		return nil
 	}
 
+	s.logger.Info("processed recurring expense",
+		slog.Uint64("recurring_expense_id", uint64(recurringExpense.ID)),
+		slog.Uint64("expense_id", uint64(expense.ID)),
+		slog.Time("next_date", recurringExpense.NextDate),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       recurringExpense.UserID,
+		ActivityType: models.ActivityTypeExpenseCreated,
+		EntityType:   models.EntityTypeExpense,
+		EntityID:     expense.ID,
+		Description:  "создан расход по регулярному платежу",
+		Metadata: map[string]interface{}{
+			"after":                expense,
+			"recurring_expense_id": recurringExpense.ID,
+		},
+	})
+
+	checkBudgetAlerts(s.alerts, expense)
+
 	return nil
 }
 
+// CalculateNextDate возвращает ближайшую дату повторения, не раньше текущего момента
 func (s *recurringExpenseService) CalculateNextDate(recurringExpense *models.RecurringExpense) time.Time {
-	now := time.Now()
 	baseDate := recurringExpense.NextDate
-	if baseDate.Before(now) {
+	if now := time.Now(); baseDate.Before(now) {
 		baseDate = now
 	}
+	return nextOccurrence(recurringExpense, baseDate)
+}
 
+// occurrenceDateOf возвращает календарную дату повторения, по которой расход связан с регулярным платежом
+func occurrenceDateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// nextOccurrence возвращает первую дату повторения строго после from
+func nextOccurrence(recurringExpense *models.RecurringExpense, from time.Time) time.Time {
 	switch recurringExpense.Type {
 	case models.RecurringTypeDaily:
-		return baseDate.AddDate(0, 0, 1)
+		return from.AddDate(0, 0, 1)
 
 	case models.RecurringTypeWeekly:
 		if recurringExpense.DayOfWeek != nil {
 			// Находим следующий указанный день недели
-			daysUntilTarget := (int(*recurringExpense.DayOfWeek) - int(baseDate.Weekday()) + 7) % 7
+			daysUntilTarget := (*recurringExpense.DayOfWeek - int(from.Weekday()) + 7) % 7
 			if daysUntilTarget == 0 {
 				daysUntilTarget = 7 // Если сегодня нужный день, берем следующий
 			}
-			return baseDate.AddDate(0, 0, daysUntilTarget)
+			return from.AddDate(0, 0, daysUntilTarget)
 		}
-		return baseDate.AddDate(0, 0, 7)
+		return from.AddDate(0, 0, 7)
 
 	case models.RecurringTypeMonthly:
 		if recurringExpense.DayOfMonth != nil {
-			// Находим следующий месяц с указанным днем
-			nextMonth := baseDate.AddDate(0, 1, 0)
+			// Считаем от первого числа, чтобы AddDate не перескочил короткий месяц (31 января -> 3 марта)
+			nextMonth := time.Date(from.Year(), from.Month()+1, 1, 0, 0, 0, 0, from.Location())
 			day := *recurringExpense.DayOfMonth
 			// Проверяем, что день существует в месяце
 			lastDayOfMonth := time.Date(nextMonth.Year(), nextMonth.Month()+1, 0, 0, 0, 0, 0, nextMonth.Location()).Day()
@@ -370,14 +489,57 @@ func (s *recurringExpenseService) CalculateNextDate(recurringExpense *models.Rec
 			}
 			return time.Date(nextMonth.Year(), nextMonth.Month(), day, 0, 0, 0, 0, nextMonth.Location())
 		}
-		return baseDate.AddDate(0, 1, 0)
+		return from.AddDate(0, 1, 0)
 
 	case models.RecurringTypeYearly:
-		return baseDate.AddDate(1, 0, 0)
+		return from.AddDate(1, 0, 0)
 
 	default:
-		return baseDate.AddDate(0, 0, 1)
+		return from.AddDate(0, 0, 1)
+	}
+}
+
+// recordUpdate записывает в историю изменение регулярного расхода
+func (s *recurringExpenseService) recordUpdate(userID uint, before models.RecurringExpense, after *models.RecurringExpense, description string) {
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeRecurringUpdated,
+		EntityType:   models.EntityTypeRecurringExpense,
+		EntityID:     after.ID,
+		Description:  description,
+		Metadata:     activityMetadata(before, after),
+	})
+}
+
+// getOwnedRecurringExpense загружает регулярный расход и проверяет, что он принадлежит пользователю
+func (s *recurringExpenseService) getOwnedRecurringExpense(userID, id uint, op string) (*models.RecurringExpense, error) {
+	recurringExpense, err := s.recurringExpenses.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("recurring expense not found",
+				slog.String("op", op),
+				slog.Uint64("recurring_expense_id", uint64(id)),
+			)
+			return nil, ErrRecurringExpenseNotFound
+		}
+		s.logger.Error("failed to fetch recurring expense",
+			slog.String("op", op),
+			slog.Uint64("recurring_expense_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
 	}
+
+	if recurringExpense.UserID != userID {
+		s.logger.Warn("recurring expense belongs to another user",
+			slog.String("op", op),
+			slog.Uint64("recurring_expense_id", uint64(id)),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		return nil, ErrForbidden
+	}
+
+	return recurringExpense, nil
 }
 
 func (s *recurringExpenseService) validateRecurringExpenseCreate(req models.CreateRecurringExpenseRequest) error {
@@ -424,6 +586,12 @@ func (s *recurringExpenseService) applyRecurringExpenseUpdate(
 ) error {
 	if req.CategoryID != nil {
 		recurringExpense.CategoryID = *req.CategoryID
+		// Сбрасываем предзагруженную категорию, иначе Save перезапишет category_id старым значением
+		recurringExpense.Category = models.Category{}
+	}
+
+	if req.AccountID != nil {
+		recurringExpense.AccountID = accountIDUpdate(*req.AccountID)
 	}
 
 	if req.Amount != nil {
@@ -433,6 +601,14 @@ func (s *recurringExpenseService) applyRecurringExpenseUpdate(
 		recurringExpense.Amount = *req.Amount
 	}
 
+	if req.Currency != nil {
+		currency, err := normalizeCurrency(*req.Currency)
+		if err != nil {
+			return err
+		}
+		recurringExpense.Currency = currency
+	}
+
 	if req.Description != nil {
 		recurringExpense.Description = *req.Description
 	}
@@ -459,6 +635,13 @@ func (s *recurringExpenseService) applyRecurringExpenseUpdate(
 		recurringExpense.IsActive = *req.IsActive
 	}
 
+	// Сумма округляется после смены валюты, чтобы учесть точность новой валюты
+	amount, err := roundToCurrency(recurringExpense.Amount, recurringExpense.Currency)
+	if err != nil {
+		return err
+	}
+	recurringExpense.Amount = amount
+
 	return nil
 }
 
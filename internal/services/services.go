@@ -0,0 +1,76 @@
+package services
+
+import (
+	"cashcontrol/internal/config"
+	"cashcontrol/internal/notifications"
+	"cashcontrol/internal/repository"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// Services сервисы приложения, собранные над одним подключением к БД.
+// Один и тот же набор используют HTTP маршруты, фоновые задачи и команда seed.
+type Services struct {
+	Auth             AuthService
+	User             UserService
+	Category         CategoryService
+	Currency         CurrencyService
+	Budget           BudgetService
+	BudgetAlert      BudgetAlertService
+	Categorization   CategorizationService
+	Expense          ExpenseService
+	RecurringExpense RecurringExpenseService
+	ExpenseImport    ExpenseImportService
+	Account          AccountService
+	Income           IncomeService
+	Tag              TagService
+	Trash            TrashService
+	Statistics       StatisticsService
+	CashFlow         CashFlowService
+	ActivityLog      ActivityLogService
+}
+
+// New создает репозитории и связывает с ними все сервисы
+func New(cfg *config.Config, db *gorm.DB, logger *slog.Logger, defaultCategories *DefaultCategories) *Services {
+	userRepo := repository.NewUserRepository(db, logger)
+	categoryRepo := repository.NewCategoryRepository(db, logger)
+	expenseRepo := repository.NewExpenseRepository(db, logger)
+	incomeRepo := repository.NewIncomeRepository(db, logger)
+	accountRepo := repository.NewAccountRepository(db, logger)
+	budgetRepo := repository.NewBudgetRepository(db, logger)
+	recurringExpenseRepo := repository.NewRecurringExpenseRepository(db, logger)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db, logger)
+	activityLogRepo := repository.NewActivityLogRepository(db, logger)
+	budgetAlertRepo := repository.NewBudgetAlertRepository(db, logger)
+	exchangeRateRepo := repository.NewExchangeRateRepository(db, logger)
+	categorizationRuleRepo := repository.NewCategorizationRuleRepository(db, logger)
+	trashRepo := repository.NewTrashRepository(db, logger)
+	tagRepo := repository.NewTagRepository(db, logger)
+
+	activityLogService := NewActivityLogService(activityLogRepo, logger)
+	currencyService := NewCurrencyService(exchangeRateRepo, userRepo, logger)
+	budgetService := NewBudgetService(budgetRepo, expenseRepo, incomeRepo, categoryRepo, currencyService, activityLogService, logger)
+	budgetAlertService := NewBudgetAlertService(budgetAlertRepo, categoryRepo, budgetService, notifications.FromConfig(cfg, logger), logger)
+	categorizationService := NewCategorizationService(categorizationRuleRepo, expenseRepo, categoryRepo, accountRepo, activityLogService, logger)
+
+	return &Services{
+		Auth:             NewAuthService(userRepo, refreshTokenRepo, logger, cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL, defaultCategories),
+		User:             NewUserService(userRepo, logger),
+		Category:         NewCategoryService(categoryRepo, activityLogService, logger),
+		Currency:         currencyService,
+		Budget:           budgetService,
+		BudgetAlert:      budgetAlertService,
+		Categorization:   categorizationService,
+		Expense:          NewExpenseService(expenseRepo, categoryRepo, accountRepo, tagRepo, currencyService, categorizationService, activityLogService, budgetAlertService, logger),
+		RecurringExpense: NewRecurringExpenseService(recurringExpenseRepo, categoryRepo, accountRepo, currencyService, activityLogService, budgetAlertService, logger),
+		ExpenseImport:    NewExpenseImportService(expenseRepo, categoryRepo, accountRepo, currencyService, categorizationService, activityLogService, budgetAlertService, logger),
+		Account:          NewAccountService(accountRepo, currencyService, activityLogService, logger),
+		Income:           NewIncomeService(incomeRepo, categoryRepo, accountRepo, currencyService, activityLogService, logger),
+		Tag:              NewTagService(tagRepo, activityLogService, logger),
+		Trash:            NewTrashService(trashRepo, recurringExpenseRepo, activityLogService, cfg.TrashRetention, logger),
+		Statistics:       NewStatisticsService(expenseRepo, categoryRepo, currencyService, logger),
+		CashFlow:         NewCashFlowService(expenseRepo, incomeRepo, currencyService, logger),
+		ActivityLog:      activityLogService,
+	}
+}
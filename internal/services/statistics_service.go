@@ -0,0 +1,570 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/money"
+	"cashcontrol/internal/repository"
+	"errors"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+var (
+	ErrInvalidStatisticsPeriod = errors.New("период должен быть одним из: day, week, month, year")
+	ErrInvalidDateRange        = errors.New("начальная дата не может быть позже конечной")
+)
+
+type StatisticsService interface {
+	GetPeriodStatistics(userID uint, period models.StatisticsPeriod, date time.Time) (*models.PeriodStatistics, error)
+	GetStatisticsByPeriods(userID uint, period models.StatisticsPeriod, startDate, endDate *time.Time) ([]models.PeriodStatistics, error)
+	GetDistribution(userID uint, startDate, endDate *time.Time) ([]models.ExpenseDistribution, error)
+}
+
+type statisticsService struct {
+	expenses   repository.ExpenseRepository
+	categories repository.CategoryRepository
+	currencies CurrencyService
+	logger     *slog.Logger
+}
+
+func NewStatisticsService(
+	expenses repository.ExpenseRepository,
+	categories repository.CategoryRepository,
+	currencies CurrencyService,
+	logger *slog.Logger,
+) StatisticsService {
+	return &statisticsService{expenses: expenses, categories: categories, currencies: currencies, logger: logger}
+}
+
+// GetPeriodStatistics возвращает статистику за период, в который попадает date
+func (s *statisticsService) GetPeriodStatistics(userID uint, period models.StatisticsPeriod, date time.Time) (*models.PeriodStatistics, error) {
+	if !isValidStatisticsPeriod(period) {
+		return nil, ErrInvalidStatisticsPeriod
+	}
+
+	start := periodStart(period, date)
+	end := nextPeriodStart(period, start)
+	filter := models.ExpenseFilter{
+		UserID:    userID,
+		StartDate: &start,
+		EndDate:   ptrTime(end.Add(-time.Nanosecond)),
+	}
+
+	currency, totals, err := s.baseCurrencyTotals(filter, "get_period_statistics")
+	if err != nil {
+		return nil, err
+	}
+	tagTotals, err := s.tagTotals(filter, currency, "get_period_statistics")
+	if err != nil {
+		return nil, err
+	}
+	index, err := s.categoryIndex(userID, "get_period_statistics")
+	if err != nil {
+		return nil, err
+	}
+
+	stats := buildPeriodStatistics(period, start, currency, sumByCategory(totals), sumByPeriodAndTag(tagTotals, ""), index)
+
+	s.logger.Info("period statistics calculated",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("period", string(period)),
+		slog.Time("start_date", start),
+		slog.Int("count", stats.Count),
+	)
+
+	return &stats, nil
+}
+
+// GetStatisticsByPeriods возвращает статистику по каждому периоду диапазона, в котором были расходы
+func (s *statisticsService) GetStatisticsByPeriods(userID uint, period models.StatisticsPeriod, startDate, endDate *time.Time) ([]models.PeriodStatistics, error) {
+	if !isValidStatisticsPeriod(period) {
+		return nil, ErrInvalidStatisticsPeriod
+	}
+	filter, err := statisticsFilter(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, daily, err := s.baseCurrencyTotals(filter, "get_statistics_by_periods")
+	if err != nil {
+		return nil, err
+	}
+	tagDaily, err := s.tagTotals(filter, currency, "get_statistics_by_periods")
+	if err != nil {
+		return nil, err
+	}
+	index, err := s.categoryIndex(userID, "get_statistics_by_periods")
+	if err != nil {
+		return nil, err
+	}
+	totals := sumByPeriodAndCategory(daily, period)
+	tagTotals := sumByPeriodAndTag(tagDaily, period)
+
+	// Строки отсортированы по началу периода, поэтому группируем подряд идущие.
+	// Расходы с метками входят и в суммы по категориям, так что периоды меток есть среди периодов категорий.
+	result := make([]models.PeriodStatistics, 0)
+	for i, k := 0, 0; i < len(totals); {
+		start := totals[i].PeriodStart
+		j := i
+		for j < len(totals) && totals[j].PeriodStart.Equal(start) {
+			j++
+		}
+		l := k
+		for l < len(tagTotals) && tagTotals[l].PeriodStart.Equal(start) {
+			l++
+		}
+		result = append(result, buildPeriodStatistics(period, start, currency, totals[i:j], tagTotals[k:l], index))
+		i, k = j, l
+	}
+
+	s.logger.Info("statistics by periods calculated",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("period", string(period)),
+		slog.Int("periods", len(result)),
+	)
+
+	return result, nil
+}
+
+// GetDistribution возвращает распределение расходов по корневым категориям в процентах
+// с вложенным распределением по подкатегориям
+func (s *statisticsService) GetDistribution(userID uint, startDate, endDate *time.Time) ([]models.ExpenseDistribution, error) {
+	filter, err := statisticsFilter(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, daily, err := s.baseCurrencyTotals(filter, "get_distribution")
+	if err != nil {
+		return nil, err
+	}
+	index, err := s.categoryIndex(userID, "get_distribution")
+	if err != nil {
+		return nil, err
+	}
+	totals := sumByCategory(daily)
+
+	var total money.Amount
+	for _, t := range totals {
+		total += t.TotalAmount
+	}
+
+	distribution := expenseDistribution(rollUpCategories(totals, index), currency, total)
+
+	s.logger.Info("expense distribution calculated",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("categories", len(distribution)),
+	)
+
+	return distribution, nil
+}
+
+// baseCurrencyTotals возвращает базовую валюту пользователя и дневные суммы расходов, пересчитанные в нее
+func (s *statisticsService) baseCurrencyTotals(filter models.ExpenseFilter, op string) (string, []models.DailyTotal, error) {
+	currency, err := s.currencies.BaseCurrency(filter.UserID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	totals, err := convertedDailyTotals(s.expenses, s.currencies, filter, currency)
+	if err != nil {
+		if !errors.Is(err, ErrMissingExchangeRate) {
+			s.logger.Error("failed to aggregate expenses",
+				slog.String("op", op),
+				slog.Uint64("user_id", uint64(filter.UserID)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return "", nil, err
+	}
+	return currency, totals, nil
+}
+
+// tagTotals возвращает дневные суммы расходов по меткам, пересчитанные в базовую валюту currency
+func (s *statisticsService) tagTotals(filter models.ExpenseFilter, currency, op string) ([]models.TagDailyTotal, error) {
+	totals, err := convertedTagTotals(s.expenses, s.currencies, filter, currency)
+	if err != nil {
+		if !errors.Is(err, ErrMissingExchangeRate) {
+			s.logger.Error("failed to aggregate expenses by tag",
+				slog.String("op", op),
+				slog.Uint64("user_id", uint64(filter.UserID)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil, err
+	}
+	return totals, nil
+}
+
+// categoryIndex загружает категории пользователя для свертки сумм подкатегорий в родительские
+func (s *statisticsService) categoryIndex(userID uint, op string) (categoryIndex, error) {
+	categories, err := s.categories.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to load categories",
+			slog.String("op", op),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	return newCategoryIndex(categories), nil
+}
+
+// buildPeriodStatistics собирает статистику периода из агрегатов по категориям и меткам;
+// разбивка идет по корневым категориям, суммы подкатегорий входят в родительские
+func buildPeriodStatistics(
+	period models.StatisticsPeriod,
+	start time.Time,
+	currency string,
+	totals []models.CategoryPeriodTotal,
+	tags []models.TagPeriodTotal,
+	index categoryIndex,
+) models.PeriodStatistics {
+	stats := models.PeriodStatistics{
+		Period:    period,
+		StartDate: start,
+		EndDate:   nextPeriodStart(period, start).AddDate(0, 0, -1),
+		Currency:  currency,
+	}
+
+	for _, t := range totals {
+		stats.TotalAmount += t.TotalAmount
+		stats.Count += t.Count
+	}
+	if stats.Count > 0 {
+		stats.AverageAmount = stats.TotalAmount.Div(stats.Count, currency)
+	}
+
+	stats.ByCategory = categoryStatistics(rollUpCategories(totals, index), stats.TotalAmount)
+	stats.ByTag = tagStatistics(tags, stats.TotalAmount)
+
+	return stats
+}
+
+// categoryTotalNode сумма категории вместе со всеми ее подкатегориями
+type categoryTotalNode struct {
+	total    models.CategoryPeriodTotal
+	children []*categoryTotalNode
+}
+
+// rollUpCategories сворачивает суммы по категориям в дерево: сумма категории включает суммы всех
+// ее подкатегорий, верхний уровень составляют корневые категории. Уровни отсортированы по убыванию суммы.
+func rollUpCategories(totals []models.CategoryPeriodTotal, index categoryIndex) []*categoryTotalNode {
+	nodes := make(map[uint]*categoryTotalNode)
+	linked := make(map[uint]bool)
+	node := func(id uint, name, color string) *categoryTotalNode {
+		n, ok := nodes[id]
+		if !ok {
+			n = &categoryTotalNode{total: models.CategoryPeriodTotal{CategoryID: id, CategoryName: name, CategoryColor: color}}
+			nodes[id] = n
+		}
+		return n
+	}
+
+	for _, t := range totals {
+		n := node(t.CategoryID, t.CategoryName, t.CategoryColor)
+		n.total.TotalAmount += t.TotalAmount
+		n.total.Count += t.Count
+
+		for _, parentID := range index.ancestors(t.CategoryID) {
+			parent := node(parentID, index[parentID].Name, index[parentID].Color)
+			parent.total.TotalAmount += t.TotalAmount
+			parent.total.Count += t.Count
+			if !linked[n.total.CategoryID] {
+				linked[n.total.CategoryID] = true
+				parent.children = append(parent.children, n)
+			}
+			n = parent
+		}
+	}
+
+	roots := make([]*categoryTotalNode, 0, len(nodes))
+	for id, n := range nodes {
+		if !linked[id] {
+			roots = append(roots, n)
+		}
+	}
+	sortCategoryNodes(roots)
+	return roots
+}
+
+// sortCategoryNodes сортирует уровень дерева и все вложенные уровни: крупные категории первыми
+func sortCategoryNodes(nodes []*categoryTotalNode) {
+	sort.Slice(nodes, func(a, b int) bool {
+		if nodes[a].total.TotalAmount != nodes[b].total.TotalAmount {
+			return nodes[a].total.TotalAmount > nodes[b].total.TotalAmount
+		}
+		return nodes[a].total.CategoryID < nodes[b].total.CategoryID
+	})
+	for _, n := range nodes {
+		sortCategoryNodes(n.children)
+	}
+}
+
+// categoryStatistics переводит дерево сумм в статистику по категориям; проценты считаются от total
+func categoryStatistics(nodes []*categoryTotalNode, total money.Amount) []models.CategoryStatistics {
+	result := make([]models.CategoryStatistics, 0, len(nodes))
+	for _, n := range nodes {
+		stats := models.CategoryStatistics{
+			CategoryID:    int(n.total.CategoryID),
+			CategoryName:  n.total.CategoryName,
+			CategoryColor: n.total.CategoryColor,
+			TotalAmount:   n.total.TotalAmount,
+			Count:         n.total.Count,
+			Percentage:    percentage(n.total.TotalAmount, total),
+		}
+		if len(n.children) > 0 {
+			stats.Subcategories = categoryStatistics(n.children, total)
+		}
+		result = append(result, stats)
+	}
+	return result
+}
+
+// tagStatistics переводит суммы по меткам в статистику; проценты считаются от total,
+// поэтому у расходов с несколькими метками их сумма может превышать 100
+func tagStatistics(totals []models.TagPeriodTotal, total money.Amount) []models.TagStatistics {
+	result := make([]models.TagStatistics, 0, len(totals))
+	for _, t := range totals {
+		result = append(result, models.TagStatistics{
+			TagID:       t.TagID,
+			TagName:     t.TagName,
+			TagColor:    t.TagColor,
+			TotalAmount: t.TotalAmount,
+			Count:       t.Count,
+			Percentage:  percentage(t.TotalAmount, total),
+		})
+	}
+	return result
+}
+
+// expenseDistribution переводит дерево сумм в распределение расходов; проценты считаются от total
+func expenseDistribution(nodes []*categoryTotalNode, currency string, total money.Amount) []models.ExpenseDistribution {
+	result := make([]models.ExpenseDistribution, 0, len(nodes))
+	for _, n := range nodes {
+		distribution := models.ExpenseDistribution{
+			CategoryID:    int(n.total.CategoryID),
+			CategoryName:  n.total.CategoryName,
+			CategoryColor: n.total.CategoryColor,
+			Amount:        n.total.TotalAmount,
+			Currency:      currency,
+			Percentage:    percentage(n.total.TotalAmount, total),
+		}
+		if len(n.children) > 0 {
+			distribution.Subcategories = expenseDistribution(n.children, currency, total)
+		}
+		result = append(result, distribution)
+	}
+	return result
+}
+
+// convertedDailyTotals суммирует расходы фильтра по дням и пересчитывает суммы в валюту target
+func convertedDailyTotals(expenses repository.ExpenseRepository, currencies CurrencyService, filter models.ExpenseFilter, target string) ([]models.DailyTotal, error) {
+	totals, err := expenses.SumByDay(filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := currencies.ConvertDailyTotals(filter.UserID, target, totals); err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+// convertedTagTotals суммирует расходы фильтра по дням и меткам и пересчитывает суммы в валюту target
+// по тем же курсам на день расхода, что и суммы по категориям
+func convertedTagTotals(expenses repository.ExpenseRepository, currencies CurrencyService, filter models.ExpenseFilter, target string) ([]models.TagDailyTotal, error) {
+	totals, err := expenses.SumByDayAndTag(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := make([]models.DailyTotal, len(totals))
+	for i, t := range totals {
+		amounts[i] = models.DailyTotal{Day: t.Day, Currency: t.Currency, TotalAmount: t.TotalAmount}
+	}
+	if err := currencies.ConvertDailyTotals(filter.UserID, target, amounts); err != nil {
+		return nil, err
+	}
+	for i := range totals {
+		totals[i].Currency = amounts[i].Currency
+		totals[i].TotalAmount = amounts[i].TotalAmount
+	}
+	return totals, nil
+}
+
+// sumByCategory складывает дневные суммы по категориям, крупные категории первыми
+func sumByCategory(daily []models.DailyTotal) []models.CategoryPeriodTotal {
+	return sumByPeriodAndCategory(daily, "")
+}
+
+// sumByPeriodAndCategory складывает дневные суммы по периодам и категориям.
+// Пустой period означает агрегацию без периода; результат отсортирован по периоду и убыванию суммы.
+func sumByPeriodAndCategory(daily []models.DailyTotal, period models.StatisticsPeriod) []models.CategoryPeriodTotal {
+	type key struct {
+		start      time.Time
+		categoryID uint
+	}
+	index := make(map[key]int)
+	totals := make([]models.CategoryPeriodTotal, 0)
+
+	for _, d := range daily {
+		var start time.Time
+		if period != "" {
+			start = periodStart(period, d.Day)
+		}
+		k := key{start: start, categoryID: d.CategoryID}
+		i, ok := index[k]
+		if !ok {
+			i = len(totals)
+			index[k] = i
+			totals = append(totals, models.CategoryPeriodTotal{
+				PeriodStart:   start,
+				CategoryID:    d.CategoryID,
+				CategoryName:  d.CategoryName,
+				CategoryColor: d.CategoryColor,
+			})
+		}
+		totals[i].TotalAmount += d.TotalAmount
+		totals[i].Count += d.Count
+	}
+
+	sort.Slice(totals, func(a, b int) bool {
+		if !totals[a].PeriodStart.Equal(totals[b].PeriodStart) {
+			return totals[a].PeriodStart.Before(totals[b].PeriodStart)
+		}
+		if totals[a].TotalAmount != totals[b].TotalAmount {
+			return totals[a].TotalAmount > totals[b].TotalAmount
+		}
+		return totals[a].CategoryID < totals[b].CategoryID
+	})
+	return totals
+}
+
+// sumByPeriodAndTag складывает дневные суммы по периодам и меткам.
+// Пустой period означает агрегацию без периода; результат отсортирован по периоду и убыванию суммы.
+func sumByPeriodAndTag(daily []models.TagDailyTotal, period models.StatisticsPeriod) []models.TagPeriodTotal {
+	type key struct {
+		start time.Time
+		tagID uint
+	}
+	index := make(map[key]int)
+	totals := make([]models.TagPeriodTotal, 0)
+
+	for _, d := range daily {
+		var start time.Time
+		if period != "" {
+			start = periodStart(period, d.Day)
+		}
+		k := key{start: start, tagID: d.TagID}
+		i, ok := index[k]
+		if !ok {
+			i = len(totals)
+			index[k] = i
+			totals = append(totals, models.TagPeriodTotal{
+				PeriodStart: start,
+				TagID:       d.TagID,
+				TagName:     d.TagName,
+				TagColor:    d.TagColor,
+			})
+		}
+		totals[i].TotalAmount += d.TotalAmount
+		totals[i].Count += d.Count
+	}
+
+	sort.Slice(totals, func(a, b int) bool {
+		if !totals[a].PeriodStart.Equal(totals[b].PeriodStart) {
+			return totals[a].PeriodStart.Before(totals[b].PeriodStart)
+		}
+		if totals[a].TotalAmount != totals[b].TotalAmount {
+			return totals[a].TotalAmount > totals[b].TotalAmount
+		}
+		return totals[a].TagID < totals[b].TagID
+	})
+	return totals
+}
+
+// sumByPeriod складывает дневные суммы по периодам в порядке возрастания
+func sumByPeriod(daily []models.DailyTotal, period models.StatisticsPeriod) []models.PeriodTotal {
+	index := make(map[time.Time]int)
+	totals := make([]models.PeriodTotal, 0)
+
+	for _, d := range daily {
+		start := periodStart(period, d.Day)
+		i, ok := index[start]
+		if !ok {
+			i = len(totals)
+			index[start] = i
+			totals = append(totals, models.PeriodTotal{PeriodStart: start})
+		}
+		totals[i].TotalAmount += d.TotalAmount
+		totals[i].Count += d.Count
+	}
+
+	sort.Slice(totals, func(a, b int) bool {
+		return totals[a].PeriodStart.Before(totals[b].PeriodStart)
+	})
+	return totals
+}
+
+// statisticsFilter строит фильтр по диапазону дат; конечная дата включается целиком
+func statisticsFilter(userID uint, startDate, endDate *time.Time) (models.ExpenseFilter, error) {
+	if startDate != nil && endDate != nil && startDate.After(*endDate) {
+		return models.ExpenseFilter{}, ErrInvalidDateRange
+	}
+
+	filter := models.ExpenseFilter{UserID: userID, StartDate: startDate}
+	if endDate != nil {
+		filter.EndDate = ptrTime(periodStart(models.PeriodDay, *endDate).AddDate(0, 0, 1).Add(-time.Nanosecond))
+	}
+	return filter, nil
+}
+
+func isValidStatisticsPeriod(period models.StatisticsPeriod) bool {
+	switch period {
+	case models.PeriodDay, models.PeriodWeek, models.PeriodMonth, models.PeriodYear:
+		return true
+	}
+	return false
+}
+
+// periodStart возвращает начало периода в UTC, неделя начинается с понедельника как в date_trunc
+func periodStart(period models.StatisticsPeriod, t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case models.PeriodWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case models.PeriodMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case models.PeriodYear:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// nextPeriodStart возвращает начало периода, следующего за периодом с началом start
+func nextPeriodStart(period models.StatisticsPeriod, start time.Time) time.Time {
+	switch period {
+	case models.PeriodWeek:
+		return start.AddDate(0, 0, 7)
+	case models.PeriodMonth:
+		return start.AddDate(0, 1, 0)
+	case models.PeriodYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// percentage возвращает долю part от total в процентах с точностью до сотых
+func percentage(part, total money.Amount) float64 {
+	return money.Percent(part, total)
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTagNotFound  = errors.New("метка не найдена")
+	ErrTagNameTaken = errors.New("метка с таким названием уже есть")
+	ErrEmptyTagName = errors.New("название метки не может быть пустым")
+)
+
+type TagService interface {
+	CreateTag(userID uint, req models.CreateTagRequest) (*models.Tag, error)
+	GetTagList(userID uint) ([]models.Tag, error)
+	GetTagByID(userID, id uint) (*models.Tag, error)
+	UpdateTag(userID, id uint, req models.UpdateTagRequest) (*models.Tag, error)
+	DeleteTag(userID, id uint) error
+}
+
+type tagService struct {
+	tags         repository.TagRepository
+	activityLogs ActivityLogService
+	logger       *slog.Logger
+}
+
+func NewTagService(tags repository.TagRepository, activityLogs ActivityLogService, logger *slog.Logger) TagService {
+	return &tagService{tags: tags, activityLogs: activityLogs, logger: logger}
+}
+
+func (s *tagService) CreateTag(userID uint, req models.CreateTagRequest) (*models.Tag, error) {
+	tag := &models.Tag{
+		UserID: userID,
+		Name:   strings.TrimSpace(req.Name),
+		Color:  req.Color,
+	}
+
+	if err := s.validateName(tag); err != nil {
+		s.logger.Warn("tag create validation failed",
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("reason", err.Error()),
+		)
+		return nil, err
+	}
+
+	if err := s.tags.Create(tag); err != nil {
+		s.logger.Error("tag create failed",
+			slog.String("op", "create_tag"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	s.logger.Info("tag created",
+		slog.Uint64("tag_id", uint64(tag.ID)),
+		slog.Uint64("user_id", uint64(userID)),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeTagCreated,
+		EntityType:   models.EntityTypeTag,
+		EntityID:     tag.ID,
+		Description:  "создана метка",
+		Metadata:     activityMetadata(nil, tag),
+	})
+
+	return tag, nil
+}
+
+func (s *tagService) GetTagList(userID uint) ([]models.Tag, error) {
+	tags, err := s.tags.GetByUserID(userID)
+	if err != nil {
+		s.logger.Error("failed to list tags",
+			slog.String("op", "list_tags"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("tags listed",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.Int("count", len(tags)),
+	)
+
+	return tags, nil
+}
+
+func (s *tagService) GetTagByID(userID, id uint) (*models.Tag, error) {
+	tag, err := s.getOwnedTag(userID, id, "get_tag_by_id")
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("tag retrieved",
+		slog.Uint64("tag_id", uint64(tag.ID)),
+	)
+
+	return tag, nil
+}
+
+func (s *tagService) UpdateTag(userID, id uint, req models.UpdateTagRequest) (*models.Tag, error) {
+	tag, err := s.getOwnedTag(userID, id, "update_tag")
+	if err != nil {
+		return nil, err
+	}
+
+	before := *tag
+
+	if req.Name != nil {
+		tag.Name = strings.TrimSpace(*req.Name)
+		if err := s.validateName(tag); err != nil {
+			s.logger.Warn("tag update validation failed",
+				slog.Uint64("tag_id", uint64(id)),
+				slog.String("reason", err.Error()),
+			)
+			return nil, err
+		}
+	}
+	if req.Color != nil {
+		tag.Color = *req.Color
+	}
+
+	if err := s.tags.Update(tag); err != nil {
+		s.logger.Error("tag update failed",
+			slog.String("op", "update_tag"),
+			slog.Uint64("tag_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	s.logger.Info("tag updated",
+		slog.Uint64("tag_id", uint64(tag.ID)),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeTagUpdated,
+		EntityType:   models.EntityTypeTag,
+		EntityID:     tag.ID,
+		Description:  "изменена метка",
+		Metadata:     activityMetadata(before, tag),
+	})
+
+	return tag, nil
+}
+
+// DeleteTag переносит метку в корзину; пока метка там, она не показывается у расходов и не учитывается в фильтрах
+func (s *tagService) DeleteTag(userID, id uint) error {
+	tag, err := s.getOwnedTag(userID, id, "delete_tag")
+	if err != nil {
+		return err
+	}
+
+	if err := s.tags.Delete(id); err != nil {
+		s.logger.Error("tag delete failed",
+			slog.String("op", "delete_tag"),
+			slog.Uint64("tag_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	s.logger.Info("tag deleted",
+		slog.Uint64("tag_id", uint64(id)),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeTagDeleted,
+		EntityType:   models.EntityTypeTag,
+		EntityID:     id,
+		Description:  "удалена метка",
+		Metadata:     activityMetadata(tag, nil),
+	})
+
+	return nil
+}
+
+// getOwnedTag загружает метку и проверяет, что она принадлежит пользователю
+func (s *tagService) getOwnedTag(userID, id uint, op string) (*models.Tag, error) {
+	tag, err := s.tags.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("tag not found",
+				slog.String("op", op),
+				slog.Uint64("tag_id", uint64(id)),
+			)
+			return nil, ErrTagNotFound
+		}
+		s.logger.Error("failed to fetch tag",
+			slog.String("op", op),
+			slog.Uint64("tag_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if tag.UserID != userID {
+		s.logger.Warn("tag belongs to another user",
+			slog.String("op", op),
+			slog.Uint64("tag_id", uint64(id)),
+			slog.Uint64("user_id", uint64(userID)),
+		)
+		return nil, ErrForbidden
+	}
+
+	return tag, nil
+}
+
+// validateName проверяет, что название метки не пустое и не занято другой меткой пользователя
+func (s *tagService) validateName(tag *models.Tag) error {
+	if tag.Name == "" {
+		return ErrEmptyTagName
+	}
+
+	existing, err := s.tags.GetByName(tag.UserID, tag.Name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != tag.ID {
+		return ErrTagNameTaken
+	}
+	return nil
+}
+
+// resolveTags загружает метки пользователя для назначения расходу; повторы в ids не учитываются.
+// Если хотя бы одна метка не найдена или принадлежит другому пользователю, возвращается ErrTagNotFound.
+func resolveTags(tags repository.TagRepository, userID uint, ids []uint) ([]models.Tag, error) {
+	if len(ids) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	unique := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+
+	found, err := tags.GetByIDs(userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(found) != len(unique) {
+		return nil, ErrTagNotFound
+	}
+	return found, nil
+}
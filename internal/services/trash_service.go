@@ -0,0 +1,179 @@
+package services
+
+import (
+	"cashcontrol/internal/models"
+	"cashcontrol/internal/repository"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTrashItemNotFound      = errors.New("запись не найдена в корзине")
+	ErrInvalidTrashEntityType = errors.New("неизвестный тип записи корзины")
+	ErrTrashRestoreConflict   = errors.New("запись нельзя восстановить: вместо нее уже создана новая, например бюджет на тот же месяц или метка с тем же названием")
+)
+
+type TrashService interface {
+	GetTrash(filter models.TrashFilter) ([]models.TrashItem, error)
+	Restore(userID uint, entityType string, id uint) (*models.TrashRestoreResult, error)
+	PurgeExpired(ctx context.Context) error
+}
+
+type trashService struct {
+	trash             repository.TrashRepository
+	recurringExpenses repository.RecurringExpenseRepository
+	activityLogs      ActivityLogService
+	retention         time.Duration
+	logger            *slog.Logger
+}
+
+// NewTrashService создает сервис корзины; записи хранятся в корзине retention, нулевое значение отключает очистку
+func NewTrashService(
+	trash repository.TrashRepository,
+	recurringExpenses repository.RecurringExpenseRepository,
+	activityLogs ActivityLogService,
+	retention time.Duration,
+	logger *slog.Logger,
+) TrashService {
+	return &trashService{
+		trash:             trash,
+		recurringExpenses: recurringExpenses,
+		activityLogs:      activityLogs,
+		retention:         retention,
+		logger:            logger,
+	}
+}
+
+func (s *trashService) GetTrash(filter models.TrashFilter) ([]models.TrashItem, error) {
+	if filter.EntityType != nil && !slices.Contains(models.TrashEntityTypes, *filter.EntityType) {
+		return nil, ErrInvalidTrashEntityType
+	}
+
+	items, err := s.trash.List(filter)
+	if err != nil {
+		s.logger.Error("failed to get trash",
+			slog.String("op", "get_trash"),
+			slog.Uint64("user_id", uint64(filter.UserID)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if s.retention > 0 {
+		for i := range items {
+			purgeAt := items[i].DeletedAt.Add(s.retention)
+			items[i].PurgeAt = &purgeAt
+		}
+	}
+
+	return items, nil
+}
+
+// Restore возвращает запись из корзины вместе с удаленными категориями и счетами, на которые она ссылается.
+// Регулярный расход продолжает работу с ближайшей будущей даты: пропущенные за время в корзине повторения не создаются.
+func (s *trashService) Restore(userID uint, entityType string, id uint) (*models.TrashRestoreResult, error) {
+	if !slices.Contains(models.TrashEntityTypes, entityType) {
+		return nil, ErrInvalidTrashEntityType
+	}
+
+	result, err := s.trash.Restore(userID, entityType, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTrashItemNotFound
+		}
+		if errors.Is(err, repository.ErrRestoreConflict) {
+			return nil, ErrTrashRestoreConflict
+		}
+		s.logger.Error("failed to restore from trash",
+			slog.String("op", "restore_from_trash"),
+			slog.Uint64("user_id", uint64(userID)),
+			slog.String("entity_type", entityType),
+			slog.Uint64("entity_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return nil, err
+	}
+
+	if entityType == models.EntityTypeRecurringExpense {
+		s.skipMissedOccurrences(id)
+	}
+
+	s.logger.Info("restored from trash",
+		slog.Uint64("user_id", uint64(userID)),
+		slog.String("entity_type", entityType),
+		slog.Uint64("entity_id", uint64(id)),
+		slog.Int("dependencies", len(result.Dependencies)),
+	)
+
+	recordActivity(s.activityLogs, s.logger, models.CreateActivityLogRequest{
+		UserID:       userID,
+		ActivityType: models.ActivityTypeEntityRestored,
+		EntityType:   entityType,
+		EntityID:     id,
+		Description:  "запись восстановлена из корзины",
+		Metadata: map[string]interface{}{
+			"dependencies": result.Dependencies,
+		},
+	})
+
+	return result, nil
+}
+
+// skipMissedOccurrences переносит NextDate восстановленного регулярного расхода на ближайшее будущее повторение
+func (s *trashService) skipMissedOccurrences(id uint) {
+	recurringExpense, err := s.recurringExpenses.GetByID(id)
+	if err != nil {
+		s.logger.Warn("failed to get restored recurring expense",
+			slog.Uint64("recurring_expense_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	now := time.Now()
+	if recurringExpense.NextDate.After(now) {
+		return
+	}
+
+	recurringExpense.NextDate = nextOccurrence(recurringExpense, now)
+	if err := s.recurringExpenses.Update(recurringExpense); err != nil {
+		s.logger.Warn("failed to move next date of restored recurring expense",
+			slog.Uint64("recurring_expense_id", uint64(id)),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// PurgeExpired окончательно удаляет записи, которые пролежали в корзине дольше срока хранения
+func (s *trashService) PurgeExpired(ctx context.Context) error {
+	if s.retention <= 0 {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	purged, err := s.trash.Purge(time.Now().Add(-s.retention))
+	if err != nil {
+		s.logger.Error("failed to purge trash",
+			slog.String("op", "purge_trash"),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+
+	var total int64
+	attrs := make([]any, 0, len(purged)+1)
+	for _, entityType := range models.TrashEntityTypes {
+		total += purged[entityType]
+		attrs = append(attrs, slog.Int64(entityType, purged[entityType]))
+	}
+	attrs = append(attrs, slog.Int64("total", total))
+	s.logger.Info("trash purged", attrs...)
+
+	return nil
+}